@@ -0,0 +1,19 @@
+package webhook
+
+import "github.com/lddsb/dingtalk-webhook/adapters"
+
+// BurnRateWindow `one long/short window pair of a multi-window burn rate alert, see package adapters`
+type BurnRateWindow = adapters.BurnRateWindow
+
+// SLOBurn `state of an SLO error budget`
+type SLOBurn = adapters.SLOBurn
+
+// HTMLToMarkdown `convert a safe subset of html, e.g. alert mail bodies, into DingTalk markdown`
+func HTMLToMarkdown(src string) string {
+	return adapters.HTMLToMarkdown(src)
+}
+
+// FormatSLOBurn `render the standard multi-window burn rate alert as markdown`
+func FormatSLOBurn(burn SLOBurn) *MarkdownMessage {
+	return adapters.FormatSLOBurn(burn)
+}
@@ -0,0 +1,298 @@
+package adapters
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+var (
+	htmlToken      = regexp.MustCompile(`(?s)<!--.*?-->|<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*?)(/?)>`)
+	htmlAttr       = regexp.MustCompile(`([a-zA-Z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlSpaces     = regexp.MustCompile(`[ \t\r\n]+`)
+	htmlBlankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+)
+
+// html elements whose content is never shown
+var htmlSkipped = map[string]bool{"script": true, "style": true, "head": true, "title": true}
+
+// html elements which never have content
+var htmlVoid = map[string]bool{"br": true, "img": true, "hr": true}
+
+// html elements rendered as their own markdown block
+var htmlBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "hr": true,
+}
+
+// htmlList one open ul or ol
+type htmlList struct {
+	ordered bool
+	n       int
+}
+
+// htmlCapture content collected for an element rendered once it is closed
+type htmlCapture struct {
+	name string
+	href string
+	buf  strings.Builder
+	rows [][]string
+	row  []string
+	//  the first row is made of th cells
+	headed bool
+}
+
+// htmlConverter state of one HTMLToMarkdown run
+type htmlConverter struct {
+	out      strings.Builder
+	captures []*htmlCapture
+	lists    []htmlList
+	skip     int
+	pre      int
+}
+
+// HTMLToMarkdown `convert a safe subset of html, e.g. alert mail bodies, into DingTalk markdown`
+//
+// Headings, paragraphs, emphasis, links, images, lists, quotes, pre blocks, tables and
+// <font color> are converted, tables and pre blocks the way message.Table and message.CodeBlock render them.
+// Scripts and styles are dropped, any other tag is stripped keeping its text.
+func HTMLToMarkdown(src string) string {
+	c := &htmlConverter{}
+	last := 0
+	for _, m := range htmlToken.FindAllStringSubmatchIndex(src, -1) {
+		c.text(src[last:m[0]])
+		last = m[1]
+		if strings.HasPrefix(src[m[0]:m[1]], "<!--") {
+			continue
+		}
+
+		name := strings.ToLower(src[m[4]:m[5]])
+		if m[3] > m[2] {
+			c.end(name)
+			continue
+		}
+		c.start(name, parseHTMLAttrs(src[m[6]:m[7]]))
+		//  <a/> and friends close themselves, void elements like <br> have nothing to close
+		if m[9] > m[8] && !htmlVoid[name] {
+			c.end(name)
+		}
+	}
+	c.text(src[last:])
+
+	return strings.TrimSpace(htmlBlankLines.ReplaceAllString(c.out.String(), "\n\n"))
+}
+
+func parseHTMLAttrs(src string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range htmlAttr.FindAllStringSubmatch(src, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+
+	return attrs
+}
+
+// buffer innermost capture, or the output
+func (c *htmlConverter) buffer() *strings.Builder {
+	if 0 != len(c.captures) {
+		return &c.captures[len(c.captures)-1].buf
+	}
+
+	return &c.out
+}
+
+func (c *htmlConverter) write(s string) {
+	c.buffer().WriteString(s)
+}
+
+func (c *htmlConverter) text(s string) {
+	if 0 != c.skip || "" == s {
+		return
+	}
+	if 0 == c.pre {
+		s = htmlSpaces.ReplaceAllString(s, " ")
+		//  indentation of the html source is not content
+		if written := c.buffer().String(); "" == written || strings.HasSuffix(written, "\n") {
+			s = strings.TrimLeft(s, " ")
+		}
+	}
+	c.write(html.UnescapeString(s))
+}
+
+func (c *htmlConverter) capture(name string) *htmlCapture {
+	capture := &htmlCapture{name: name}
+	c.captures = append(c.captures, capture)
+	return capture
+}
+
+// captured pop the innermost capture when it belongs to name
+func (c *htmlConverter) captured(name string) *htmlCapture {
+	for i := len(c.captures) - 1; i >= 0; i-- {
+		if name == c.captures[i].name {
+			capture := c.captures[i]
+			c.captures = c.captures[:i]
+			return capture
+		}
+	}
+
+	return nil
+}
+
+// table innermost open table
+func (c *htmlConverter) table() *htmlCapture {
+	for i := len(c.captures) - 1; i >= 0; i-- {
+		if "table" == c.captures[i].name {
+			return c.captures[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *htmlConverter) start(name string, attrs map[string]string) {
+	if htmlSkipped[name] {
+		c.skip++
+		return
+	}
+	if 0 != c.skip {
+		return
+	}
+	if htmlBlocks[name] && !c.nestedList(name) {
+		c.write("\n\n")
+	}
+
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.write(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case "hr":
+		c.write("---\n\n")
+	case "br":
+		c.write(message.LineBreak)
+	case "b", "strong":
+		c.write("**")
+	case "i", "em":
+		c.write("*")
+	case "font":
+		if color := attrs["color"]; "" != color {
+			c.write("<font color=" + color + ">")
+		}
+	case "img":
+		c.write("![" + attrs["alt"] + "](" + attrs["src"] + ")")
+	case "a":
+		c.capture("a").href = attrs["href"]
+	case "blockquote":
+		c.capture("blockquote")
+	case "pre":
+		c.pre++
+		c.capture("pre")
+	case "ul", "ol":
+		c.lists = append(c.lists, htmlList{ordered: "ol" == name})
+	case "li":
+		if 0 == len(c.lists) {
+			c.write("\n- ")
+			break
+		}
+		list := &c.lists[len(c.lists)-1]
+		list.n++
+		marker := "- "
+		if list.ordered {
+			marker = fmt.Sprintf("%d. ", list.n)
+		}
+		c.write("\n" + strings.Repeat("  ", len(c.lists)-1) + marker)
+	case "table":
+		c.capture("table")
+	case "tr":
+		if table := c.table(); nil != table {
+			table.row = nil
+		}
+	case "td", "th":
+		c.capture(name)
+	}
+}
+
+func (c *htmlConverter) end(name string) {
+	if htmlSkipped[name] {
+		if 0 != c.skip {
+			c.skip--
+		}
+		return
+	}
+	if 0 != c.skip {
+		return
+	}
+
+	switch name {
+	case "b", "strong":
+		c.write("**")
+	case "i", "em":
+		c.write("*")
+	case "font":
+		c.write("</font>")
+	case "a":
+		if capture := c.captured("a"); nil != capture {
+			text := strings.TrimSpace(capture.buf.String())
+			switch {
+			case "" == capture.href:
+				c.write(text)
+			case "" == text:
+				c.write(capture.href)
+			default:
+				c.write("[" + text + "](" + capture.href + ")")
+			}
+		}
+	case "blockquote":
+		if capture := c.captured("blockquote"); nil != capture {
+			var lines []string
+			for _, line := range strings.Split(strings.TrimSpace(capture.buf.String()), "\n") {
+				if line = strings.TrimSpace(line); "" != line {
+					lines = append(lines, "> "+line)
+				}
+			}
+			c.write("\n\n" + strings.Join(lines, "\n") + "\n\n")
+		}
+	case "pre":
+		if capture := c.captured("pre"); nil != capture {
+			c.pre--
+			c.write("\n\n" + message.CodeBlock("", strings.Trim(capture.buf.String(), "\n")) + "\n\n")
+		}
+	case "ul", "ol":
+		if 0 != len(c.lists) {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+	case "td", "th":
+		if capture := c.captured(name); nil != capture {
+			if table := c.table(); nil != table {
+				table.row = append(table.row, strings.TrimSpace(capture.buf.String()))
+				if "th" == name && 0 == len(table.rows) {
+					table.headed = true
+				}
+			}
+		}
+	case "tr":
+		if table := c.table(); nil != table && 0 != len(table.row) {
+			table.rows = append(table.rows, table.row)
+			table.row = nil
+		}
+	case "table":
+		if capture := c.captured("table"); nil != capture {
+			var headers []string
+			rows := capture.rows
+			if capture.headed && 0 != len(rows) {
+				headers, rows = rows[0], rows[1:]
+			}
+			c.write("\n\n" + message.Table(headers, rows) + "\n\n")
+		}
+	}
+
+	if htmlBlocks[name] && !c.nestedList(name) {
+		c.write("\n\n")
+	}
+}
+
+// nestedList lists inside list items continue the outer list instead of starting a block
+func (c *htmlConverter) nestedList(name string) bool {
+	return ("ul" == name || "ol" == name) && 0 != len(c.lists)
+}
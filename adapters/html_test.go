@@ -0,0 +1,41 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	src := `<html><head><title>alert</title><style>p { color: red }</style></head><body>
+<h2>Disk   full</h2>
+<p>Host <b>web-1</b> is at <font color="#ff0000">98%</font>,<br>see <a href="https://grafana/d/1">the dashboard</a>.</p>
+<!-- internal note -->
+<ul><li>/var &amp; /tmp</li><li>/home<ol><li>first</li><li>second</li></ol></li></ul>
+<blockquote>keep calm</blockquote>
+<pre>df -h
+  /dev/sda1 98%</pre>
+<table><tr><th>host</th><th>usage</th></tr><tr><td>web-1</td><td>98%</td></tr></table>
+<img src="https://grafana/render/1.png" alt="graph"/>
+<script>alert(1)</script>
+</body></html>`
+
+	want := "## Disk full\n\n" +
+		"Host **web-1** is at <font color=#ff0000>98%</font>,  \n" +
+		"see [the dashboard](https://grafana/d/1).\n\n" +
+		"- /var & /tmp\n- /home\n  1. first\n  2. second\n\n" +
+		"> keep calm\n\n" +
+		"> df -h  \n> \u00a0\u00a0/dev/sda1 98%\n\n" +
+		"- **web-1** · usage: 98%\n\n" +
+		"![graph](https://grafana/render/1.png)"
+	if got := HTMLToMarkdown(src); want != got {
+		t.Errorf("unexpected markdown:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestHTMLToMarkdownLintClean(t *testing.T) {
+	got := HTMLToMarkdown(`<p>a<br/>b</p><table><tr><td>x</td><td>y</td></tr></table><pre>code</pre><a href="https://x"/>`)
+	if warnings := message.Lint(got); 0 != len(warnings) {
+		t.Errorf("converted markdown should be lint clean, got %v in %q", warnings, got)
+	}
+}
@@ -0,0 +1,116 @@
+// Package adapters turns content produced by other tools into DingTalk messages.
+//
+// HTML from mail based alerting becomes markdown and SLO burn rate alerts become a report
+// formatted for mobile reading.
+package adapters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// BurnRateWindow `one long/short window pair of a multi-window burn rate alert`
+type BurnRateWindow struct {
+	Long          time.Duration
+	Short         time.Duration
+	LongBurnRate  float64
+	ShortBurnRate float64
+	//  the pair fires when both burn rates are at or above it
+	Threshold float64
+}
+
+// Firing `both windows burn faster than the threshold`
+func (b BurnRateWindow) Firing() bool {
+	return b.LongBurnRate >= b.Threshold && b.ShortBurnRate >= b.Threshold
+}
+
+// SLOBurn `state of an SLO error budget`
+type SLOBurn struct {
+	SLO       string
+	Severity  string
+	Objective float64 //  e.g. 0.999
+	Period    time.Duration
+	//  fraction of the period's error budget already spent
+	BudgetConsumed float64
+	Windows        []BurnRateWindow
+	RunbookURL     string
+}
+
+// ProjectedExhaustion `time until the budget is gone at the fastest firing burn rate, 0 if none fires`
+func (s SLOBurn) ProjectedExhaustion() time.Duration {
+	var rate float64
+	for _, window := range s.Windows {
+		if window.Firing() && window.ShortBurnRate > rate {
+			rate = window.ShortBurnRate
+		}
+	}
+	if 0 == rate {
+		return 0
+	}
+
+	remaining := 1 - s.BudgetConsumed
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	//  a burn rate of 1 spends exactly the budget over the period
+	return time.Duration(float64(s.Period) * remaining / rate)
+}
+
+// FormatSLOBurn `render the standard multi-window burn rate alert as markdown`
+func FormatSLOBurn(burn SLOBurn) *message.MarkdownMessage {
+	severity := burn.Severity
+	if "" == severity {
+		severity = "ticket"
+	}
+	title := fmt.Sprintf("[%s] %s is burning its error budget", severity, burn.SLO)
+
+	lines := []string{
+		"#### " + title,
+		fmt.Sprintf("**Objective**: %s%% over %s", trimFloat(burn.Objective*100), formatSpan(burn.Period)),
+		fmt.Sprintf("**Budget consumed**: %.1f%%", burn.BudgetConsumed*100),
+	}
+	if exhaustion := burn.ProjectedExhaustion(); 0 != exhaustion {
+		lines = append(lines, "**Projected exhaustion**: in "+formatSpan(exhaustion))
+	}
+	lines = append(lines, "", "**Burn rate** (long / short window):")
+	for _, window := range burn.Windows {
+		state := "ok"
+		if window.Firing() {
+			state = "**firing**"
+		}
+		lines = append(lines, fmt.Sprintf("- %s / %s: %sx / %sx (threshold %sx) %s",
+			formatSpan(window.Long), formatSpan(window.Short),
+			trimFloat(window.LongBurnRate), trimFloat(window.ShortBurnRate), trimFloat(window.Threshold), state))
+	}
+	if "" != burn.RunbookURL {
+		lines = append(lines, "", "[Runbook]("+burn.RunbookURL+")")
+	}
+
+	return &message.MarkdownMessage{Title: title, Text: strings.Join(lines, "\n")}
+}
+
+// formatSpan compact duration like 30d, 6h, 5m or 2d 3h
+func formatSpan(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d >= day && 0 == d%day:
+		return fmt.Sprintf("%dd", d/day)
+	case d >= day:
+		return fmt.Sprintf("%dd %dh", d/day, (d%day)/time.Hour)
+	case d >= time.Hour && 0 == d%time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	}
+
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
+
+// trimFloat at most two decimals without trailing zeros
+func trimFloat(f float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", f), "0"), ".")
+}
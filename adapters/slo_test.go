@@ -0,0 +1,47 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+func TestFormatSLOBurn(t *testing.T) {
+	burn := SLOBurn{
+		SLO:            "checkout-availability",
+		Severity:       "page",
+		Objective:      0.999,
+		Period:         30 * 24 * time.Hour,
+		BudgetConsumed: 0.5,
+		Windows: []BurnRateWindow{
+			{Long: time.Hour, Short: 5 * time.Minute, LongBurnRate: 15, ShortBurnRate: 15, Threshold: 14.4},
+			{Long: 6 * time.Hour, Short: 30 * time.Minute, LongBurnRate: 4, ShortBurnRate: 7.5, Threshold: 6},
+		},
+		RunbookURL: "https://runbooks/checkout",
+	}
+
+	//  half the budget left at 15x: 30d * 0.5 / 15 = 1d
+	if 24*time.Hour != burn.ProjectedExhaustion() {
+		t.Errorf("exhaustion should be 1d, got %s", burn.ProjectedExhaustion())
+	}
+
+	msg := FormatSLOBurn(burn)
+	for _, want := range []string{
+		"#### [page] checkout-availability is burning its error budget",
+		"**Objective**: 99.9% over 30d",
+		"**Budget consumed**: 50.0%",
+		"**Projected exhaustion**: in 1d",
+		"- 1h / 5m: 15x / 15x (threshold 14.4x) **firing**",
+		"- 6h / 30m: 4x / 7.5x (threshold 6x) ok",
+		"[Runbook](https://runbooks/checkout)",
+	} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("missing %q in:\n%s", want, msg.Text)
+		}
+	}
+	if 0 != len(message.Lint(msg.Text)) {
+		t.Errorf("formatted alert should pass Lint, got %v", message.Lint(msg.Text))
+	}
+}
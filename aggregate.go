@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAggregatorClosed `returned when sending through a closed Aggregator`
+var ErrAggregatorClosed = errors.New("aggregator is closed")
+
+// DefaultAggregateWindow `how long an Aggregator buffers when Window is 0`
+const DefaultAggregateWindow = 30 * time.Second
+
+var aggregateDigits = regexp.MustCompile(`\d+`)
+
+// AggregateKey `default grouping, the content with numbers masked so "disk 91%" and "disk 93%" group together`
+//
+// Payloads carrying an idempotency tag, e.g. relayed by another replica with IdempotencyFooter,
+// are grouped by their tag instead.
+func AggregateKey(payload *PayLoad) string {
+	content := payloadContent(payload)
+	if tag, ok := ParseIdempotencyTag(content); ok {
+		return "idem:" + tag
+	}
+
+	return aggregateDigits.ReplaceAllString(content, "#")
+}
+
+// Aggregator `buffer messages for a window and send one digest instead of an alert storm`
+//
+// The window opens with the first buffered message. When it closes, a lone message is sent as
+// is, anything else becomes a single markdown digest with one line per group, e.g. "17
+// occurrences of disk full on db-1, first at ..., last at ...". Everybody mentioned by a buffered
+// message is mentioned by the digest.
+type Aggregator struct {
+	//  0 uses DefaultAggregateWindow
+	Window time.Duration
+	//  groups near-identical messages, nil uses AggregateKey
+	Key func(payload *PayLoad) string
+	//  called when a digest cannot be sent, Send has returned long before
+	OnError func(err error)
+
+	webHook *WebHook
+
+	mu     sync.Mutex
+	groups []*aggregateGroup
+	index  map[string]*aggregateGroup
+	timer  *time.Timer
+	//  bumped whenever a window ends, so a timer firing late leaves the next window alone
+	generation uint64
+	closed     bool
+}
+
+// aggregateGroup messages sharing a key, the first one represents them
+type aggregateGroup struct {
+	payload     *PayLoad
+	count       int
+	first, last time.Time
+}
+
+// NewAggregator `digest messages sent through webHook over window`
+func NewAggregator(webHook *WebHook, window time.Duration) *Aggregator {
+	return &Aggregator{Window: window, webHook: webHook}
+}
+
+// Send `buffer msg for the current window, an Emergency robot sends it at once`
+func (a *Aggregator) Send(msg Message) error {
+	payload, err := msg.Payload()
+	if nil != err {
+		return err
+	}
+	//  pages must not wait for the window
+	if a.webHook.Emergency {
+		return a.webHook.SendPayload(payload)
+	}
+
+	key := AggregateKey
+	if nil != a.Key {
+		key = a.Key
+	}
+	k := key(payload)
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return ErrAggregatorClosed
+	}
+
+	if nil == a.index {
+		a.index = make(map[string]*aggregateGroup)
+	}
+	group, ok := a.index[k]
+	if !ok {
+		group = &aggregateGroup{payload: payload, first: now}
+		a.index[k] = group
+		a.groups = append(a.groups, group)
+	} else {
+		group.payload.At = mergeAt(group.payload.At, payload.At)
+	}
+	group.count++
+	group.last = now
+
+	if nil == a.timer {
+		generation := a.generation
+		a.timer = time.AfterFunc(a.window(), func() {
+			if err := a.expire(generation); nil != err && nil != a.OnError {
+				a.OnError(err)
+			}
+		})
+	}
+
+	return nil
+}
+
+// Flush `send what is buffered now and start a new window`
+func (a *Aggregator) Flush() error {
+	a.mu.Lock()
+	groups := a.take()
+	a.mu.Unlock()
+
+	return a.send(groups)
+}
+
+// expire end the window the timer of generation was started for, unless a Flush ended it already
+func (a *Aggregator) expire(generation uint64) error {
+	a.mu.Lock()
+	if generation != a.generation {
+		a.mu.Unlock()
+		return nil
+	}
+	groups := a.take()
+	a.mu.Unlock()
+
+	return a.send(groups)
+}
+
+// take end the current window and hand out its groups, the caller holds mu
+func (a *Aggregator) take() []*aggregateGroup {
+	groups := a.groups
+	a.groups, a.index = nil, nil
+	if nil != a.timer {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.generation++
+
+	return groups
+}
+
+// send send the groups of an ended window, a lone message as is and anything else as a digest
+func (a *Aggregator) send(groups []*aggregateGroup) error {
+	if 0 == len(groups) {
+		return nil
+	}
+	if 1 == len(groups) && 1 == groups[0].count {
+		return a.webHook.SendPayload(groups[0].payload)
+	}
+
+	return a.webHook.Send(digest(groups))
+}
+
+// Close `send what is buffered and stop accepting messages`
+func (a *Aggregator) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+
+	return a.Flush()
+}
+
+func (a *Aggregator) window() time.Duration {
+	if 0 == a.Window {
+		return DefaultAggregateWindow
+	}
+
+	return a.Window
+}
+
+// digest one markdown message summarizing groups
+func digest(groups []*aggregateGroup) *MarkdownMessage {
+	total := 0
+	msg := &MarkdownMessage{}
+	lines := make([]string, 0, len(groups))
+	for _, group := range groups {
+		total += group.count
+		summary := Compact(payloadContent(group.payload), CompactLength)
+		if 1 == group.count {
+			lines = append(lines, fmt.Sprintf("- %s, at %s", summary, FormatTime(group.first)))
+		} else {
+			lines = append(lines, fmt.Sprintf("- **%d** occurrences of %s, first at %s, last at %s",
+				group.count, summary, FormatTime(group.first), FormatTime(group.last)))
+		}
+
+		if at := group.payload.At; nil != at {
+			msg.IsAtAll = msg.IsAtAll || at.IsAtAll
+			msg.AtMobiles = appendMissing(msg.AtMobiles, at.AtMobiles...)
+			msg.AtUserIDs = appendMissing(msg.AtUserIDs, at.AtUserIds...)
+			if confirm := at.Confirmation(); "" != confirm && 0 == len(msg.At) {
+				msg.At = []AtOption{ConfirmAtAll(confirm)}
+			}
+		}
+	}
+
+	msg.Title = fmt.Sprintf("Digest: %d messages", total)
+	msg.Text = "#### " + msg.Title + "\n\n" + strings.Join(lines, "\n")
+
+	return msg
+}
+
+// mergeAt mention everybody mentioned by either section
+func mergeAt(at, other *At) *At {
+	if nil == other {
+		return at
+	}
+	if nil == at {
+		at = &At{}
+	}
+
+	merged := &At{
+		IsAtAll:   at.IsAtAll || other.IsAtAll,
+		AtMobiles: appendMissing(append([]string(nil), at.AtMobiles...), other.AtMobiles...),
+		AtUserIds: appendMissing(append([]string(nil), at.AtUserIds...), other.AtUserIds...),
+	}
+	//  keep the @all confirmation of whichever section carries one
+	for _, confirm := range []string{at.Confirmation(), other.Confirmation()} {
+		if "" != confirm {
+			ConfirmAtAll(confirm)(merged)
+			break
+		}
+	}
+
+	return merged
+}
+
+// appendMissing append the values not in list yet
+func appendMissing(list []string, values ...string) []string {
+	for _, value := range values {
+		if !inStrings(value, list) {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
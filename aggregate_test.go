@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestAggregator(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	aggregator := NewAggregator(webHook, 20*time.Millisecond)
+	for i := 0; i < 17; i++ {
+		_ = aggregator.Send(&TextMessage{Content: fmt.Sprintf("disk usage on db-1 at %d%%", 80+i)})
+	}
+	_ = aggregator.Send(&TextMessage{Content: "replica lag on db-2", AtMobiles: []string{"13800000000"}})
+	if 0 != len(server.Requests()) {
+		t.Fatal("messages should be buffered for the window")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for 0 == len(server.Requests()) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if 1 != len(server.Requests()) {
+		t.Fatalf("one digest should be sent, got %d requests", len(server.Requests()))
+	}
+
+	var payload PayLoad
+	_ = json.Unmarshal(server.Requests()[0].Body, &payload)
+	text := payload.Markdown.Text
+	if "Digest: 18 messages" != payload.Markdown.Title {
+		t.Errorf("digest title is wrong: %q", payload.Markdown.Title)
+	}
+	if !strings.Contains(text, "**17** occurrences of disk usage on db-1 at 80%, first at") || !strings.Contains(text, "- replica lag on db-2, at") {
+		t.Errorf("digest should summarize every group:\n%s", text)
+	}
+	if nil == payload.At || 1 != len(payload.At.AtMobiles) || !strings.Contains(text, "@13800000000") {
+		t.Errorf("digest should keep the mentions, got %+v", payload.At)
+	}
+
+	_ = aggregator.Send(&TextMessage{Content: "lonely"})
+	if err := aggregator.Close(); nil != err {
+		t.Fatal(err)
+	}
+	if payload, _ := server.Requests()[1].Payload(); "lonely" != payload.Text.Content {
+		t.Errorf("a lone message should be sent as is, got %+v", payload)
+	}
+	if err := aggregator.Send(&TextMessage{Content: "late"}); ErrAggregatorClosed != err {
+		t.Errorf("send after close should fail, got %v", err)
+	}
+}
+
+func TestAggregateKeyIdempotencyTag(t *testing.T) {
+	first := &PayLoad{MsgType: "text", Text: &Text{Content: "deploy finished on web-1\n\u200bidem:0123456789ab"}}
+	relayed := &PayLoad{MsgType: "text", Text: &Text{Content: "[relay] deploy finished on web-1\n\u200bidem:0123456789ab"}}
+	if AggregateKey(first) != AggregateKey(relayed) {
+		t.Error("payloads with the same idempotency tag should group together")
+	}
+
+	other := &PayLoad{MsgType: "text", Text: &Text{Content: "deploy finished on web-1\n\u200bidem:abcdef012345"}}
+	if AggregateKey(first) == AggregateKey(other) {
+		t.Error("payloads with different idempotency tags should not group together")
+	}
+}
+
+func TestAggregatorConfirmedAtAll(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.AtAllPolicy, webHook.AtAllConfirmToken = AtAllRequireConfirm, "page"
+
+	aggregator := NewAggregator(webHook, time.Hour)
+	_ = aggregator.Send(&TextMessage{Content: "db-1 down"})
+	_ = aggregator.Send(&TextMessage{Content: "db-1 down", At: []AtOption{ConfirmAtAll("page")}})
+	_ = aggregator.Send(&TextMessage{Content: "db-2 down"})
+	if err := aggregator.Flush(); nil != err {
+		t.Fatalf("confirmed @all should survive the digest, got %v", err)
+	}
+	if payload, _ := server.Requests()[0].Payload(); !payload.At.IsAtAll {
+		t.Errorf("digest should mention everyone, got %+v", payload.At)
+	}
+}
+
+func TestAggregatorStaleTimer(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	aggregator := NewAggregator(webHook, time.Hour)
+	_ = aggregator.Send(&TextMessage{Content: "first window"})
+	stale := aggregator.generation
+	_ = aggregator.Flush()
+	_ = aggregator.Send(&TextMessage{Content: "second window"})
+
+	//  a timer of the first window firing after the Flush
+	if err := aggregator.expire(stale); nil != err || 1 != len(server.Requests()) {
+		t.Errorf("stale timer should not end the second window, got %v and %d requests", err, len(server.Requests()))
+	}
+	_ = aggregator.Close()
+	if 2 != len(server.Requests()) {
+		t.Errorf("second window should be sent on close, got %d requests", len(server.Requests()))
+	}
+}
@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskMobile `keep the first 3 and last 4 digits, e.g. 138****8000`
+func MaskMobile(mobile string) string {
+	n := utf8.RuneCountInString(mobile)
+	if n <= 7 {
+		return mobile
+	}
+
+	runes := []rune(mobile)
+	return string(runes[:3]) + strings.Repeat("*", n-7) + string(runes[n-4:])
+}
+
+// anonymize replace mentioned mobiles in the visible body, the At block is left untouched
+func (w *WebHook) anonymize(payload *PayLoad) {
+	if nil == w.Anonymizer || nil == payload.At || 0 == len(payload.At.AtMobiles) {
+		return
+	}
+
+	var pairs []string
+	for _, mobile := range payload.At.AtMobiles {
+		if "" != mobile {
+			pairs = append(pairs, mobile, w.Anonymizer(mobile))
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+	rewriteContent(payload, replacer.Replace)
+}
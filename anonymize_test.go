@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMaskMobile(t *testing.T) {
+	cases := map[string]string{
+		"13800138000":    "138****8000",
+		"+8613800138000": "+86*******8000",
+		"1234567":        "1234567",
+	}
+	for mobile, want := range cases {
+		if got := MaskMobile(mobile); want != got {
+			t.Errorf("MaskMobile(%s): want %s, got %s", mobile, want, got)
+		}
+	}
+}
+
+func TestWebHookAnonymizer(t *testing.T) {
+	var got PayLoad
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Anonymizer = MaskMobile
+
+	if err := webHook.SendMarkdownMsg("oncall", "@13800138000 please check, cc 13900139000", false, "13800138000"); nil != err {
+		t.Fatal(err)
+	}
+	if "@138****8000 please check, cc 13900139000" != got.Markdown.Text {
+		t.Errorf("only mentioned mobiles should be masked, got %q", got.Markdown.Text)
+	}
+	if "13800138000" != got.At.AtMobiles[0] {
+		t.Errorf("at block should keep the real mobile, got %v", got.At.AtMobiles)
+	}
+}
@@ -0,0 +1,378 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueClosed `returned when enqueueing on a closed AsyncWebHook`
+var ErrQueueClosed = errors.New("async webhook is closed")
+
+// ErrQueueFull `returned or reported when a full queue rejects a payload`
+var ErrQueueFull = errors.New("async webhook queue is full")
+
+// Priority `lane of a payload, critical payloads are sent before normal ones and normal before low`
+type Priority int
+
+// priorities, the zero value is normal
+const (
+	PriorityNormal Priority = iota
+	PriorityCritical
+	PriorityLow
+)
+
+// String `name of the priority`
+func (p Priority) String() string {
+	switch p {
+	case PriorityCritical:
+		return "critical"
+	case PriorityLow:
+		return "low"
+	}
+
+	return "normal"
+}
+
+// lane index of the priority, lanes are polled in index order
+func (p Priority) lane() int {
+	switch p {
+	case PriorityCritical:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+
+	return 1
+}
+
+// asyncLanes queues of one worker, one per priority
+type asyncLanes [3]chan asyncJob
+
+// OverflowPolicy `what Enqueue does when the queue of the chosen worker is full`
+type OverflowPolicy int
+
+// overflow policies
+const (
+	//  wait for room, the default
+	OverflowBlock OverflowPolicy = iota
+	//  discard the payload and return nil, its callback sees ErrQueueFull
+	OverflowDrop
+	//  discard the payload and return ErrQueueFull
+	OverflowError
+)
+
+// AsyncWebHook `send payloads from background workers`
+//
+// Payloads sharing a key are always handled by the same worker, so e.g. every update of incident-42
+// is delivered in the order it was enqueued while unrelated keys are sent in parallel. Every worker
+// has a lane per Priority and always takes the next payload from the most urgent non-empty lane,
+// so when the rate limiter holds the workers back a page jumps ahead of queued chatter. Order is
+// only kept among payloads of the same key and priority.
+type AsyncWebHook struct {
+	//  first for 64-bit atomic alignment on 32-bit platforms
+	dropped uint64
+
+	//  called from the worker when a send fails
+	OnError func(key string, payload *PayLoad, err error)
+	//  set before the first Enqueue
+	Overflow OverflowPolicy
+
+	webHook *WebHook
+	queues  []asyncLanes
+	wg      sync.WaitGroup
+	//  bounds every send, cancelled when Shutdown gives up on draining
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	next   uint32
+}
+
+type asyncJob struct {
+	key     string
+	payload *PayLoad
+	//  per-payload completion callback, may be nil
+	done func(err error)
+	//  set for barrier markers, which carry no payload
+	barrier *asyncBarrier
+}
+
+// asyncBarrier closes done once every worker has reached its marker
+type asyncBarrier struct {
+	pending int32
+	done    chan struct{}
+}
+
+func (b *asyncBarrier) reach() {
+	if 0 == atomic.AddInt32(&b.pending, -1) {
+		close(b.done)
+	}
+}
+
+// NewAsyncWebHook `start workers sending through webHook, each with a queue of queueSize per priority`
+func NewAsyncWebHook(webHook *WebHook, workers, queueSize int) *AsyncWebHook {
+	if workers < 1 {
+		workers = 1
+	}
+
+	a := &AsyncWebHook{webHook: webHook, queues: make([]asyncLanes, workers)}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	for i := range a.queues {
+		for lane := range a.queues[i] {
+			a.queues[i][lane] = make(chan asyncJob, queueSize)
+		}
+		a.wg.Add(1)
+		go a.work(a.queues[i])
+	}
+
+	return a
+}
+
+// Enqueue `queue a payload with normal priority, payloads with the same non-empty key keep their order`
+func (a *AsyncWebHook) Enqueue(key string, payload *PayLoad) error {
+	return a.EnqueuePriority(PriorityNormal, key, payload, nil)
+}
+
+// EnqueueFunc `Enqueue and call done from the worker once the payload is sent or failed`
+//
+// done runs before OnError and receives the same error, or ErrQueueFull when OverflowDrop
+// discarded the payload.
+func (a *AsyncWebHook) EnqueueFunc(key string, payload *PayLoad, done func(err error)) error {
+	return a.EnqueuePriority(PriorityNormal, key, payload, done)
+}
+
+// EnqueuePriority `EnqueueFunc in the lane of priority, done may be nil`
+func (a *AsyncWebHook) EnqueuePriority(priority Priority, key string, payload *PayLoad, done func(err error)) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return ErrQueueClosed
+	}
+
+	job := asyncJob{key: key, payload: payload, done: done}
+	queue := a.queues[a.worker(key)][priority.lane()]
+	if OverflowBlock == a.Overflow {
+		select {
+		case queue <- job:
+			return nil
+		case <-a.ctx.Done():
+			return ErrQueueClosed
+		}
+	}
+
+	select {
+	case queue <- job:
+		return nil
+	default:
+	}
+
+	atomic.AddUint64(&a.dropped, 1)
+	if OverflowError == a.Overflow {
+		return ErrQueueFull
+	}
+	if nil != done {
+		done(ErrQueueFull)
+	}
+	return nil
+}
+
+// Len `payloads waiting in the queues`
+func (a *AsyncWebHook) Len() int {
+	n := 0
+	for _, lanes := range a.queues {
+		for _, queue := range lanes {
+			n += len(queue)
+		}
+	}
+
+	return n
+}
+
+// Cap `total capacity of the queues, a single key and priority can use only one lane`
+func (a *AsyncWebHook) Cap() int {
+	n := 0
+	for _, lanes := range a.queues {
+		for _, queue := range lanes {
+			n += cap(queue)
+		}
+	}
+
+	return n
+}
+
+// Dropped `payloads rejected by a full queue so far`
+func (a *AsyncWebHook) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Barrier `channel closed once everything enqueued before the call has been handled`
+//
+// A marker is queued behind the pending payloads of every lane, so payloads enqueued after
+// Barrier returns do not delay it. Handled means sent or reported to OnError.
+func (a *AsyncWebHook) Barrier() (<-chan struct{}, error) {
+	return a.barrier(context.Background())
+}
+
+// barrier Barrier giving up when ctx is done before every marker is queued
+func (a *AsyncWebHook) barrier(ctx context.Context) (<-chan struct{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return nil, ErrQueueClosed
+	}
+
+	barrier := &asyncBarrier{pending: int32(len(a.queues) * len(asyncLanes{})), done: make(chan struct{})}
+	for _, lanes := range a.queues {
+		for _, queue := range lanes {
+			//  markers already queued are simply passed by the workers
+			select {
+			case queue <- asyncJob{barrier: barrier}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-a.ctx.Done():
+				return nil, ErrQueueClosed
+			}
+		}
+	}
+
+	return barrier.done, nil
+}
+
+// Flush `wait until everything enqueued before the call has been handled, or ctx is done`
+func (a *AsyncWebHook) Flush(ctx context.Context) error {
+	done, err := a.barrier(ctx)
+	if nil != err {
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close `stop accepting payloads and wait until the queued ones are sent`
+func (a *AsyncWebHook) Close() {
+	_ = a.Shutdown(context.Background())
+}
+
+// Shutdown `stop accepting payloads and drain the queues, or give up when ctx is done`
+//
+// Call it before the process exits so payloads enqueued just before, e.g. a shutdown
+// notification, are still delivered. When ctx ends first the sends in flight are cancelled and
+// the payloads still queued are reported to their callback and OnError with context.Canceled,
+// then ctx.Err() is returned, Enqueue calls still waiting for room fail with ErrQueueClosed.
+// Calling it again waits for the first call to finish.
+func (a *AsyncWebHook) Shutdown(ctx context.Context) error {
+	defer a.cancel()
+
+	//  an Enqueue or Barrier waiting for room holds the lock until cancel releases it
+	drained := make(chan struct{})
+	go func() {
+		a.mu.Lock()
+		if !a.closed {
+			a.closed = true
+			for _, lanes := range a.queues {
+				for _, queue := range lanes {
+					close(queue)
+				}
+			}
+		}
+		a.mu.Unlock()
+
+		a.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		a.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+// worker pick the worker for key, keyless payloads are spread round robin
+func (a *AsyncWebHook) worker(key string) int {
+	if "" == key {
+		return int(atomic.AddUint32(&a.next, 1) % uint32(len(a.queues)))
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(a.queues)))
+}
+
+func (a *AsyncWebHook) work(lanes asyncLanes) {
+	defer a.wg.Done()
+	for {
+		job, ok := nextJob(&lanes)
+		if !ok {
+			return
+		}
+		if nil != job.barrier {
+			job.barrier.reach()
+			continue
+		}
+		err := a.webHook.sendPayloadContext(a.ctx, job.payload)
+		if nil != job.done {
+			job.done(err)
+		}
+		if nil != err && nil != a.OnError {
+			a.OnError(job.key, job.payload, err)
+		}
+	}
+}
+
+// nextJob job of the most urgent lane, waiting when all are empty, false once every lane is closed
+//
+// Closed lanes are set to nil so they are skipped from then on.
+func nextJob(lanes *asyncLanes) (asyncJob, bool) {
+	for {
+		open := false
+		for i, lane := range lanes {
+			if nil == lane {
+				continue
+			}
+			select {
+			case job, ok := <-lane:
+				if ok {
+					return job, true
+				}
+				lanes[i] = nil
+				continue
+			default:
+			}
+			open = true
+		}
+		if !open {
+			return asyncJob{}, false
+		}
+
+		select {
+		case job, ok := <-lanes[0]:
+			if ok {
+				return job, true
+			}
+			lanes[0] = nil
+		case job, ok := <-lanes[1]:
+			if ok {
+				return job, true
+			}
+			lanes[1] = nil
+		case job, ok := <-lanes[2]:
+			if ok {
+				return job, true
+			}
+			lanes[2] = nil
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestAsyncWebHookKeyOrdering(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload PayLoad
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		parts := strings.SplitN(payload.Text.Content, ":", 2)
+		mu.Lock()
+		received[parts[0]] = append(received[parts[0]], parts[1])
+		mu.Unlock()
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 4, 16)
+
+	keys := []string{"incident-1", "incident-2", "incident-3"}
+	for i := 0; i < 20; i++ {
+		for _, key := range keys {
+			payload := &PayLoad{MsgType: "text", Text: &Text{Content: fmt.Sprintf("%s:%d", key, i)}}
+			if err := async.Enqueue(key, payload); nil != err {
+				t.Fatal(err)
+			}
+		}
+	}
+	async.Close()
+
+	for _, key := range keys {
+		if 20 != len(received[key]) {
+			t.Fatalf("%s should receive 20 updates, got %d", key, len(received[key]))
+		}
+		for i, got := range received[key] {
+			if fmt.Sprint(i) != got {
+				t.Fatalf("%s updates out of order: %v", key, received[key])
+			}
+		}
+	}
+
+	if err := async.Enqueue("incident-1", &PayLoad{}); ErrQueueClosed != err {
+		t.Errorf("enqueue after close should fail, got %v", err)
+	}
+}
+
+func TestAsyncWebHookFlush(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 3, 16)
+
+	for i := 0; i < 10; i++ {
+		_ = async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: fmt.Sprintf("before %d", i)}})
+	}
+	if err := async.Flush(context.Background()); nil != err {
+		t.Fatal(err)
+	}
+	if 10 != len(server.Requests()) {
+		t.Errorf("everything enqueued before Flush should be sent, got %d", len(server.Requests()))
+	}
+
+	async.Close()
+	if err := async.Flush(context.Background()); ErrQueueClosed != err {
+		t.Errorf("flush after close should fail, got %v", err)
+	}
+}
+
+func TestAsyncWebHookFlushContext(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-block
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 4)
+	defer async.Close()
+	defer close(block)
+
+	_ = async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: "slow"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := async.Flush(ctx); context.DeadlineExceeded != err {
+		t.Errorf("flush should give up with ctx, got %v", err)
+	}
+}
+
+func TestAsyncWebHookFlushFullQueue(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-block
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 1)
+	defer async.Close()
+	defer close(block)
+
+	//  one in flight, one filling the lane
+	for i := 0; i < 2; i++ {
+		_ = async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: "slow"}})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := async.Flush(ctx); context.DeadlineExceeded != err {
+		t.Errorf("flush should give up with ctx, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("flush should not wait for room in a full lane past ctx")
+	}
+}
+
+func TestAsyncWebHookOverflow(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 1)
+	async.Overflow = OverflowError
+	if 3 != async.Cap() {
+		t.Errorf("capacity should be 1 per priority, got %d", async.Cap())
+	}
+
+	payload := func() *PayLoad { return &PayLoad{MsgType: "text", Text: &Text{Content: "hello"}} }
+	sent := make(chan error, 3)
+	done := func(err error) { sent <- err }
+	_ = async.EnqueueFunc("", payload(), done)
+	//  wait for the worker to pick up the first payload so the queue is empty again
+	for 0 != async.Len() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := async.EnqueueFunc("", payload(), done); nil != err {
+		t.Fatal(err)
+	}
+	if err := async.EnqueueFunc("", payload(), done); ErrQueueFull != err {
+		t.Errorf("full queue should return ErrQueueFull, got %v", err)
+	}
+
+	async.Overflow = OverflowDrop
+	if err := async.EnqueueFunc("", payload(), done); nil != err {
+		t.Errorf("drop policy should not return an error, got %v", err)
+	}
+	if err := <-sent; ErrQueueFull != err {
+		t.Errorf("dropped payload callback should see ErrQueueFull, got %v", err)
+	}
+	if 2 != async.Dropped() {
+		t.Errorf("2 payloads should be dropped, got %d", async.Dropped())
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-sent; nil != err {
+			t.Errorf("queued payloads should be reported sent, got %v", err)
+		}
+	}
+	async.Close()
+}
+
+func TestAsyncWebHookShutdown(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 2, 16)
+	for i := 0; i < 5; i++ {
+		_ = async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: "deploy finished"}})
+	}
+	if err := async.Shutdown(context.Background()); nil != err {
+		t.Fatal(err)
+	}
+	if 5 != len(server.Requests()) {
+		t.Errorf("shutdown should drain the queues, got %d requests", len(server.Requests()))
+	}
+	if err := async.Enqueue("", &PayLoad{}); ErrQueueClosed != err {
+		t.Errorf("enqueue after shutdown should fail, got %v", err)
+	}
+}
+
+func TestAsyncWebHookShutdownContext(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 4)
+
+	var mu sync.Mutex
+	var errs []error
+	for i := 0; i < 3; i++ {
+		_ = async.EnqueueFunc("", &PayLoad{MsgType: "text", Text: &Text{Content: "stuck"}}, func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := async.Shutdown(ctx); context.DeadlineExceeded != err {
+		t.Errorf("shutdown should give up with ctx, got %v", err)
+	}
+	if 3 != len(errs) {
+		t.Fatalf("every payload should be reported, got %d", len(errs))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("abandoned payloads should be reported as cancelled, got %v", err)
+		}
+	}
+}
+
+func TestAsyncWebHookShutdownBlockedEnqueue(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 1)
+	for i := 0; i < 2; i++ {
+		_ = async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: "stuck"}})
+	}
+	enqueued := make(chan error)
+	go func() {
+		enqueued <- async.Enqueue("", &PayLoad{MsgType: "text", Text: &Text{Content: "waiting"}})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := async.Shutdown(ctx); context.DeadlineExceeded != err {
+		t.Errorf("shutdown should give up with ctx, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("shutdown should not wait behind a blocked enqueue past ctx")
+	}
+	if err := <-enqueued; nil != err && ErrQueueClosed != err {
+		t.Errorf("blocked enqueue should be queued or refused, got %v", err)
+	}
+}
+
+func TestAsyncWebHookPriority(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload PayLoad
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if "busy" == payload.Text.Content {
+			<-release
+		}
+		mu.Lock()
+		sent = append(sent, payload.Text.Content)
+		mu.Unlock()
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	async := NewAsyncWebHook(webHook, 1, 16)
+	text := func(content string) *PayLoad { return &PayLoad{MsgType: "text", Text: &Text{Content: content}} }
+
+	_ = async.Enqueue("", text("busy"))
+	for 0 != async.Len() {
+		time.Sleep(time.Millisecond)
+	}
+	_ = async.EnqueuePriority(PriorityLow, "", text("low"), nil)
+	_ = async.Enqueue("", text("normal 1"))
+	_ = async.Enqueue("", text("normal 2"))
+	_ = async.EnqueuePriority(PriorityCritical, "", text("critical"), nil)
+	close(release)
+	async.Close()
+
+	want := []string{"busy", "critical", "normal 1", "normal 2", "low"}
+	if fmt.Sprint(want) != fmt.Sprint(sent) {
+		t.Errorf("lanes should be drained by priority, got %v", sent)
+	}
+	if "critical" != PriorityCritical.String() || "normal" != Priority(0).String() {
+		t.Error("priority names are wrong")
+	}
+}
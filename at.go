@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+)
+
+// SendText `send a text message with explicit mentions`
+func (w *WebHook) SendText(content string, options ...AtOption) error {
+	return w.SendTextContext(context.Background(), content, options...)
+}
+
+// SendTextContext `send a text message with explicit mentions, bounded by ctx`
+func (w *WebHook) SendTextContext(ctx context.Context, content string, options ...AtOption) error {
+	return w.SendContext(ctx, &TextMessage{Content: content, At: options})
+}
+
+// SendMarkdown `send a markdown message with explicit mentions`
+func (w *WebHook) SendMarkdown(title, content string, options ...AtOption) error {
+	return w.SendMarkdownContext(context.Background(), title, content, options...)
+}
+
+// SendMarkdownContext `send a markdown message with explicit mentions, bounded by ctx`
+func (w *WebHook) SendMarkdownContext(ctx context.Context, title, content string, options ...AtOption) error {
+	return w.SendContext(ctx, &MarkdownMessage{Title: title, Text: content, At: options})
+}
+
+// SendTextf `format and send a text message`
+//
+// AtOption values among args are taken out before formatting and applied as mentions, e.g.
+// SendTextf("%s is down", service, AtMobiles(oncall)).
+func (w *WebHook) SendTextf(format string, args ...interface{}) error {
+	content, options := sprintfAt(format, args)
+	return w.SendText(content, options...)
+}
+
+// SendMarkdownf `format and send a markdown message, see SendTextf for mentions`
+func (w *WebHook) SendMarkdownf(title, format string, args ...interface{}) error {
+	content, options := sprintfAt(format, args)
+	return w.SendMarkdown(title, content, options...)
+}
+
+// sprintfAt split mentions from format arguments
+func sprintfAt(format string, args []interface{}) (string, []AtOption) {
+	var options []AtOption
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if option, ok := arg.(AtOption); ok {
+			options = append(options, option)
+			continue
+		}
+		values = append(values, arg)
+	}
+
+	return fmt.Sprintf(format, values...), options
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendText(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	if err := webHook.SendText("deploy done", AtAll()); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.SendMarkdown("deploy", "#### done", AtUserIDs("manager4711")); nil != err {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	var text, markdown PayLoad
+	_ = json.Unmarshal(requests[0].Body, &text)
+	_ = json.Unmarshal(requests[1].Body, &markdown)
+	if !text.At.IsAtAll {
+		t.Error("text should mention everyone")
+	}
+	if "markdown" != markdown.MsgType || !reflect.DeepEqual([]string{"manager4711"}, markdown.At.AtUserIds) {
+		t.Errorf("markdown should mention the user id, got %+v", markdown.At)
+	}
+}
+
+func TestSendTextf(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	if err := webHook.SendTextf("%s is down for %dm", "billing", 5, AtMobiles("13800138000")); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.SendMarkdownf("deploy", "#### %s done", "billing", AtAll()); nil != err {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	var text, markdown PayLoad
+	_ = json.Unmarshal(requests[0].Body, &text)
+	_ = json.Unmarshal(requests[1].Body, &markdown)
+	if "billing is down for 5m" != text.Text.Content || "13800138000" != text.At.AtMobiles[0] {
+		t.Errorf("unexpected text payload %+v %+v", text.Text, text.At)
+	}
+	if "#### billing done" != markdown.Markdown.Text || !markdown.At.IsAtAll {
+		t.Errorf("unexpected markdown payload %+v %+v", markdown.Markdown, markdown.At)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// ErrAtAllNotAllowed `returned when AtAllPolicy refuses an @all message`
+var ErrAtAllNotAllowed = errors.New("mentioning everyone is not allowed on this robot")
+
+// AtAllPolicy `what a robot does with messages mentioning everyone`
+type AtAllPolicy int
+
+// @all policies
+const (
+	//  send as is
+	AtAllAllow AtAllPolicy = iota
+	//  send without mentioning everyone
+	AtAllStrip
+	//  refuse with ErrAtAllNotAllowed
+	AtAllReject
+	//  refuse unless the message was built with ConfirmAtAll(WebHook.AtAllConfirmToken)
+	AtAllRequireConfirm
+)
+
+// ParseAtAllPolicy `policy from its config name: allow, strip, reject or confirm`
+func ParseAtAllPolicy(name string) (AtAllPolicy, error) {
+	switch name {
+	case "", "allow":
+		return AtAllAllow, nil
+	case "strip":
+		return AtAllStrip, nil
+	case "reject":
+		return AtAllReject, nil
+	case "confirm":
+		return AtAllRequireConfirm, nil
+	}
+
+	return AtAllAllow, fmt.Errorf("unknown @all policy: %q", name)
+}
+
+// ConfirmAtAll `mention everyone on robots with AtAllRequireConfirm`
+func ConfirmAtAll(token string) AtOption {
+	return message.ConfirmAtAll(token)
+}
+
+// guardAtAll apply AtAllPolicy to payload
+func (w *WebHook) guardAtAll(payload *PayLoad) error {
+	if nil == payload.At || !payload.At.IsAtAll {
+		return nil
+	}
+
+	switch w.AtAllPolicy {
+	case AtAllStrip:
+		payload.At.IsAtAll = false
+	case AtAllReject:
+		return ErrAtAllNotAllowed
+	case AtAllRequireConfirm:
+		if "" == w.AtAllConfirmToken || payload.At.Confirmation() != w.AtAllConfirmToken {
+			return ErrAtAllNotAllowed
+		}
+	}
+
+	return nil
+}
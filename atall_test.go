@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestAtAllPolicy(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	webHook.AtAllPolicy = AtAllStrip
+	if err := webHook.SendTextMsg("deploy", true); nil != err {
+		t.Fatal(err)
+	}
+	webhooktest.AssertAtAll(t, server.Requests()[0], false)
+
+	webHook.AtAllPolicy = AtAllReject
+	if err := webHook.SendText("deploy", AtAll()); ErrAtAllNotAllowed != err {
+		t.Errorf("@all should be rejected, got %v", err)
+	}
+	if err := webHook.SendText("deploy", AtMobiles("13800138000")); nil != err {
+		t.Errorf("other mentions should pass, got %v", err)
+	}
+
+	webHook.AtAllPolicy, webHook.AtAllConfirmToken = AtAllRequireConfirm, "yes-page-everyone"
+	if err := webHook.SendText("outage", ConfirmAtAll("wrong")); ErrAtAllNotAllowed != err {
+		t.Errorf("wrong token should be rejected, got %v", err)
+	}
+	if err := webHook.SendText("outage", ConfirmAtAll("yes-page-everyone")); nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	webhooktest.AssertAtAll(t, requests[len(requests)-1], true)
+	if 3 != len(requests) {
+		t.Errorf("rejected messages should not be sent, got %d requests", len(requests))
+	}
+}
+
+func TestParseAtAllPolicy(t *testing.T) {
+	for name, want := range map[string]AtAllPolicy{"": AtAllAllow, "strip": AtAllStrip, "reject": AtAllReject, "confirm": AtAllRequireConfirm} {
+		if got, err := ParseAtAllPolicy(name); nil != err || want != got {
+			t.Errorf("%q: want %v, got %v %v", name, want, got, err)
+		}
+	}
+	if _, err := ParseAtAllPolicy("sometimes"); nil == err {
+		t.Error("unknown policy error should be catch!")
+	}
+}
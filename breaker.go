@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen `returned without sending while the circuit breaker is open`
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState `state of a CircuitBreaker`
+type BreakerState int
+
+// circuit breaker states
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+// String `closed, open or half-open`
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return "closed"
+}
+
+// CircuitBreaker `stops sending to a degraded endpoint instead of piling up blocked requests`
+//
+// Threshold consecutive transient failures (see IsTransient) open the breaker, every send then
+// fails with ErrCircuitOpen until Cooldown has passed. A single probe is let through next: its
+// success closes the breaker, its failure opens it for another Cooldown. Api errors such as a
+// keyword mismatch prove the endpoint is up and do not count, neither do sends whose ctx
+// ended first.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+	//  called outside the lock on every transition, e.g. to switch to a fallback channel
+	OnStateChange func(from, to BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker `open after threshold consecutive failures, probe again after cooldown`
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// WithCircuitBreaker `guard the robot with breaker`
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(w *WebHook) {
+		w.Breaker = breaker
+	}
+}
+
+// State `current state, an open breaker past its cooldown reports half-open`
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if BreakerOpen == b.state && time.Since(b.openedAt) >= b.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow reserve a request, ErrCircuitOpen when open or while the probe is out
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	if BreakerOpen == b.state && time.Since(b.openedAt) >= b.Cooldown {
+		b.state = BreakerHalfOpen
+	}
+	to := b.state
+	var err error
+	switch {
+	case BreakerOpen == b.state, BreakerHalfOpen == b.state && b.probing:
+		err = ErrCircuitOpen
+	case BreakerHalfOpen == b.state:
+		b.probing = true
+	}
+	b.mu.Unlock()
+
+	b.changed(from, to)
+	return err
+}
+
+// cancel hand back a reservation that never reached the api or whose caller gave up
+func (b *CircuitBreaker) cancel() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// record count the outcome of a request let through by allow
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	from := b.state
+	b.probing = false
+	if IsTransient(err) {
+		b.failures++
+		if BreakerHalfOpen == b.state || b.failures >= b.Threshold {
+			b.state, b.openedAt = BreakerOpen, time.Now()
+		}
+	} else {
+		b.state, b.failures = BreakerClosed, 0
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.changed(from, to)
+}
+
+func (b *CircuitBreaker) changed(from, to BreakerState) {
+	if from != to && nil != b.OnStateChange {
+		b.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	var transitions []string
+	breaker := NewCircuitBreaker(2, 30*time.Millisecond)
+	breaker.OnStateChange = func(from, to BreakerState) {
+		transitions = append(transitions, from.String()+">"+to.String())
+	}
+	webHook := NewWebHook("token", WithCircuitBreaker(breaker))
+	webHook.APIURL = server.URL
+
+	_ = server.ReplyFixture("keywords-not-in-content", "bad-gateway", "bad-gateway")
+	for i := 0; i < 3; i++ {
+		_ = webHook.SendTextMsg("hello", false)
+	}
+	if BreakerOpen != breaker.State() {
+		t.Fatalf("two consecutive transient failures should open the breaker, got %s", breaker.State())
+	}
+	if err := webHook.SendTextMsg("hello", false); ErrCircuitOpen != err || 3 != len(server.Requests()) {
+		t.Errorf("open breaker should fail fast, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	_ = server.ReplyFixture("bad-gateway")
+	_ = webHook.SendTextMsg("hello", false)
+	if BreakerOpen != breaker.State() || 4 != len(server.Requests()) {
+		t.Errorf("failed probe should open the breaker again, got %s", breaker.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := webHook.SendTextMsg("hello", false); nil != err || BreakerClosed != breaker.State() {
+		t.Errorf("successful probe should close the breaker, got %v %s", err, breaker.State())
+	}
+
+	want := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if len(want) != len(transitions) {
+		t.Fatalf("want transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if want[i] != transitions[i] {
+			t.Errorf("want transitions %v, got %v", want, transitions)
+			break
+		}
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	breaker := NewCircuitBreaker(1, 0)
+	breaker.record(&HTTPError{StatusCode: 502})
+
+	if err := breaker.allow(); nil != err {
+		t.Fatalf("probe should be let through, got %v", err)
+	}
+	if err := breaker.allow(); ErrCircuitOpen != err {
+		t.Errorf("only one probe should be out, got %v", err)
+	}
+	breaker.cancel()
+	if err := breaker.allow(); nil != err {
+		t.Errorf("cancelled probe should be handed back, got %v", err)
+	}
+}
+
+func TestCircuitBreakerCanceledProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	breaker := NewCircuitBreaker(1, 0)
+	breaker.record(&HTTPError{StatusCode: 502})
+	webHook := NewWebHook("token", WithCircuitBreaker(breaker))
+	webHook.APIURL = server.URL
+
+	for _, err := range []error{context.Canceled, context.DeadlineExceeded} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if context.Canceled == err {
+			time.AfterFunc(10*time.Millisecond, cancel)
+		}
+		_ = webHook.SendTextMsgContext(ctx, "hello", false)
+		cancel()
+
+		if BreakerClosed == breaker.State() {
+			t.Errorf("probe ended by %v should leave the breaker open", err)
+		}
+		if err := breaker.allow(); nil != err {
+			t.Errorf("probe ended by the caller should be handed back, got %v", err)
+		}
+		breaker.cancel()
+	}
+}
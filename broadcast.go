@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Broadcast `send one message to several robots concurrently`
+type Broadcast struct {
+	//  robots by name, the names identify them in results and errors
+	Targets map[string]*WebHook
+	//  most sends in flight at once, 0 sends to every target at once
+	Concurrency int
+}
+
+// NewBroadcast `broadcast to targets`
+func NewBroadcast(targets map[string]*WebHook) *Broadcast {
+	return &Broadcast{Targets: targets}
+}
+
+// Send `send msg to every target`
+func (b *Broadcast) Send(msg Message) (map[string]*SendResult, error) {
+	return b.SendContext(context.Background(), msg)
+}
+
+// SendContext `send msg to every target, bounded by ctx`
+//
+// The map holds the result of every target, nil for those whose api was not reached. The error
+// is a *MultiError naming the failed targets, nil when all succeeded. Every target gets its own
+// copy of the payload, so per-robot keywords and footers do not leak into the others.
+func (b *Broadcast) SendContext(ctx context.Context, msg Message) (map[string]*SendResult, error) {
+	payload, err := msg.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(b.Targets))
+	for name := range b.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	concurrency := b.Concurrency
+	if concurrency < 1 || concurrency > len(names) {
+		concurrency = len(names)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]*SendResult, len(names))
+	errs := make(map[string]error, len(names))
+	for _, name := range names {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(name string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			result, err := b.Targets[name].SendWithResultContext(ctx, clonePayload(payload))
+
+			mu.Lock()
+			results[name], errs[name] = result, err
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	multi := &MultiError{}
+	for _, name := range names {
+		multi.add(name, errs[name])
+	}
+
+	return results, multi.Err()
+}
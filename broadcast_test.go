@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestBroadcast(t *testing.T) {
+	ok := webhooktest.NewServer()
+	defer ok.Close()
+	down := webhooktest.NewServer()
+	defer down.Close()
+	_ = down.ReplyFixture("token-not-exist")
+
+	robot := func(server *webhooktest.Server, keyword string) *WebHook {
+		webHook := NewWebHook("token")
+		webHook.APIURL = server.URL
+		webHook.Keywords = []string{keyword}
+		return webHook
+	}
+	broadcast := NewBroadcast(map[string]*WebHook{
+		"ops":   robot(ok, "[ops]"),
+		"dev":   robot(ok, "[dev]"),
+		"stale": robot(down, "[stale]"),
+	})
+
+	results, err := broadcast.Send(&TextMessage{Content: "release 1.2.0 is out"})
+	var multi *MultiError
+	if !errors.As(err, &multi) || 3 != multi.Total || "stale" != multi.Failed()[0] || !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("the stale robot should be reported, got %v", err)
+	}
+	if 3 != len(results) || 0 != results["ops"].ErrorCode || 300001 != results["stale"].ErrorCode {
+		t.Errorf("every target should have a result, got %v", results)
+	}
+
+	for _, request := range ok.Requests() {
+		payload, _ := request.Payload()
+		if c := payload.Text.Content; "release 1.2.0 is out\n\n[ops]" != c && "release 1.2.0 is out\n\n[dev]" != c {
+			t.Errorf("keywords of one robot should not leak into another, got %q", c)
+		}
+	}
+}
+
+func TestBroadcastConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	targets := make(map[string]*WebHook)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		webHook := NewWebHook("token")
+		webHook.DryRun = true
+		webHook.Use(func(next Sender) Sender {
+			return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+				n := atomic.AddInt32(&inFlight, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&inFlight, -1)
+				return next.Send(ctx, payload)
+			})
+		})
+		targets[name] = webHook
+	}
+
+	broadcast := &Broadcast{Targets: targets, Concurrency: 2}
+	if _, err := broadcast.Send(&TextMessage{Content: "hello"}); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != atomic.LoadInt32(&peak) {
+		t.Errorf("at most 2 sends should be in flight, got %d", peak)
+	}
+}
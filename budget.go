@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BudgetMonitor `Metrics which alerts an admin robot when a channel fails or throttles too often`
+//
+// Rates are computed over the observations of the last Window, alerts for a channel are repeated
+// at most once per Cooldown.
+type BudgetMonitor struct {
+	Admin *WebHook
+	//  observations are passed on to Next as well, e.g. a *Stats
+	Next         Metrics
+	Window       time.Duration
+	MinSamples   int
+	FailureRate  float64
+	ThrottleRate float64
+	Cooldown     time.Duration
+
+	mu       sync.Mutex
+	samples  map[string][]budgetSample
+	alerted  map[string]time.Time
+	sendFunc func(title, text string) //  replaced in tests
+}
+
+type budgetSample struct {
+	at        time.Time
+	failed    bool
+	throttled bool
+}
+
+// NewBudgetMonitor `alert admin when over 20% of sends fail or 10% are throttled within 5 minutes`
+func NewBudgetMonitor(admin *WebHook) *BudgetMonitor {
+	return &BudgetMonitor{
+		Admin:        admin,
+		Window:       5 * time.Minute,
+		MinSamples:   10,
+		FailureRate:  0.2,
+		ThrottleRate: 0.1,
+		Cooldown:     30 * time.Minute,
+	}
+}
+
+// ObserveSend `implement Metrics`
+func (m *BudgetMonitor) ObserveSend(channel, msgType string, latency time.Duration, err error) {
+	if nil != m.Next {
+		m.Next.ObserveSend(channel, msgType, latency, err)
+	}
+	m.observe(channel, err)
+}
+
+// ObserveSenderSend `implement SenderMetrics, the sender is passed on to Next`
+func (m *BudgetMonitor) ObserveSenderSend(sender SenderIdentity, channel, msgType string, latency time.Duration, err error) {
+	if nil != m.Next {
+		observeSend(m.Next, sender, channel, msgType, latency, err)
+	}
+	m.observe(channel, err)
+}
+
+// observe record a sample and alert when the channel runs out of budget
+func (m *BudgetMonitor) observe(channel string, err error) {
+	if ErrSilenced == err || ErrDropped == err || (nil != m.Admin && channel == m.Admin.channel()) {
+		return
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	if nil == m.samples {
+		m.samples = make(map[string][]budgetSample)
+		m.alerted = make(map[string]time.Time)
+	}
+
+	samples := append(m.samples[channel], budgetSample{at: now, failed: nil != err, throttled: isThrottled(err)})
+	for 0 < len(samples) && now.Sub(samples[0].at) > m.Window {
+		samples = samples[1:]
+	}
+	m.samples[channel] = samples
+
+	var failed, throttled int
+	for _, sample := range samples {
+		if sample.failed {
+			failed++
+		}
+		if sample.throttled {
+			throttled++
+		}
+	}
+
+	total := float64(len(samples))
+	failureRate, throttleRate := float64(failed)/total, float64(throttled)/total
+	breached := len(samples) >= m.MinSamples &&
+		((0 < m.FailureRate && failureRate >= m.FailureRate) || (0 < m.ThrottleRate && throttleRate >= m.ThrottleRate))
+	if !breached || now.Sub(m.alerted[channel]) < m.Cooldown {
+		m.mu.Unlock()
+		return
+	}
+	m.alerted[channel] = now
+	m.mu.Unlock()
+
+	title := fmt.Sprintf("Notification budget exceeded: %s", channel)
+	text := fmt.Sprintf("#### %s\n\n- failure rate: %.0f%% (%d/%d)\n- throttle rate: %.0f%% (%d/%d)\n- window: %s\n- last error: %v",
+		title, failureRate*100, failed, len(samples), throttleRate*100, throttled, len(samples), m.Window, err)
+	m.alert(title, text)
+}
+
+// alert deliver the meta-alert without blocking the observed send
+func (m *BudgetMonitor) alert(title, text string) {
+	if nil != m.sendFunc {
+		m.sendFunc(title, text)
+		return
+	}
+	if nil != m.Admin {
+		go func() {
+			_ = m.Admin.SendMarkdownMsg(title, text, false)
+		}()
+	}
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudgetMonitor(t *testing.T) {
+	admin := NewWebHook("admin")
+	admin.Name = "admin"
+	monitor := NewBudgetMonitor(admin)
+	monitor.MinSamples = 4
+	stats := NewStats()
+	monitor.Next = stats
+
+	var alerts []string
+	monitor.sendFunc = func(title, text string) {
+		alerts = append(alerts, text)
+	}
+
+	throttled := &APIError{Code: 130101, Message: "send too fast"}
+	monitor.ObserveSend("ops", "text", time.Millisecond, nil)
+	monitor.ObserveSend("ops", "text", time.Millisecond, nil)
+	monitor.ObserveSend("ops", "text", time.Millisecond, nil)
+	if 0 != len(alerts) {
+		t.Fatal("healthy channel should not alert")
+	}
+
+	monitor.ObserveSend("ops", "text", time.Millisecond, throttled)
+	if 1 != len(alerts) || !strings.Contains(alerts[0], "throttle rate: 25%") {
+		t.Fatalf("throttle alert expected, got %v", alerts)
+	}
+
+	monitor.ObserveSend("ops", "text", time.Millisecond, errors.New("boom"))
+	if 1 != len(alerts) {
+		t.Error("alerts should respect the cooldown")
+	}
+
+	for i := 0; i < 10; i++ {
+		monitor.ObserveSend("admin", "text", time.Millisecond, errors.New("boom"))
+	}
+	if 1 != len(alerts) {
+		t.Error("admin channel failures must not alert itself")
+	}
+
+	if 5 != stats.Snapshot()["ops"].Sent+stats.Snapshot()["ops"].Failed {
+		t.Error("observations should be forwarded to Next")
+	}
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import "github.com/lddsb/dingtalk-webhook/message"
+
+// LineBreak `forced line break, DingTalk joins lines separated by a single newline`
+const LineBreak = message.LineBreak
+
+// MarkdownBuilder `build DingTalk markdown block by block, see package message`
+type MarkdownBuilder = message.MarkdownBuilder
+
+// KVList `key: value lines, the shape DingTalk renders best for structured fields`
+type KVList = message.KVList
+
+// ActionCardBuilder `build an action card with a single jump or a list of buttons`
+type ActionCardBuilder = message.ActionCardBuilder
+
+// FeedCardBuilder `build a feed card, validated against the feedCard schema`
+type FeedCardBuilder = message.FeedCardBuilder
+
+// NewMarkdownBuilder `empty builder`
+func NewMarkdownBuilder() *MarkdownBuilder {
+	return message.NewMarkdownBuilder()
+}
+
+// NewKVList `empty list`
+func NewKVList() *KVList {
+	return message.NewKVList()
+}
+
+// NewActionCardBuilder `builder for a card titled title`
+func NewActionCardBuilder(title string) *ActionCardBuilder {
+	return message.NewActionCardBuilder(title)
+}
+
+// NewFeedCardBuilder `empty builder`
+func NewFeedCardBuilder() *FeedCardBuilder {
+	return message.NewFeedCardBuilder()
+}
+
+// Table `render rows DingTalk-style, as one list item per row`
+func Table(headers []string, rows [][]string) string {
+	return message.Table(headers, rows)
+}
+
+// CodeBlock `render code as a quote keeping its lines and indentation`
+func CodeBlock(lang, code string) string {
+	return message.CodeBlock(lang, code)
+}
+
+// Image `standalone image`
+func Image(url string) string {
+	return message.Image(url)
+}
+
+// TableOfContents `summary of the sections of a long markdown report`
+func TableOfContents(markdown string) string {
+	return message.TableOfContents(markdown)
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import "time"
+
+// DateLayout `layout of the dates given to HolidayCalendar`
+const DateLayout = "2006-01-02"
+
+// Calendar `decides on which days scheduled messages go out, e.g. backed by a team's holiday feed`
+type Calendar interface {
+	IsWorkday(day time.Time) bool
+}
+
+// CalendarFunc `adapt a plain function to Calendar`
+type CalendarFunc func(day time.Time) bool
+
+// IsWorkday `implement Calendar`
+func (f CalendarFunc) IsWorkday(day time.Time) bool {
+	return f(day)
+}
+
+// Weekdays `Monday to Friday`
+var Weekdays Calendar = CalendarFunc(func(day time.Time) bool {
+	return time.Saturday != day.Weekday() && time.Sunday != day.Weekday()
+})
+
+// HolidayCalendar `Base minus holidays, plus make-up workdays such as the weekends swapped around Chinese holidays`
+type HolidayCalendar struct {
+	//  nil treats every day as a workday
+	Base     Calendar
+	Holidays map[string]bool
+	Workdays map[string]bool
+}
+
+// NewHolidayCalendar `skip the given DateLayout dates on top of base`
+func NewHolidayCalendar(base Calendar, holidays ...string) *HolidayCalendar {
+	c := &HolidayCalendar{Base: base, Holidays: make(map[string]bool), Workdays: make(map[string]bool)}
+	for _, day := range holidays {
+		c.Holidays[day] = true
+	}
+
+	return c
+}
+
+// AddWorkdays `mark DateLayout dates as workdays even if Base says otherwise`
+func (c *HolidayCalendar) AddWorkdays(days ...string) *HolidayCalendar {
+	for _, day := range days {
+		c.Workdays[day] = true
+	}
+
+	return c
+}
+
+// IsWorkday `implement Calendar, dates are compared in the location of day`
+func (c *HolidayCalendar) IsWorkday(day time.Time) bool {
+	date := day.Format(DateLayout)
+	switch {
+	case c.Workdays[date]:
+		return true
+	case c.Holidays[date]:
+		return false
+	case nil == c.Base:
+		return true
+	}
+
+	return c.Base.IsWorkday(day)
+}
+
+// DailySchedule `a wall clock time in a location, on the workdays of a calendar`
+type DailySchedule struct {
+	Hour   int
+	Minute int
+	//  nil means time.Local
+	Location *time.Location
+	//  nil runs every day
+	Calendar Calendar
+}
+
+// calendarHorizon days searched for a workday before a schedule is considered empty
+const calendarHorizon = 2 * 366
+
+// Next `first scheduled time after after, zero when the calendar has no workday within two years`
+//
+// The wall clock time is kept across daylight saving changes, so a 09:00 digest stays at 09:00.
+func (s DailySchedule) Next(after time.Time) time.Time {
+	location := s.Location
+	if nil == location {
+		location = time.Local
+	}
+
+	local := after.In(location)
+	for i := 0; i <= calendarHorizon; i++ {
+		next := time.Date(local.Year(), local.Month(), local.Day()+i, s.Hour, s.Minute, 0, 0, location)
+		if !next.After(after) {
+			continue
+		}
+		if nil == s.Calendar || s.Calendar.IsWorkday(next) {
+			return next
+		}
+	}
+
+	return time.Time{}
+}
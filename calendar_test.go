@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHolidayCalendar(t *testing.T) {
+	calendar := NewHolidayCalendar(Weekdays, "2024-10-01", "2024-10-02").AddWorkdays("2024-10-12")
+	cases := map[string]bool{
+		"2024-09-30": true,  //  Monday
+		"2024-10-01": false, //  National Day
+		"2024-10-05": false, //  Saturday
+		"2024-10-12": true,  //  make-up Saturday
+	}
+	for date, want := range cases {
+		day, _ := time.Parse(DateLayout, date)
+		if want != calendar.IsWorkday(day) {
+			t.Errorf("%s workday should be %v", date, want)
+		}
+	}
+}
+
+func TestDailyScheduleNext(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	schedule := DailySchedule{Hour: 9, Location: shanghai, Calendar: NewHolidayCalendar(Weekdays, "2024-10-07")}
+
+	//  Friday 10:00 in Shanghai, past today's run
+	after := time.Date(2024, 10, 4, 2, 0, 0, 0, time.UTC)
+	want := time.Date(2024, 10, 8, 9, 0, 0, 0, shanghai)
+	if next := schedule.Next(after); !want.Equal(next) {
+		t.Errorf("weekend and holiday should be skipped, want %s, got %s", want, next)
+	}
+
+	//  Friday 08:00 in Shanghai, before today's run
+	after = time.Date(2024, 10, 4, 0, 0, 0, 0, time.UTC)
+	if next := schedule.Next(after); 4 != next.Day() || 9 != next.Hour() {
+		t.Errorf("today's run should be next, got %s", next)
+	}
+
+	never := DailySchedule{Calendar: CalendarFunc(func(time.Time) bool { return false })}
+	if !never.Next(after).IsZero() {
+		t.Error("schedule without workdays should return the zero time")
+	}
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DefaultCanaryInterval `how often CanaryLoop.Run checks when Interval is not set`
+const DefaultCanaryInterval = 5 * time.Minute
+
+// DefaultCanaryTimeout `how long a canary may take to arrive when Timeout is not set`
+const DefaultCanaryTimeout = 30 * time.Second
+
+// CanaryReceiver `sees messages arriving in the group, e.g. a stream mode or outgoing robot`
+type CanaryReceiver interface {
+	//  Expect registers interest in a message containing token, arrived is closed when it shows up
+	Expect(token string) (arrived <-chan struct{}, cancel func())
+}
+
+// CanaryLoop `end-to-end check that messages sent by Sender actually reach the group`
+type CanaryLoop struct {
+	Sender   *WebHook
+	Receiver CanaryReceiver
+	//  how long a canary may take to arrive, 0 uses DefaultCanaryTimeout
+	Timeout time.Duration
+	//  pause between checks of Run, 0 uses DefaultCanaryInterval
+	Interval time.Duration
+	//  notified through another path when a canary is lost, may be nil
+	Alert     *WebHook
+	OnFailure func(err error)
+}
+
+// Check `send one canary and wait for it`
+func (c *CanaryLoop) Check(ctx context.Context) error {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); nil != err {
+		return err
+	}
+	token := "canary-" + hex.EncodeToString(buf)
+
+	arrived, cancel := c.Receiver.Expect(token)
+	defer cancel()
+
+	start := time.Now()
+	if err := c.Sender.SendTextMsgContext(ctx, "[canary] "+token, false); nil != err {
+		return fmt.Errorf("canary %s not sent: %v", token, err)
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCanaryTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-arrived:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("canary %s sent to %s did not arrive within %s", token, c.Sender.channel(), time.Since(start).Round(time.Millisecond))
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run `check every Interval until ctx is done, reporting lost canaries`
+func (c *CanaryLoop) Run(ctx context.Context) {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultCanaryInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Check(ctx); nil != err && nil == ctx.Err() {
+			c.fail(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fail report a lost canary through every configured path
+func (c *CanaryLoop) fail(err error) {
+	if nil != c.OnFailure {
+		c.OnFailure(err)
+	}
+	if nil != c.Alert {
+		_ = c.Alert.SendTextMsg("[delivery check failed] "+err.Error(), false)
+	}
+}
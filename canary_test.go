@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGroup delivers every text message it receives to registered expectations
+type fakeGroup struct {
+	mu      sync.Mutex
+	waiting map[string]chan struct{}
+	deliver bool
+}
+
+func (g *fakeGroup) Expect(token string) (<-chan struct{}, func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ch := make(chan struct{})
+	g.waiting[token] = ch
+	return ch, func() {
+		g.mu.Lock()
+		delete(g.waiting, token)
+		g.mu.Unlock()
+	}
+}
+
+func (g *fakeGroup) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	var payload PayLoad
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+	g.mu.Lock()
+	for token, ch := range g.waiting {
+		if g.deliver && strings.Contains(payload.Text.Content, token) {
+			close(ch)
+			delete(g.waiting, token)
+		}
+	}
+	g.mu.Unlock()
+	_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+}
+
+func TestCanaryLoop(t *testing.T) {
+	group := &fakeGroup{waiting: make(map[string]chan struct{}), deliver: true}
+	server := httptest.NewServer(group)
+	defer server.Close()
+
+	sender := NewWebHook("token")
+	sender.APIURL = server.URL
+	var failures []error
+	loop := &CanaryLoop{Sender: sender, Receiver: group, Timeout: 50 * time.Millisecond, OnFailure: func(err error) {
+		failures = append(failures, err)
+	}}
+
+	if err := loop.Check(context.Background()); nil != err {
+		t.Fatalf("delivered canary should pass, got %v", err)
+	}
+
+	group.mu.Lock()
+	group.deliver = false
+	group.mu.Unlock()
+	if err := loop.Check(context.Background()); nil == err || !strings.Contains(err.Error(), "did not arrive") {
+		t.Errorf("lost canary should be reported, got %v", err)
+	}
+
+	loop.Interval = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	loop.Run(ctx)
+	if 1 != len(failures) {
+		t.Errorf("run should report the lost canary once, got %v", failures)
+	}
+}
+
+func TestCanaryLoopDefaults(t *testing.T) {
+	group := &fakeGroup{waiting: make(map[string]chan struct{}), deliver: true}
+	server := httptest.NewServer(group)
+	defer server.Close()
+
+	sender := NewWebHook("token")
+	sender.APIURL = server.URL
+	loop := &CanaryLoop{Sender: sender, Receiver: group}
+
+	if err := loop.Check(context.Background()); nil != err {
+		t.Errorf("zero timeout should wait DefaultCanaryTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	//  a zero interval used to panic in time.NewTicker
+	loop.Run(ctx)
+}
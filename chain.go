@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+)
+
+// Chain `ordered messages sent as one announcement`
+//
+// Every message is built before the first one is sent, so a broken part fails the chain
+// without posting anything. Sending stops at the first failure and ChainError tells how
+// many parts made it.
+type Chain struct {
+	webHook  *WebHook
+	messages []Message
+}
+
+// ChainError `a chain that was only partially delivered`
+type ChainError struct {
+	Delivered int
+	Total     int
+	Err       error
+}
+
+// Error `implement error interface`
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("chain stopped after %d of %d messages: %v", e.Delivered, e.Total, e.Err)
+}
+
+// Unwrap `expose the error of the failed part`
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
+// Chain `start a chain sent through w`
+func (w *WebHook) Chain(messages ...Message) *Chain {
+	return &Chain{webHook: w, messages: messages}
+}
+
+// Then `append a message`
+func (c *Chain) Then(msg Message) *Chain {
+	c.messages = append(c.messages, msg)
+	return c
+}
+
+// Send `send the chain`
+func (c *Chain) Send() error {
+	return c.SendContext(context.Background())
+}
+
+// SendContext `send the chain, bounded by ctx`
+func (c *Chain) SendContext(ctx context.Context) error {
+	payloads := make([]*PayLoad, len(c.messages))
+	for i, msg := range c.messages {
+		payload, err := msg.Payload()
+		if nil != err {
+			return &ChainError{Total: len(c.messages), Err: fmt.Errorf("message %d: %w", i+1, err)}
+		}
+		payloads[i] = payload
+	}
+
+	for i, payload := range payloads {
+		if err := c.webHook.sendPayloadContext(ctx, payload); nil != err {
+			return &ChainError{Delivered: i, Total: len(payloads), Err: err}
+		}
+	}
+
+	return nil
+}
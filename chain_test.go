@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestChain(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	header, _ := NewActionCardBuilder("Release v1.2.3").Text("billing is rolling out").Single("Open", "https://ci/1").Build()
+	links, _ := NewFeedCardBuilder().Link("changelog", "https://ci/changelog", "").Build()
+	err := webHook.Chain(header).
+		Then(&MarkdownMessage{Title: "details", Text: "#### details"}).
+		Then(links).
+		Send()
+	if nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if 3 != len(requests) {
+		t.Fatalf("3 messages expected, got %d", len(requests))
+	}
+	for i, msgType := range []string{"actionCard", "markdown", "feedCard"} {
+		if payload, _ := requests[i].Payload(); msgType != payload.MsgType {
+			t.Errorf("message %d should be %s, got %s", i, msgType, payload.MsgType)
+		}
+	}
+
+	var chainErr *ChainError
+	err = webHook.Chain(header, &ActionCardMessage{Title: "broken"}).Send()
+	if !errors.As(err, &chainErr) || 0 != chainErr.Delivered || 3 != len(server.Requests()) {
+		t.Errorf("invalid part should stop the chain before sending, got %v", err)
+	}
+
+	_ = server.ReplyFixture("ok", "send-too-fast")
+	err = webHook.Chain(header, header, header).Send()
+	if !errors.As(err, &chainErr) || 1 != chainErr.Delivered || 3 != chainErr.Total || !errors.Is(err, ErrTooFast) {
+		t.Errorf("partial delivery should be reported, got %v", err)
+	}
+	if 5 != len(server.Requests()) {
+		t.Errorf("chain should stop at the first failure, got %d requests", len(server.Requests()))
+	}
+}
+
+func TestChainBuildErrorWrapped(t *testing.T) {
+	webHook := NewWebHook("token")
+	err := webHook.Chain(&TextMessage{Content: "first"}, &LinkMessage{Title: "t", At: []AtOption{AtAll()}}).Send()
+
+	var validation *ValidationError
+	if !errors.As(err, &validation) || "link" != validation.MsgType {
+		t.Errorf("build error should stay matchable through the chain, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidClick `tracking parameters missing or not signed by the ClickTracker`
+var ErrInvalidClick = errors.New("invalid click tracking parameters")
+
+// ClickInfo `what a tracked link points to and where it was sent`
+type ClickInfo struct {
+	Target  string
+	Channel string
+	//  IdempotencyTag of the message, ties clicks to history entries
+	Message string
+	//  button or link title
+	Label string
+}
+
+// ClickTracker `sends button and link urls through a redirect so clicks can be counted`
+//
+// Without Secret anyone can craft a redirect through BaseURL, set it whenever the redirect
+// service is reachable from the internet.
+type ClickTracker struct {
+	//  redirect endpoint, e.g. https://t.example.com/click, see ClickHandler
+	BaseURL string
+	Secret  string
+}
+
+// Wrap `tracking url for info`
+func (t *ClickTracker) Wrap(info ClickInfo) string {
+	query := clickQuery(info)
+	if "" != t.Secret {
+		query.Set("s", t.sign(query))
+	}
+
+	separator := "?"
+	if strings.Contains(t.BaseURL, "?") {
+		separator = "&"
+	}
+	return t.BaseURL + separator + query.Encode()
+}
+
+// Decode `read and verify the parameters of a tracking url`
+func (t *ClickTracker) Decode(query url.Values) (ClickInfo, error) {
+	info := ClickInfo{Target: query.Get("u"), Channel: query.Get("c"), Message: query.Get("m"), Label: query.Get("l")}
+	if !isURL(info.Target) {
+		return ClickInfo{}, ErrInvalidClick
+	}
+	if "" != t.Secret && !hmac.Equal([]byte(query.Get("s")), []byte(t.sign(clickQuery(info)))) {
+		return ClickInfo{}, ErrInvalidClick
+	}
+
+	return info, nil
+}
+
+// ClickHandler `redirect tracking urls to their target, reporting each click to onClick`
+func (t *ClickTracker) ClickHandler(onClick func(ClickInfo)) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		info, err := t.Decode(r.URL.Query())
+		if nil != err {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if nil != onClick {
+			onClick(info)
+		}
+		http.Redirect(rw, r, info.Target, http.StatusFound)
+	})
+}
+
+// sign hmac of the tracking parameters
+func (t *ClickTracker) sign(query url.Values) string {
+	mac := hmac.New(sha256.New, []byte(t.Secret))
+	mac.Write([]byte(query.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// clickQuery tracking parameters without signature
+func clickQuery(info ClickInfo) url.Values {
+	query := url.Values{"u": {info.Target}}
+	for key, value := range map[string]string{"c": info.Channel, "m": info.Message, "l": info.Label} {
+		if "" != value {
+			query.Set(key, value)
+		}
+	}
+
+	return query
+}
+
+// trackClicks wrap the http(s) urls of buttons and links, dingtalk:// urls must stay as they are
+func (w *WebHook) trackClicks(payload *PayLoad, tag string) {
+	if nil == w.ClickTracker {
+		return
+	}
+
+	wrap := func(target, label string) string {
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			return target
+		}
+		return w.ClickTracker.Wrap(ClickInfo{Target: target, Channel: w.channel(), Message: tag, Label: label})
+	}
+	if nil != payload.Link {
+		payload.Link.MessageURL = wrap(payload.Link.MessageURL, payload.Link.Title)
+	}
+	if nil != payload.ActionCard {
+		payload.ActionCard.SingleURL = wrap(payload.ActionCard.SingleURL, payload.ActionCard.SingleTitle)
+		for i, button := range payload.ActionCard.Buttons {
+			payload.ActionCard.Buttons[i].ActionURL = wrap(button.ActionURL, button.Title)
+		}
+	}
+	if nil != payload.FeedCard {
+		for i, link := range payload.FeedCard.Links {
+			payload.FeedCard.Links[i].MessageURL = wrap(link.MessageURL, link.Title)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestClickTracker(t *testing.T) {
+	tracker := &ClickTracker{BaseURL: "https://t.example.com/click", Secret: "secret"}
+	info := ClickInfo{Target: "https://example.com/runbook?id=1", Channel: "ops", Message: "abc", Label: "Runbook"}
+
+	wrapped, err := url.Parse(tracker.Wrap(info))
+	if nil != err || "t.example.com" != wrapped.Host {
+		t.Fatalf("url should point at the redirect, got %v %v", wrapped, err)
+	}
+	decoded, err := tracker.Decode(wrapped.Query())
+	if nil != err || info != decoded {
+		t.Errorf("decode should round trip, got %+v %v", decoded, err)
+	}
+
+	query := wrapped.Query()
+	query.Set("u", "https://evil.example.com")
+	if _, err := tracker.Decode(query); ErrInvalidClick != err {
+		t.Errorf("tampered target should be rejected, got %v", err)
+	}
+
+	var clicks []ClickInfo
+	recorder := httptest.NewRecorder()
+	tracker.ClickHandler(func(info ClickInfo) {
+		clicks = append(clicks, info)
+	}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, wrapped.String(), nil))
+	if http.StatusFound != recorder.Code || info.Target != recorder.Header().Get("Location") || 1 != len(clicks) {
+		t.Errorf("click should be counted and redirected, got %d %s", recorder.Code, recorder.Header().Get("Location"))
+	}
+}
+
+func TestTrackClicks(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.ClickTracker = &ClickTracker{BaseURL: "https://t.example.com/click"}
+
+	err := webHook.Send(&ActionCardMessage{Title: "t", Text: "x", Buttons: []Button{
+		{Title: "Open", ActionURL: "https://example.com/a"},
+		{Title: "Chat", ActionURL: "dingtalk://dingtalkclient/page/link?url=x"},
+	}})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	var payload PayLoad
+	_ = json.Unmarshal(server.Requests()[0].Body, &payload)
+	buttons := payload.ActionCard.Buttons
+	if !strings.HasPrefix(buttons[0].ActionURL, "https://t.example.com/click?") || !strings.Contains(buttons[0].ActionURL, "l=Open") {
+		t.Errorf("http url should be tracked, got %s", buttons[0].ActionURL)
+	}
+	if "dingtalk://dingtalkclient/page/link?url=x" != buttons[1].ActionURL {
+		t.Errorf("dingtalk url should be left alone, got %s", buttons[1].ActionURL)
+	}
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	webHook := NewWebHook("token", WithHTTPClient(&http.Client{Transport: transport}))
+	webHook.APIURL = server.URL
+
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != transport.requests {
+		t.Errorf("custom client should be used, got %d requests", transport.requests)
+	}
+
+	webHook.Client = nil
+	if DefaultClient != webHook.httpClient() || 0 == DefaultClient.Timeout {
+		t.Error("default client with a timeout should be used as fallback")
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	if nil == DefaultTransport.Proxy {
+		t.Error("default transport should honour the proxy environment")
+	}
+
+	webHook := NewWebHook("token", WithoutProxy())
+	transport, ok := webHook.httpClient().Transport.(*http.Transport)
+	if !ok || nil != transport.Proxy || 0 == webHook.httpClient().Timeout {
+		t.Error("direct client with a timeout should be used without proxy")
+	}
+
+	client := &http.Client{}
+	webHook.Client = client
+	if client != webHook.httpClient() {
+		t.Error("custom client should win over DisableProxy")
+	}
+}
+
+func TestExplicitProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := ParseProxy(proxy.URL)
+	if nil != err {
+		t.Fatal(err)
+	}
+	webHook := NewWebHook("token", WithProxy(proxyURL))
+	webHook.APIURL = "http://dingtalk.invalid/robot/send"
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(proxied) || "http://dingtalk.invalid/robot/send?access_token=token" != proxied[0] {
+		t.Errorf("request should go through the proxy, got %v", proxied)
+	}
+	if webHook.httpClient() != NewWebHook("other", WithProxy(proxyURL)).httpClient() {
+		t.Error("robots using the same proxy should share a client")
+	}
+
+	socks, err := ParseProxy("socks5://127.0.0.1:1080")
+	if nil != err {
+		t.Fatal(err)
+	}
+	transport := NewWebHook("token", WithProxy(socks)).httpClient().Transport.(*http.Transport)
+	if u, _ := transport.Proxy(httptest.NewRequest(http.MethodPost, "https://oapi.dingtalk.com", nil)); nil == u || socks.String() != u.String() {
+		t.Errorf("socks5 proxy should be used, got %v", u)
+	}
+
+	for _, raw := range []string{"ftp://proxy:21", "http://", "::"} {
+		if _, err := ParseProxy(raw); nil == err {
+			t.Errorf("invalid proxy %q error should be catch!", raw)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompactLength `longest compact form, about what a phone shows in a push notification`
+const CompactLength = 60
+
+var (
+	compactImage  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	compactLink   = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	compactTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	compactMarker = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	compactSpace  = regexp.MustCompile(`\s+`)
+)
+
+// Compact `one line plain text summary of markdown, cut to limit characters`
+func Compact(markdown string, limit int) string {
+	text := compactImage.ReplaceAllString(markdown, "[$1]")
+	text = compactLink.ReplaceAllString(text, "$1")
+	text = compactTag.ReplaceAllString(text, "")
+	text = compactMarker.ReplaceAllString(text, "")
+	text = strings.NewReplacer("**", "", "__", "", "`", "").Replace(text)
+	text = strings.TrimSpace(compactSpace.ReplaceAllString(text, " "))
+
+	if limit > 0 && utf8.RuneCountInString(text) > limit {
+		runes := []rune(text)
+		text = strings.TrimSpace(string(runes[:limit-1])) + "…"
+	}
+	return text
+}
+
+// RenderedMessage `a message with a compact form for phones and the full one for the chat window`
+//
+// DingTalk shows the title of markdown and actionCard messages in push notifications and the
+// conversation list, the compact form goes there while Full is what opens in the chat.
+type RenderedMessage struct {
+	//  empty derives it from the body of Full with Compact
+	Compact string
+	Full    Message
+}
+
+// Payload `implement Message`
+func (m *RenderedMessage) Payload() (*PayLoad, error) {
+	payload, err := m.Full.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	switch {
+	case nil != payload.Markdown:
+		payload.Markdown.Title = m.compact(payload.Markdown.Text, payload.Markdown.Title)
+	case nil != payload.ActionCard:
+		payload.ActionCard.Title = m.compact(payload.ActionCard.Text, payload.ActionCard.Title)
+	}
+
+	return payload, nil
+}
+
+// compact the compact form, falling back to the body and then the original title
+func (m *RenderedMessage) compact(body, title string) string {
+	if "" != m.Compact {
+		return Compact(m.Compact, CompactLength)
+	}
+	if compact := Compact(body, CompactLength); "" != compact {
+		return compact
+	}
+
+	return title
+}
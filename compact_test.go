@@ -0,0 +1,32 @@
+package webhook
+
+import "testing"
+
+func TestCompact(t *testing.T) {
+	markdown := "#### Deploy **api**\n\n- status: <font color=#ff0000>failed</font>\n- [logs](https://example.com)\n\n![chart](https://example.com/c.png)"
+	if got := Compact(markdown, 0); "Deploy api status: failed logs [chart]" != got {
+		t.Errorf("unexpected compact form %q", got)
+	}
+	if got := Compact("磁盘使用率超过阈值，请尽快处理", 8); "磁盘使用率超过…" != got {
+		t.Errorf("compact form should be cut to the limit, got %q", got)
+	}
+}
+
+func TestRenderedMessage(t *testing.T) {
+	msg := &RenderedMessage{Full: &MarkdownMessage{Title: "deploy", Text: "### Deploy failed\n\n> api v1.2"}}
+	payload, err := msg.Payload()
+	if nil != err || "Deploy failed api v1.2" != payload.Markdown.Title || "### Deploy failed\n\n> api v1.2" != payload.Markdown.Text {
+		t.Errorf("title should be the compact body, got %+v %v", payload.Markdown, err)
+	}
+
+	msg = &RenderedMessage{Compact: "P1: api down", Full: &ActionCardMessage{Title: "t", Text: "long", SingleTitle: "open", SingleURL: "https://example.com"}}
+	payload, _ = msg.Payload()
+	if "P1: api down" != payload.ActionCard.Title {
+		t.Errorf("explicit compact form should be the title, got %q", payload.ActionCard.Title)
+	}
+
+	msg = &RenderedMessage{Compact: "ignored", Full: &TextMessage{Content: "hello"}}
+	if payload, _ = msg.Payload(); "hello" != payload.Text.Content {
+		t.Error("text messages should be sent as is")
+	}
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DeprecationWarning `a payload field DingTalk no longer honours`
+type DeprecationWarning struct {
+	MsgType string
+	Field   string
+	Hint    string
+}
+
+// String `human readable warning`
+func (d DeprecationWarning) String() string {
+	return fmt.Sprintf("%s.%s is deprecated by DingTalk: %s", d.MsgType, d.Field, d.Hint)
+}
+
+// DriftError `known-good payloads rejected by the api`
+type DriftError struct {
+	Rejected map[string]Response
+}
+
+// Error `implement error interface`
+func (e *DriftError) Error() string {
+	var parts []string
+	for msgType, result := range e.Rejected {
+		parts = append(parts, fmt.Sprintf("%s: {code: %d, msg: %s}", msgType, result.ErrorCode, result.ErrorMessage))
+	}
+
+	return "api drift detected, known-good payloads rejected: " + strings.Join(parts, "; ")
+}
+
+// errcodes which reject the robot itself rather than the payload shape
+var nonDriftCodes = map[int]bool{
+	130101: true, //  send too fast
+	300001: true, //  token is not exist
+	310000: true, //  sign, keyword or ip whitelist mismatch
+}
+
+// applyCompat `map deprecated fields to their current form and report what was changed`
+func applyCompat(payload *PayLoad) []DeprecationWarning {
+	var warnings []DeprecationWarning
+	//  hideAvatar is ignored by DingTalk and rejected by some gateways
+	if nil != payload.ActionCard && "" != payload.ActionCard.HideAvatar {
+		if "1" == payload.ActionCard.HideAvatar {
+			warnings = append(warnings, DeprecationWarning{
+				MsgType: "actionCard",
+				Field:   "hideAvatar",
+				Hint:    "the robot avatar can no longer be hidden, field dropped",
+			})
+		}
+		payload.ActionCard.HideAvatar = ""
+	}
+
+	return warnings
+}
+
+// knownGoodPayloads `minimal payloads the api is known to accept, keyed by msgtype`
+func knownGoodPayloads() map[string]*PayLoad {
+	return map[string]*PayLoad{
+		"text": {
+			MsgType: "text",
+			Text:    &Text{Content: "[self-test] text"},
+		},
+		"link": {
+			MsgType: "link",
+			Link:    &Link{Title: "[self-test] link", Text: "link", MessageURL: "https://www.dingtalk.com"},
+		},
+		"markdown": {
+			MsgType:  "markdown",
+			Markdown: &Markdown{Title: "[self-test] markdown", Text: "#### markdown"},
+		},
+		"actionCard": {
+			MsgType: "actionCard",
+			ActionCard: &ActionCard{
+				Title:       "[self-test] actionCard",
+				Text:        "actionCard",
+				SingleTitle: "open",
+				SingleURL:   "https://www.dingtalk.com",
+			},
+		},
+		"feedCard": {
+			MsgType:  "feedCard",
+			FeedCard: &FeedCard{Links: []LinkMsg{{Title: "[self-test] feedCard", MessageURL: "https://www.dingtalk.com"}}},
+		},
+	}
+}
+
+// CheckAPIDrift `send known-good payloads and report the ones the api rejects`
+//
+// every msgtype checked posts one "[self-test]" message to the group, only text is checked when msgTypes is empty.
+// Token, sign and rate limit errors are returned as is because they say nothing about the payload shape.
+func (w *WebHook) CheckAPIDrift(msgTypes ...string) error {
+	if 0 == len(msgTypes) {
+		msgTypes = []string{"text"}
+	}
+
+	payloads := knownGoodPayloads()
+	drift := &DriftError{Rejected: make(map[string]Response)}
+	for _, msgType := range msgTypes {
+		payload, ok := payloads[msgType]
+		if !ok {
+			return fmt.Errorf("unknown msgtype: %s", msgType)
+		}
+
+		reply, err := w.postPayload(context.Background(), payload)
+		if nil != err {
+			return err
+		}
+
+		if 0 == reply.ErrorCode {
+			continue
+		}
+
+		if nonDriftCodes[reply.ErrorCode] {
+			return &APIError{Code: reply.ErrorCode, Message: reply.ErrorMessage}
+		}
+
+		drift.Rejected[msgType] = reply.Response
+	}
+
+	if 0 != len(drift.Rejected) {
+		return drift
+	}
+
+	return nil
+}
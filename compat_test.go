@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApplyCompat(t *testing.T) {
+	payload := &PayLoad{MsgType: "actionCard", ActionCard: &ActionCard{HideAvatar: "1"}}
+
+	warnings := applyCompat(payload)
+	if 1 != len(warnings) || "hideAvatar" != warnings[0].Field {
+		t.Fatalf("hideAvatar warning should be emitted, got %v", warnings)
+	}
+
+	bs, _ := json.Marshal(payload)
+	if strings.Contains(string(bs), "hideAvatar") {
+		t.Error("hideAvatar should not be serialized anymore")
+	}
+
+	payload.ActionCard.HideAvatar = "0"
+	if 0 != len(applyCompat(payload)) {
+		t.Error("hideAvatar=0 is the api default and should be dropped silently")
+	}
+}
+
+func TestCheckAPIDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), `"msgtype":"markdown"`) {
+			_, _ = rw.Write([]byte(`{"errcode":40035,"errmsg":"invalid param"}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	if err := webHook.CheckAPIDrift(); nil != err {
+		t.Errorf("text payload should pass, got %v", err)
+	}
+
+	err := webHook.CheckAPIDrift("text", "markdown")
+	drift, ok := err.(*DriftError)
+	if !ok {
+		t.Fatalf("drift error should be returned, got %v", err)
+	}
+	if _, ok := drift.Rejected["markdown"]; !ok || 1 != len(drift.Rejected) {
+		t.Errorf("only markdown should be rejected, got %v", drift.Rejected)
+	}
+
+	if err := webHook.CheckAPIDrift("unknown"); nil == err {
+		t.Error("unknown msgtype error should be catch!")
+	}
+}
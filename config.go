@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrYAMLUnmarshal `LoadConfig was given a YAML file but no unmarshal func able to read it`
+var ErrYAMLUnmarshal = errors.New("yaml config needs an unmarshal func, e.g. yaml.Unmarshal")
+
+// Duration `time.Duration written as "1m30s" in config files`
+type Duration time.Duration
+
+// UnmarshalText `parse a time.ParseDuration string`
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if nil != err {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalText `format as time.Duration does`
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// RateLimitConfig `Rate requests per Per, spaced evenly or with bursts of Burst`
+type RateLimitConfig struct {
+	Rate int `json:"rate" yaml:"rate"`
+	//  0 uses HardLimitPeriod
+	Per Duration `json:"per,omitempty" yaml:"per,omitempty"`
+	//  0 uses a LeakyBucket, otherwise a TokenBucket
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+}
+
+// limiter the Limiter described
+func (c *RateLimitConfig) limiter() (Limiter, error) {
+	if c.Rate < 1 || c.Burst < 0 {
+		return nil, fmt.Errorf("invalid rate limit %d/%s burst %d", c.Rate, time.Duration(c.Per), c.Burst)
+	}
+
+	per := time.Duration(c.Per)
+	if 0 == per {
+		per = HardLimitPeriod
+	}
+	if 0 == c.Burst {
+		return NewLeakyBucket(c.Rate, per), nil
+	}
+
+	return NewTokenBucket(c.Rate, per, c.Burst), nil
+}
+
+// RobotConfig `one robot, Token, Secret and URL are expanded against the environment`
+type RobotConfig struct {
+	Token  string `json:"token" yaml:"token"`
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	//  empty uses the DingTalk api
+	URL       string           `json:"url,omitempty" yaml:"url,omitempty"`
+	Keywords  []string         `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	Profile   RobotProfile     `json:"profile,omitempty" yaml:"profile,omitempty"`
+	//  reserved for P1 pages, see WebHook.Emergency
+	Emergency bool `json:"emergency,omitempty" yaml:"emergency,omitempty"`
+}
+
+// PoolConfig `robots posting to the same group, see RobotPool`
+type PoolConfig struct {
+	Robots []string `json:"robots" yaml:"robots"`
+	Limit  int      `json:"limit,omitempty" yaml:"limit,omitempty"`
+	Period Duration `json:"period,omitempty" yaml:"period,omitempty"`
+}
+
+// Config `declarative robot wiring, fields carry json and yaml tags`
+//
+// Pools and routes refer to robots by their name in Robots.
+type Config struct {
+	Robots map[string]RobotConfig `json:"robots" yaml:"robots"`
+	Pools  map[string]PoolConfig  `json:"pools,omitempty" yaml:"pools,omitempty"`
+	Router *Router                `json:"router,omitempty" yaml:"router,omitempty"`
+}
+
+// Wiring `everything built from a Config`
+type Wiring struct {
+	Registry *Registry
+	Pools    map[string]*RobotPool
+	//  nil when the config has no router
+	Router *Router
+}
+
+// ParseConfig `decode a config, unmarshal defaults to json.Unmarshal, pass yaml.Unmarshal for YAML`
+func ParseConfig(data []byte, unmarshal func([]byte, interface{}) error) (*Config, error) {
+	if nil == unmarshal {
+		unmarshal = json.Unmarshal
+	}
+
+	var config Config
+	if err := unmarshal(data, &config); nil != err {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadConfig `read, decode and build the config at path`
+//
+// A nil unmarshal decodes JSON, a .yaml or .yml path then fails with ErrYAMLUnmarshal instead of
+// a confusing JSON syntax error.
+func LoadConfig(path string, unmarshal func([]byte, interface{}) error) (*Wiring, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); nil == unmarshal && (".yaml" == ext || ".yml" == ext) {
+		return nil, fmt.Errorf("%s: %w", path, ErrYAMLUnmarshal)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+
+	config, err := ParseConfig(data, unmarshal)
+	if nil != err {
+		return nil, err
+	}
+
+	return config.Build()
+}
+
+// Build `create the robots, pools and router the config describes`
+func (c *Config) Build() (*Wiring, error) {
+	wiring := &Wiring{Registry: NewRegistry(), Pools: make(map[string]*RobotPool, len(c.Pools))}
+	for name, spec := range c.Robots {
+		robot, err := spec.build()
+		if nil != err {
+			return nil, fmt.Errorf("robot %q: %v", name, err)
+		}
+		wiring.Registry.Register(name, robot)
+	}
+
+	for name, spec := range c.Pools {
+		if 0 == len(spec.Robots) {
+			return nil, fmt.Errorf("pool %q: no robots", name)
+		}
+		robots := make([]*WebHook, len(spec.Robots))
+		for i, robot := range spec.Robots {
+			w, ok := wiring.Registry.Get(robot)
+			if !ok {
+				return nil, fmt.Errorf("pool %q: %v", name, &UnknownRobotError{Name: robot})
+			}
+			robots[i] = w
+		}
+		pool := NewRobotPool(robots...)
+		pool.Limit, pool.Period = spec.Limit, time.Duration(spec.Period)
+		wiring.Pools[name] = pool
+	}
+
+	if nil != c.Router {
+		router := *c.Router
+		if err := router.validate(); nil != err {
+			return nil, err
+		}
+		for _, route := range router.Routes {
+			for _, robot := range route.Robots {
+				if _, ok := c.Robots[robot]; !ok {
+					return nil, fmt.Errorf("route %q: %v", route.Name, &UnknownRobotError{Name: robot})
+				}
+			}
+		}
+		for _, robot := range router.Default {
+			if _, ok := c.Robots[robot]; !ok {
+				return nil, fmt.Errorf("default route: %v", &UnknownRobotError{Name: robot})
+			}
+		}
+		router.registry = wiring.Registry
+		wiring.Router = &router
+	}
+
+	return wiring, nil
+}
+
+// build the robot described
+func (c RobotConfig) build() (*WebHook, error) {
+	token := os.ExpandEnv(c.Token)
+	if "" == token {
+		return nil, errors.New("no token")
+	}
+
+	w := NewWebHook(token, WithProfile(c.Profile))
+	w.Secret = os.ExpandEnv(c.Secret)
+	if "" != c.URL {
+		w.APIURL = os.ExpandEnv(c.URL)
+	}
+	w.Keywords = c.Keywords
+	w.Emergency = c.Emergency
+	if nil != c.RateLimit {
+		limiter, err := c.RateLimit.limiter()
+		if nil != err {
+			return nil, err
+		}
+		w.Limiter = limiter
+	}
+
+	return w, nil
+}
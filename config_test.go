@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	_ = os.Setenv("DINGTALK_TEST_SECRET", "SECxxx")
+	defer os.Unsetenv("DINGTALK_TEST_SECRET")
+
+	dir, err := ioutil.TempDir("", "dingtalk-config")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "robots.json")
+	config := `{
+		"robots": {
+			"oncall": {"token": "token-oncall", "secret": "${DINGTALK_TEST_SECRET}", "url": "` + server.URL + `",
+				"rateLimit": {"rate": 10, "per": "1m", "burst": 2}, "profile": {"owner": "sre"}},
+			"ops-1": {"token": "token-ops-1", "url": "` + server.URL + `", "keywords": ["alert"]},
+			"ops-2": {"token": "token-ops-2", "url": "` + server.URL + `", "keywords": ["alert"]}
+		},
+		"pools": {"ops": {"robots": ["ops-1", "ops-2"], "limit": 15, "period": "30s"}},
+		"router": {"routes": [{"name": "page", "severities": ["critical"], "robots": ["oncall"]}], "default": ["ops-1"]}
+	}`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); nil != err {
+		t.Fatal(err)
+	}
+
+	wiring, err := LoadConfig(path, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	oncall, _ := wiring.Registry.Get("oncall")
+	if "SECxxx" != oncall.Secret || "sre" != oncall.Profile.Owner || "oncall" != oncall.Name {
+		t.Errorf("robot should be configured from the file, got %+v", oncall)
+	}
+	if _, ok := oncall.Limiter.(*TokenBucket); !ok {
+		t.Errorf("burst should give a token bucket, got %T", oncall.Limiter)
+	}
+	if pool := wiring.Pools["ops"]; nil == pool || 15 != pool.Limit || 30*time.Second != pool.Period || 2 != len(pool.Usage()) {
+		t.Errorf("pool should be configured from the file, got %+v", pool)
+	}
+
+	if err := wiring.Router.Notify(Event{Severity: "critical", Title: "db down"}); nil != err {
+		t.Fatal(err)
+	}
+	if err := wiring.Router.Notify(Event{Severity: "info", Title: "deployed"}); nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if "token-oncall" != requests[0].Query.Get("access_token") || "" == requests[0].Query.Get("sign") {
+		t.Errorf("critical event should go to the signed oncall robot, got %s", requests[0].Query)
+	}
+	if "token-ops-1" != requests[1].Query.Get("access_token") {
+		t.Errorf("other events should go to the default robot, got %s", requests[1].Query)
+	}
+
+	for _, broken := range []string{
+		`{"robots": {"a": {}}}`,
+		`{"robots": {"a": {"token": "t", "rateLimit": {"rate": 0}}}}`,
+		`{"robots": {"a": {"token": "t"}}, "pools": {"p": {"robots": ["b"]}}}`,
+		`{"robots": {"a": {"token": "t"}}, "router": {"routes": [{"name": "r", "robots": ["b"]}]}}`,
+		`{"robots": {"a": {"token": "t"}}, "router": {"default": ["b"]}}`,
+		`{"robots": {"a": {"token": "t", "rateLimit": {"rate": 1, "per": "soon"}}}}`,
+	} {
+		config, err := ParseConfig([]byte(broken), nil)
+		if nil == err {
+			_, err = config.Build()
+		}
+		if nil == err {
+			t.Errorf("config error should be catch! %s", broken)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json"), nil); nil == err || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("missing file error should be catch! got %v", err)
+	}
+	for _, name := range []string{"robots.yaml", "robots.YML"} {
+		if _, err := LoadConfig(filepath.Join(dir, name), nil); !errors.Is(err, ErrYAMLUnmarshal) {
+			t.Errorf("yaml without unmarshal error should be catch! got %v", err)
+		}
+	}
+}
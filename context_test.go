@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	sends := map[string]func(ctx context.Context) error{
+		"text": func(ctx context.Context) error {
+			return webHook.SendTextMsgContext(ctx, "hello", false)
+		},
+		"link": func(ctx context.Context) error {
+			return webHook.SendLinkMsgContext(ctx, "title", "text", "", "https://www.dingtalk.com")
+		},
+		"markdown": func(ctx context.Context) error {
+			return webHook.SendMarkdownMsgContext(ctx, "title", "text", false)
+		},
+		"actionCard": func(ctx context.Context) error {
+			return webHook.SendActionCardMsgContext(ctx, "title", "text", []string{"open"}, []string{"https://www.dingtalk.com"}, false, false)
+		},
+		"feedCard": func(ctx context.Context) error {
+			return webHook.SendLinkCardMsgContext(ctx, []LinkMsg{{Title: "title", MessageURL: "https://www.dingtalk.com"}})
+		},
+	}
+
+	for msgType, send := range sends {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		err := send(ctx)
+		cancel()
+		if nil == err {
+			t.Errorf("%s: deadline error should be catch!", msgType)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%s: send should stop at the deadline, took %s", msgType, elapsed)
+		}
+	}
+}
+
+func TestSendContextLimiter(t *testing.T) {
+	webHook := NewWebHook("token")
+	webHook.Limiter = NewLeakyBucket(1, time.Hour)
+	_ = webHook.Limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := webHook.SendTextMsgContext(ctx, "hello", false); context.Canceled != err {
+		t.Errorf("limiter wait should honour the context, got %v", err)
+	}
+}
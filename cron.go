@@ -0,0 +1,416 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJobExists `returned when adding a cron job under a name already in use`
+var ErrJobExists = errors.New("cron job already exists")
+
+// Schedule `when a recurring message is due, DailySchedule and CronSchedule implement it`
+type Schedule interface {
+	//  first time after after, zero when there is none
+	Next(after time.Time) time.Time
+}
+
+// CronSchedule `a parsed five field cron expression`
+type CronSchedule struct {
+	//  nil means time.Local
+	Location *time.Location
+	//  nil runs on every matching day, otherwise non-workdays are skipped
+	Calendar Calendar
+
+	spec                              string
+	minute, hour, dom, month, weekday uint64
+	//  false when the field was *, cron runs when either restricted day field matches
+	domSet, weekdaySet bool
+}
+
+// cronField bounds and names of one field
+type cronField struct {
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronHorizon years searched for a match before a schedule is considered empty
+const cronHorizon = 5
+
+// ParseCron `parse "minute hour day-of-month month day-of-week" or one of @hourly, @daily, @weekly, @monthly, @yearly`
+//
+// Fields accept *, numbers, ranges, lists and steps such as "*/15", "1-5" or "mon,wed,fri".
+// Day of week 0 and 7 are both Sunday, as in crontab.
+func ParseCron(expr string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(cronFields) != len(fields) {
+		return nil, fmt.Errorf("cron %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &CronSchedule{spec: strings.TrimSpace(expr)}
+	bits := []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.weekday}
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if nil != err {
+			return nil, fmt.Errorf("cron %q: %v", expr, err)
+		}
+		*bits[i] = set
+	}
+	//  7 is another name for Sunday
+	if 0 != s.weekday&(1<<7) {
+		s.weekday |= 1
+	}
+	s.domSet, s.weekdaySet = "*" != fields[2], "*" != fields[4]
+
+	return s, nil
+}
+
+// MustParseCron `ParseCron for expressions known to be valid, panics otherwise`
+func MustParseCron(expr string) *CronSchedule {
+	s, err := ParseCron(expr)
+	if nil != err {
+		panic(err)
+	}
+
+	return s
+}
+
+// parseCronField bit set of the values a field matches
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); -1 != i {
+			n, err := strconv.Atoi(part[i+1:])
+			if nil != err || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step, part = n, part[:i]
+		}
+
+		low, high := bounds.min, bounds.max
+		if "*" != part {
+			ends := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = cronValue(ends[0], bounds); nil != err {
+				return 0, err
+			}
+			high = low
+			if 2 == len(ends) {
+				if high, err = cronValue(ends[1], bounds); nil != err {
+					return 0, err
+				}
+			} else if step > 1 {
+				//  "5/15" means from 5 to the end
+				high = bounds.max
+			}
+		}
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, bounds.min, bounds.max)
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+// cronValue a number or a month or weekday name
+func cronValue(str string, field cronField) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(name, str) {
+			return i + field.min, nil
+		}
+	}
+
+	n, err := strconv.Atoi(str)
+	if nil != err {
+		return 0, fmt.Errorf("invalid value %q", str)
+	}
+
+	return n, nil
+}
+
+// String `the expression the schedule was parsed from`
+func (s *CronSchedule) String() string {
+	return s.spec
+}
+
+// Next `implement Schedule`
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	location := s.Location
+	if nil == location {
+		location = time.Local
+	}
+
+	t := after.In(location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronHorizon
+	for t.Year() <= limit {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		case !s.dayMatches(t) || (nil != s.Calendar && !s.Calendar.IsWorkday(t)):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches crontab semantics, either day field matches when both are restricted
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom, weekday := has(s.dom, t.Day()), has(s.weekday, int(t.Weekday()))
+	if s.domSet && s.weekdaySet {
+		return dom || weekday
+	}
+
+	return dom && weekday
+}
+
+func has(set uint64, v int) bool {
+	return 0 != set&(1<<uint(v))
+}
+
+// MessageFunc `builds the message of a recurring job for the time it is due`
+type MessageFunc func(at time.Time) (Message, error)
+
+// TemplateFunc `MessageFunc rendering a template registered on w with data(at)`
+func (w *WebHook) TemplateFunc(name string, data func(at time.Time) interface{}) MessageFunc {
+	return func(at time.Time) (Message, error) {
+		return w.templates().Render(context.Background(), name, data(at))
+	}
+}
+
+// CronJob `a recurring message`
+type CronJob struct {
+	Name     string
+	Schedule Schedule
+	WebHook  *WebHook
+	Message  MessageFunc
+}
+
+// CronEntry `state of a job as reported by Cron.Entries`
+type CronEntry struct {
+	Name     string
+	Schedule Schedule
+	//  zero when the schedule has no further time
+	Next time.Time
+	//  zero until the job ran
+	Last    time.Time
+	LastErr error
+}
+
+type cronEntry struct {
+	job     CronJob
+	next    time.Time
+	last    time.Time
+	lastErr error
+	stop    chan struct{}
+}
+
+// Cron `runs recurring messages, jobs can be added and removed while it runs`
+//
+// Every job waits on its own timer, a slow send delays nothing but the next run of that job.
+// Runs missed while the process was down or a send was slow are skipped, not caught up.
+type Cron struct {
+	//  called when building or sending a message fails
+	OnError func(name string, err error)
+
+	mu      sync.Mutex
+	entries map[string]*cronEntry
+	running bool
+}
+
+// NewCron `empty stopped scheduler`
+func NewCron() *Cron {
+	return &Cron{entries: make(map[string]*cronEntry)}
+}
+
+// Add `schedule job, its name must be unique`
+func (c *Cron) Add(job CronJob) error {
+	if nil == job.Schedule || nil == job.WebHook || nil == job.Message {
+		return fmt.Errorf("cron job %q: schedule, webhook and message are required", job.Name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[job.Name]; ok {
+		return ErrJobExists
+	}
+
+	now := time.Now()
+	next := job.Schedule.Next(now)
+	if !next.IsZero() && !next.After(now) {
+		return fmt.Errorf("cron job %q: schedule must return a time after %s, got %s", job.Name, now, next)
+	}
+	entry := &cronEntry{job: job, next: next, stop: make(chan struct{})}
+	c.entries[job.Name] = entry
+	if c.running {
+		go c.run(entry, entry.stop)
+	}
+
+	return nil
+}
+
+// AddCron `schedule a message on w with a cron expression`
+func (c *Cron) AddCron(name, expr string, w *WebHook, msg MessageFunc) error {
+	schedule, err := ParseCron(expr)
+	if nil != err {
+		return err
+	}
+
+	return c.Add(CronJob{Name: name, Schedule: schedule, WebHook: w, Message: msg})
+}
+
+// Remove `unschedule a job, false when there is none by that name`
+func (c *Cron) Remove(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[name]
+	if !ok {
+		return false
+	}
+	delete(c.entries, name)
+	close(entry.stop)
+
+	return true
+}
+
+// Entries `all jobs, soonest first`
+func (c *Cron) Entries() []CronEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]CronEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, CronEntry{
+			Name:     entry.job.Name,
+			Schedule: entry.job.Schedule,
+			Next:     entry.next,
+			Last:     entry.last,
+			LastErr:  entry.lastErr,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Next.Equal(entries[j].Next) {
+			return entries[i].Name < entries[j].Name
+		}
+		//  jobs without a next run go last
+		return !entries[i].Next.IsZero() && (entries[j].Next.IsZero() || entries[i].Next.Before(entries[j].Next))
+	})
+
+	return entries
+}
+
+// Start `start running the jobs`
+func (c *Cron) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return
+	}
+
+	c.running = true
+	for _, entry := range c.entries {
+		go c.run(entry, entry.stop)
+	}
+}
+
+// Stop `stop running the jobs, sends in progress are not interrupted`
+func (c *Cron) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.running {
+		return
+	}
+
+	c.running = false
+	for _, entry := range c.entries {
+		close(entry.stop)
+		//  a fresh channel so Start can run the job again
+		entry.stop = make(chan struct{})
+	}
+}
+
+func (c *Cron) run(entry *cronEntry, stop chan struct{}) {
+	for {
+		c.mu.Lock()
+		next := entry.next
+		c.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		err := c.fire(entry.job, next)
+		now := time.Now()
+		c.mu.Lock()
+		entry.last, entry.lastErr = next, err
+		entry.next = entry.job.Schedule.Next(now)
+		//  a schedule that does not move forward would fire in a tight loop, stop the job instead
+		stalled := !entry.next.IsZero() && !entry.next.After(now)
+		if stalled {
+			entry.next = time.Time{}
+		}
+		c.mu.Unlock()
+		if nil != err && nil != c.OnError {
+			c.OnError(entry.job.Name, err)
+		}
+		if stalled && nil != c.OnError {
+			c.OnError(entry.job.Name, fmt.Errorf("schedule did not advance past %s, job stopped", now))
+		}
+	}
+}
+
+func (c *Cron) fire(job CronJob, at time.Time) error {
+	msg, err := job.Message(at)
+	if nil != err {
+		return err
+	}
+
+	return job.WebHook.Send(msg)
+}
@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestParseCron(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	//  a Wednesday
+	after := time.Date(2024, 1, 3, 9, 30, 0, 0, shanghai)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 3, 9, 45, 0, 0, shanghai)},
+		{"0 10 * * mon-fri", time.Date(2024, 1, 3, 10, 0, 0, 0, shanghai)},
+		{"30 9 * * 1-5", time.Date(2024, 1, 4, 9, 30, 0, 0, shanghai)},
+		{"0 18 * * fri", time.Date(2024, 1, 5, 18, 0, 0, 0, shanghai)},
+		{"0 9 * * 7", time.Date(2024, 1, 7, 9, 0, 0, 0, shanghai)},
+		{"0 0 1,15 * *", time.Date(2024, 1, 15, 0, 0, 0, 0, shanghai)},
+		{"0 0 13 * fri", time.Date(2024, 1, 5, 0, 0, 0, 0, shanghai)},
+		{"0 0 29 feb *", time.Date(2024, 2, 29, 0, 0, 0, 0, shanghai)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, shanghai)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseCron(c.expr)
+		if nil != err {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		schedule.Location = shanghai
+		if got := schedule.Next(after); !got.Equal(c.want) {
+			t.Errorf("%s: want %s, got %s", c.expr, c.want, got)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * * * mon-", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr); nil == err {
+			t.Errorf("%q: invalid expression error should be catch!", expr)
+		}
+	}
+
+	schedule := MustParseCron("0 0 30 2 *")
+	if next := schedule.Next(after); !next.IsZero() {
+		t.Errorf("impossible schedule should have no next time, got %s", next)
+	}
+}
+
+func TestCronCalendar(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	calendar := NewHolidayCalendar(Weekdays, "2024-01-01")
+	schedule := MustParseCron("0 10 * * *")
+	schedule.Location, schedule.Calendar = shanghai, calendar
+
+	friday := time.Date(2023, 12, 29, 11, 0, 0, 0, shanghai)
+	if next := schedule.Next(friday); !next.Equal(time.Date(2024, 1, 2, 10, 0, 0, 0, shanghai)) {
+		t.Errorf("weekend and holiday should be skipped, got %s", next)
+	}
+}
+
+// soon schedule due shortly after every run
+type soon struct{}
+
+func (soon) Next(after time.Time) time.Time { return after.Add(10 * time.Millisecond) }
+
+func TestCron(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	cron := NewCron()
+	failed := make(chan error, 1)
+	cron.OnError = func(name string, err error) {
+		select {
+		case failed <- err:
+		default:
+		}
+	}
+	standUp := func(at time.Time) (Message, error) {
+		return &TextMessage{Content: "stand-up at " + at.Format("15:04")}, nil
+	}
+	if err := cron.Add(CronJob{Name: "stand-up", Schedule: soon{}, WebHook: webHook, Message: standUp}); nil != err {
+		t.Fatal(err)
+	}
+	if err := cron.AddCron("weekly", "0 9 * * mon", webHook, standUp); nil != err {
+		t.Fatal(err)
+	}
+	if err := cron.AddCron("stand-up", "@daily", webHook, standUp); ErrJobExists != err {
+		t.Errorf("duplicate name should be rejected, got %v", err)
+	}
+	entries := cron.Entries()
+	if 2 != len(entries) || "stand-up" != entries[0].Name || "0 9 * * mon" != entries[1].Schedule.(*CronSchedule).String() {
+		t.Fatalf("entries should be listed soonest first, got %+v", entries)
+	}
+
+	cron.Start()
+	defer cron.Stop()
+	deadline := time.Now().Add(time.Second)
+	for len(server.Requests()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !cron.Remove("stand-up") {
+		t.Fatal("job should be removed")
+	}
+	if len(server.Requests()) < 2 {
+		t.Fatalf("recurring job should run repeatedly, got %d sends", len(server.Requests()))
+	}
+	if cron.Remove("stand-up") || 1 != len(cron.Entries()) {
+		t.Error("removed job should be gone")
+	}
+
+	broken := errors.New("no on-call rotation")
+	_ = cron.Add(CronJob{Name: "on-call", Schedule: soon{}, WebHook: webHook, Message: func(time.Time) (Message, error) {
+		return nil, broken
+	}})
+	select {
+	case err := <-failed:
+		if broken != err {
+			t.Errorf("OnError should receive the failure, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("job added while running should run")
+	}
+}
+
+// stuck schedule due once, then never moving past the time it is asked about
+type stuck struct{ calls *int32 }
+
+func (s stuck) Next(after time.Time) time.Time {
+	if 1 == atomic.AddInt32(s.calls, 1) {
+		return after.Add(10 * time.Millisecond)
+	}
+	return after
+}
+
+func TestCronStalledSchedule(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	ping := func(at time.Time) (Message, error) { return &TextMessage{Content: "ping"}, nil }
+
+	cron := NewCron()
+	if err := cron.Add(CronJob{Name: "now", Schedule: stuck{calls: new(int32)}, WebHook: webHook, Message: ping}); nil != err {
+		t.Fatal(err)
+	}
+	stalled := stuck{calls: new(int32)}
+	*stalled.calls = 1
+	if err := cron.Add(CronJob{Name: "never", Schedule: stalled, WebHook: webHook, Message: ping}); nil == err {
+		t.Error("schedule not moving forward error should be catch!")
+	}
+
+	failed := make(chan error, 1)
+	cron.OnError = func(name string, err error) { failed <- err }
+	cron.Start()
+	defer cron.Stop()
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("stalled schedule should be reported")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if 1 != len(server.Requests()) {
+		t.Errorf("stalled job should stop after its run, got %d requests", len(server.Requests()))
+	}
+}
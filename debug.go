@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+)
+
+// DebugPath `path RegisterDebugHandlers serves the debug state on`
+const DebugPath = "/debug/dingtalk"
+
+// DebugReporter `component exposing its internal state for quick inspection`
+type DebugReporter interface {
+	DebugState() map[string]interface{}
+}
+
+// DebugState `implement DebugReporter`
+func (w *WebHook) DebugState() map[string]interface{} {
+	state := map[string]interface{}{
+		"channel":   w.channel(),
+		"emergency": w.Emergency,
+		"silences":  len(w.ActiveSilences()),
+	}
+	if !w.Profile.IsZero() {
+		state["profile"] = w.Profile
+	}
+
+	switch limiter := w.limiter().(type) {
+	case *LeakyBucket:
+		state["limiter_delay"] = limiter.Delay().String()
+	case *TokenBucket:
+		state["limiter_delay"] = limiter.Delay().String()
+	case *hardLimiter:
+		state["hard_limit_remaining"] = limiter.remaining()
+	}
+	if nil != w.Breaker {
+		state["breaker"] = w.Breaker.State().String()
+	}
+	w.mu.Lock()
+	if coolDown := time.Until(w.coolUntil); coolDown > 0 {
+		state["cooldown"] = coolDown.String()
+	}
+	w.mu.Unlock()
+	if nil != w.Timeout {
+		state["timeout"] = w.Timeout.Timeout().String()
+	}
+	if nil != w.History {
+		usage := w.History.Usage()
+		state["history_entries"] = usage.Entries
+		state["history_bytes"] = usage.Bytes
+		state["history_evicted"] = usage.Expired + usage.EvictedBySize + usage.EvictedByCount
+	}
+	if stats, ok := w.Metrics.(*Stats); ok {
+		state["stats_overflowed"] = stats.Overflowed()
+		if channel, ok := stats.Snapshot()[w.channel()]; ok {
+			state["sent"] = channel.Sent
+			state["failed"] = channel.Failed
+			state["throttled"] = channel.Throttled
+			state["last_error"] = channel.LastError
+			if !channel.LastErrorAt.IsZero() {
+				state["last_error_at"] = channel.LastErrorAt.Format(time.RFC3339)
+			}
+		}
+	}
+
+	return state
+}
+
+// DebugState `implement DebugReporter`
+func (a *AsyncWebHook) DebugState() map[string]interface{} {
+	a.mu.RLock()
+	closed := a.closed
+	a.mu.RUnlock()
+
+	depth, workers, lanes := 0, make([]int, len(a.queues)), make(map[string]int)
+	for i, queues := range a.queues {
+		for _, priority := range []Priority{PriorityCritical, PriorityNormal, PriorityLow} {
+			n := len(queues[priority.lane()])
+			workers[i] += n
+			lanes[priority.String()] += n
+		}
+		depth += workers[i]
+	}
+
+	return map[string]interface{}{
+		"closed":        closed,
+		"queue_depth":   depth,
+		"worker_depths": workers,
+		"lane_depths":   lanes,
+	}
+}
+
+// debugSnapshot state of every reporter keyed by its name
+func debugSnapshot(reporters map[string]DebugReporter) map[string]interface{} {
+	snapshot := make(map[string]interface{}, len(reporters))
+	for name, reporter := range reporters {
+		snapshot[name] = reporter.DebugState()
+	}
+
+	return snapshot
+}
+
+// RegisterDebugHandlers `serve the state of reporters as json on DebugPath`
+//
+// The state holds channel names and error messages but never tokens or secrets, it is still
+// meant for an internal admin mux rather than a public one.
+func RegisterDebugHandlers(mux *http.ServeMux, reporters map[string]DebugReporter) {
+	mux.HandleFunc(DebugPath, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(debugSnapshot(reporters))
+	})
+}
+
+// PublishExpvar `publish the state of reporters as the expvar name`
+//
+// Like expvar.Publish it panics when name is already taken.
+func PublishExpvar(name string, reporters map[string]DebugReporter) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return debugSnapshot(reporters)
+	}))
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegisterDebugHandlers(t *testing.T) {
+	stats := NewStats()
+	stats.ObserveSend("alerts", "text", 0, errors.New("boom"))
+
+	webHook := NewWebHook("token")
+	webHook.Name = "alerts"
+	webHook.Metrics = stats
+	webHook.Limiter = NewLeakyBucket(20, time.Minute)
+	matcher, _ := MatchContent("noise")
+	webHook.Silence(matcher, time.Minute)
+
+	async := NewAsyncWebHook(webHook, 2, 4)
+	defer async.Close()
+
+	mux := http.NewServeMux()
+	RegisterDebugHandlers(mux, map[string]DebugReporter{"alerts": webHook, "alerts-async": async})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath, nil))
+
+	var state map[string]map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); nil != err {
+		t.Fatal(err)
+	}
+	alerts := state["alerts"]
+	if "boom" != alerts["last_error"] || 1.0 != alerts["failed"] || 1.0 != alerts["silences"] || "0s" != alerts["limiter_delay"] {
+		t.Errorf("unexpected webhook state %v", alerts)
+	}
+	if 0.0 != state["alerts-async"]["queue_depth"] {
+		t.Errorf("unexpected queue state %v", state["alerts-async"])
+	}
+
+	webHook.Emergency = true
+	if HardLimit != webHook.DebugState()["hard_limit_remaining"] {
+		t.Errorf("emergency channels should report the hard limit, got %v", webHook.DebugState())
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	//  expvar names can not be published twice, keep -count reruns apart
+	name := fmt.Sprintf("dingtalk_test_%d", time.Now().UnixNano())
+	PublishExpvar(name, map[string]DebugReporter{"default": NewWebHook("token")})
+
+	var state map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &state); nil != err {
+		t.Fatal(err)
+	}
+	if "default" != state["default"]["channel"] {
+		t.Errorf("unexpected expvar state %v", state)
+	}
+}
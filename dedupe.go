@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDuplicate `returned instead of sending when the idempotency key was already sent within the window`
+var ErrDuplicate = errors.New("duplicate message suppressed")
+
+// DefaultDedupeWindow `how long an idempotency key suppresses re-sends when WebHook.DedupeWindow is 0`
+const DefaultDedupeWindow = 10 * time.Minute
+
+// DedupeStore `remembers idempotency keys, share one between replicas to dedupe across them`
+type DedupeStore interface {
+	//  record key for window, false when it is already recorded
+	Claim(key string, window time.Duration) (bool, error)
+	//  forget key so a failed send can be retried
+	Release(key string) error
+}
+
+// MemoryDedupeStore `in-process DedupeStore`
+type MemoryDedupeStore struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+// NewMemoryDedupeStore `empty in-memory store`
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{keys: make(map[string]time.Time)}
+}
+
+// Claim `implement DedupeStore`
+func (s *MemoryDedupeStore) Claim(key string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, expires := range s.keys {
+		if !now.Before(expires) {
+			delete(s.keys, k)
+		}
+	}
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = now.Add(window)
+
+	return true, nil
+}
+
+// Release `implement DedupeStore`
+func (s *MemoryDedupeStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	return nil
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey `attach an idempotency key to the messages sent with ctx`
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKey `key attached by WithIdempotencyKey, empty when there is none`
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// WithDedupe `suppress re-sends of an idempotency key within window, remembered in memory`
+func WithDedupe(window time.Duration) Option {
+	return WithDedupeStore(NewMemoryDedupeStore(), window)
+}
+
+// WithDedupeStore `suppress re-sends of an idempotency key within window, remembered in store`
+func WithDedupeStore(store DedupeStore, window time.Duration) Option {
+	return func(w *WebHook) {
+		w.Dedupe = store
+		w.DedupeWindow = window
+	}
+}
+
+// deduplicate run send unless the idempotency key of ctx was claimed within the window
+//
+// The key is released again when send fails before delivering anything, so the caller's own retry
+// goes through. Once a part of a split message went out the key is kept, a retry would repeat
+// that part. A store error is logged and the message sent, a duplicate is better than a lost
+// notification.
+func (w *WebHook) deduplicate(ctx context.Context, send func() (bool, error)) error {
+	key := IdempotencyKey(ctx)
+	//  a repeated page is better than a missed one
+	if nil == w.Dedupe || "" == key || w.Emergency {
+		_, err := send()
+		return err
+	}
+
+	//  the same key may be used on several robots sharing a store
+	key = w.channel() + "/" + key
+	window := w.DedupeWindow
+	if 0 == window {
+		window = DefaultDedupeWindow
+	}
+
+	claimed, err := w.Dedupe.Claim(key, window)
+	if nil != err {
+		w.errorf("dingtalk %s: dedupe store: %v", w.channel(), err)
+		_, err = send()
+		return err
+	}
+	if !claimed {
+		w.infof("dingtalk %s: duplicate of %s suppressed", w.channel(), key)
+		return ErrDuplicate
+	}
+
+	delivered, err := send()
+	if nil != err && !delivered {
+		if releaseErr := w.Dedupe.Release(key); nil != releaseErr {
+			w.errorf("dingtalk %s: dedupe store: %v", w.channel(), releaseErr)
+		}
+	}
+
+	return err
+}
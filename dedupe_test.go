@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestDedupe(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("ok", "bad-gateway", "ok", "ok")
+
+	store := NewMemoryDedupeStore()
+	webHook := NewWebHook("token", WithDedupeStore(store, time.Minute))
+	webHook.APIURL = server.URL
+	ctx := WithIdempotencyKey(context.Background(), "incident-42")
+
+	if err := webHook.SendTextMsgContext(ctx, "down", false); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.SendTextMsgContext(ctx, "down", false); ErrDuplicate != err {
+		t.Errorf("re-send within the window should be suppressed, got %v", err)
+	}
+
+	other := WithIdempotencyKey(context.Background(), "incident-43")
+	if err := webHook.SendTextMsgContext(other, "down", false); nil == err {
+		t.Fatal("bad gateway error should be catch!")
+	}
+	if err := webHook.SendTextMsgContext(other, "down", false); nil != err {
+		t.Errorf("failed send should release its key, got %v", err)
+	}
+
+	if err := webHook.SendTextMsg("no key", false); nil != err {
+		t.Errorf("messages without a key should never be suppressed, got %v", err)
+	}
+	if 4 != len(server.Requests()) {
+		t.Errorf("duplicate should not reach the api, got %d requests", len(server.Requests()))
+	}
+
+	replica := NewWebHook("token", WithDedupeStore(store, time.Minute))
+	replica.APIURL = server.URL
+	if _, err := replica.SendWithResultContext(ctx, &TextMessage{Content: "down"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("replicas sharing a store should dedupe, got %v", err)
+	}
+}
+
+func TestMemoryDedupeStore(t *testing.T) {
+	store := NewMemoryDedupeStore()
+	if ok, _ := store.Claim("key", 10*time.Millisecond); !ok {
+		t.Fatal("first claim should succeed")
+	}
+	if ok, _ := store.Claim("key", 10*time.Millisecond); ok {
+		t.Error("second claim should fail")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := store.Claim("key", 10*time.Millisecond); !ok {
+		t.Error("claim should expire after the window")
+	}
+}
+
+func TestDedupeSplitPartlyDelivered(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("ok", "bad-gateway")
+
+	webHook := NewWebHook("token", WithDedupe(time.Minute))
+	webHook.APIURL = server.URL
+	webHook.AutoSplit = true
+	ctx := WithIdempotencyKey(context.Background(), "incident-44")
+
+	long := strings.Repeat(strings.Repeat("x", 99)+"\n", 300)
+	if err := webHook.SendTextMsgContext(ctx, long, false); nil == err || 2 != len(server.Requests()) {
+		t.Fatalf("second part should fail, got %v after %d requests", err, len(server.Requests()))
+	}
+	if err := webHook.SendTextMsgContext(ctx, long, false); ErrDuplicate != err {
+		t.Errorf("retry after a delivered part should be suppressed, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxClockSkew `how far the request timestamp may drift from DingTalk's clock`
+const MaxClockSkew = time.Hour
+
+// SignReason `likely cause of a rejected signature`
+type SignReason string
+
+// sign failure causes
+const (
+	SignClockSkew  SignReason = "clock skew"
+	SignBadSecret  SignReason = "bad secret"
+	SignWrongRobot SignReason = "wrong robot"
+)
+
+// SignError `diagnosed signature failure`
+type SignError struct {
+	*APIError
+	Reason SignReason
+	//  local clock minus DingTalk's clock, zero when the response had no Date header
+	Skew   time.Duration
+	Detail string
+}
+
+// Error `implement error interface`
+func (e *SignError) Error() string {
+	return fmt.Sprintf("%s (%s: %s)", e.APIError.Error(), e.Reason, e.Detail)
+}
+
+// Unwrap `expose the api error`
+func (e *SignError) Unwrap() error {
+	return e.APIError
+}
+
+// isSignError errcode DingTalk uses for signature and robot security failures
+func isSignError(err *APIError) bool {
+	return 310000 == err.Code
+}
+
+// containsFold case insensitive strings.Contains
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// diagnoseSign tell bad secret, clock skew and wrong robot apart
+func (w *WebHook) diagnoseSign(apiErr *APIError, result *SendResult) error {
+	diagnosis := &SignError{APIError: apiErr}
+	message := strings.ToLower(apiErr.Message)
+
+	if date, err := http.ParseTime(result.Header.Get("Date")); nil == err && 0 != result.timestamp {
+		diagnosis.Skew = time.Unix(0, result.timestamp*int64(time.Millisecond)).Sub(date)
+	}
+
+	switch {
+	case strings.Contains(message, "keywords") || strings.Contains(message, "whitelist"):
+		diagnosis.Reason = SignWrongRobot
+		diagnosis.Detail = "the robot is secured by keywords or ip whitelist, not by signature"
+	case !strings.Contains(message, "sign"):
+		return apiErr
+	case "" == w.Secret:
+		diagnosis.Reason = SignWrongRobot
+		diagnosis.Detail = "the robot requires a signature but no Secret is configured"
+	case diagnosis.Skew > MaxClockSkew || diagnosis.Skew < -MaxClockSkew:
+		diagnosis.Reason = SignClockSkew
+		diagnosis.Detail = fmt.Sprintf("local clock is %s off DingTalk's clock", diagnosis.Skew.Round(time.Second))
+	case nil != VerifyReferenceVectors():
+		diagnosis.Reason = SignBadSecret
+		diagnosis.Detail = "local signing does not match the reference vectors"
+	case !strings.HasPrefix(w.Secret, "SEC"):
+		diagnosis.Reason = SignBadSecret
+		diagnosis.Detail = "robot secrets start with SEC, check what was copied"
+	default:
+		diagnosis.Reason = SignBadSecret
+		diagnosis.Detail = "clock and signing are fine, the secret does not match this robot"
+	}
+
+	return diagnosis
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signServer(date time.Time, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Date", date.UTC().Format(http.TimeFormat))
+		_, _ = rw.Write([]byte(body))
+	}))
+}
+
+func TestDiagnoseSign(t *testing.T) {
+	const signMismatch = `{"errcode":310000,"errmsg":"sign not match, more: [https://ding-doc.dingtalk.com/doc#/serverapi2/qf2nxq]"}`
+	cases := []struct {
+		name   string
+		secret string
+		date   time.Time
+		body   string
+		reason SignReason
+	}{
+		{"skew", "SECabc", time.Now().Add(-2 * time.Hour), signMismatch, SignClockSkew},
+		{"bad secret", "SECabc", time.Now(), signMismatch, SignBadSecret},
+		{"not a secret", "abc", time.Now(), signMismatch, SignBadSecret},
+		{"no secret", "", time.Now(), signMismatch, SignWrongRobot},
+		{"keywords", "SECabc", time.Now(), `{"errcode":310000,"errmsg":"keywords not in content"}`, SignWrongRobot},
+	}
+
+	for _, c := range cases {
+		server := signServer(c.date, c.body)
+		webHook := NewWebHook("token")
+		webHook.APIURL = server.URL
+		webHook.Secret = c.secret
+
+		err := webHook.SendTextMsg("hello", false)
+		signErr, ok := err.(*SignError)
+		if !ok {
+			t.Errorf("%s: sign error expected, got %v", c.name, err)
+		} else if c.reason != signErr.Reason {
+			t.Errorf("%s: want %s, got %s (%s)", c.name, c.reason, signErr.Reason, signErr.Detail)
+		}
+		server.Close()
+	}
+}
+
+func TestDiagnoseSignPassesOtherErrors(t *testing.T) {
+	server := signServer(time.Now(), `{"errcode":130101,"errmsg":"send too fast"}`)
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	err := webHook.SendTextMsg("hello", false)
+	if apiErr, ok := err.(*APIError); !ok || 130101 != apiErr.Code {
+		t.Errorf("plain api error expected, got %v", err)
+	}
+}
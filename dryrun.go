@@ -0,0 +1,46 @@
+package webhook
+
+import "encoding/json"
+
+// DryRunRequest `a request that was built, validated and signed but not posted`
+type DryRunRequest struct {
+	URL      string
+	Body     []byte
+	Warnings []LintWarning
+}
+
+// dryRun validate and lint a signed body instead of posting it
+func (w *WebHook) dryRun(apiURL string, bs []byte, result *SendResult) (*SendResult, error) {
+	request := &DryRunRequest{URL: apiURL, Body: bs}
+
+	var payload PayLoad
+	if err := json.Unmarshal(bs, &payload); nil == err {
+		//  msgtypes without a schema are left to the api, as SendRaw does
+		if _, ok := SchemaFor(payload.MsgType); ok {
+			if err := ValidatePayload(&payload); nil != err {
+				return nil, err
+			}
+		}
+		request.Warnings = lintPayload(&payload)
+	}
+
+	if nil != w.OnDryRun {
+		w.OnDryRun(*request)
+	}
+
+	result.Response = Response{ErrorCode: 0, ErrorMessage: "ok"}
+	result.DryRun = request
+	return result, nil
+}
+
+// lintPayload lint the markdown sections of a payload
+func lintPayload(payload *PayLoad) []LintWarning {
+	switch {
+	case nil != payload.Markdown:
+		return Lint(payload.Markdown.Text)
+	case nil != payload.ActionCard:
+		return Lint(payload.ActionCard.Text)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestDryRun(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	var requests []DryRunRequest
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Secret = "secret"
+	webHook.DryRun = true
+	webHook.OnDryRun = func(r DryRunRequest) {
+		requests = append(requests, r)
+	}
+
+	result, err := webHook.SendWithResult(&MarkdownMessage{Title: "t", Text: "| a | b |\n|---|---|"})
+	if nil != err {
+		t.Fatalf("dry run should succeed, got %v", err)
+	}
+	if 0 != len(server.Requests()) {
+		t.Error("dry run should not hit the api")
+	}
+	if nil == result.DryRun || 1 != len(requests) {
+		t.Fatalf("dry run request should be returned and handed to OnDryRun, got %+v", result)
+	}
+	if !strings.Contains(result.DryRun.URL, "access_token=token") || !strings.Contains(result.DryRun.URL, "sign=") {
+		t.Errorf("url should be signed, got %s", result.DryRun.URL)
+	}
+	if !strings.Contains(string(result.DryRun.Body), `"msgtype":"markdown"`) {
+		t.Errorf("json body should be returned, got %s", result.DryRun.Body)
+	}
+	if 0 == len(result.DryRun.Warnings) || "table" != result.DryRun.Warnings[0].Rule {
+		t.Errorf("markdown should be linted, got %v", result.DryRun.Warnings)
+	}
+
+	err = webHook.SendRaw([]byte(`{"msgtype":"link","link":{"title":"t"}}`))
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("validation error should be catch! got %v", err)
+	}
+	if err := webHook.SendRaw([]byte(`{"msgtype":"interactiveCard"}`)); nil != err {
+		t.Errorf("unknown msgtype should be left to the api, got %v", err)
+	}
+}
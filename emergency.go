@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DingTalk's own limit per robot, enforced even for emergency channels
+const (
+	HardLimit       = 20
+	HardLimitPeriod = time.Minute
+)
+
+// hardLimiter sliding window allowing HardLimit requests per HardLimitPeriod
+type hardLimiter struct {
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+// Wait `implement Limiter`
+func (h *hardLimiter) Wait(ctx context.Context) error {
+	for {
+		h.mu.Lock()
+		now := time.Now()
+		for 0 < len(h.sent) && now.Sub(h.sent[0]) >= HardLimitPeriod {
+			h.sent = h.sent[1:]
+		}
+		if len(h.sent) < HardLimit {
+			h.sent = append(h.sent, now)
+			h.mu.Unlock()
+			return nil
+		}
+		delay := HardLimitPeriod - now.Sub(h.sent[0])
+		h.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// remaining requests allowed right now
+func (h *hardLimiter) remaining() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := HardLimit
+	now := time.Now()
+	for _, sent := range h.sent {
+		if now.Sub(sent) < HardLimitPeriod {
+			n--
+		}
+	}
+	return n
+}
+
+// limiter limiter for the next request, emergency channels only honour DingTalk's hard limit
+func (w *WebHook) limiter() Limiter {
+	if !w.Emergency {
+		return w.Limiter
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if nil == w.hardLimit {
+		w.hardLimit = &hardLimiter{}
+	}
+
+	return w.hardLimit
+}
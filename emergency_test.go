@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestEmergencyBypass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Limiter = NewLeakyBucket(1, time.Hour)
+	webHook.Silence(MatcherFunc(func(*PayLoad) bool { return true }), time.Hour)
+
+	if err := webHook.SendTextMsg("P1 db down", false); ErrSilenced != err {
+		t.Fatalf("regular channel should be silenced, got %v", err)
+	}
+
+	webHook.Emergency = true
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := webHook.SendTextMsg("P1 db down", false); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) > time.Second {
+		t.Error("emergency channel should not wait on the local limiter")
+	}
+}
+
+func TestEmergencyBypassDedupe(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithDedupe(time.Hour))
+	webHook.APIURL = server.URL
+	webHook.Emergency = true
+
+	ctx := WithIdempotencyKey(context.Background(), "db-1-down")
+	for i := 0; i < 2; i++ {
+		if err := webHook.SendContext(ctx, &TextMessage{Content: "P1 db down"}); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if 2 != len(server.Requests()) {
+		t.Errorf("emergency channel should not suppress duplicates, got %d requests", len(server.Requests()))
+	}
+}
+
+func TestEmergencyBypassDigest(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Emergency = true
+
+	aggregator := NewAggregator(webHook, time.Hour)
+	defer aggregator.Close()
+	if err := aggregator.Send(&TextMessage{Content: "P1 db down"}); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(server.Requests()) {
+		t.Error("emergency channel should not wait for the digest window")
+	}
+}
+
+func TestEmergencyBypassRules(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithRules(Rule{Name: "drop all", Drop: true}))
+	webHook.APIURL = server.URL
+	if err := webHook.SendText("P1 db down"); ErrDropped != err {
+		t.Fatalf("regular channel should drop, got %v", err)
+	}
+
+	webHook.Emergency = true
+	if err := webHook.SendText("P1 db down"); nil != err || 1 != len(server.Requests()) {
+		t.Errorf("emergency channel should not be dropped by rules, got %v", err)
+	}
+}
+
+func TestEmergencyConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"robots": {"p1": {"token": "t", "emergency": true}}}`), nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	wiring, err := config.Build()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if robot, _ := wiring.Registry.Get("p1"); !robot.Emergency {
+		t.Error("robot should be marked emergency from the config")
+	}
+}
+
+func TestHardLimiter(t *testing.T) {
+	limiter := &hardLimiter{}
+	for i := 0; i < HardLimit; i++ {
+		if err := limiter.Wait(context.Background()); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); context.DeadlineExceeded != err {
+		t.Errorf("request above DingTalk's limit should wait, got %v", err)
+	}
+}
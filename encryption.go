@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// EncryptionProvider `encrypt message contents kept at rest`
+type EncryptionProvider interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESGCM `EncryptionProvider using AES-GCM with a random nonce per record`
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM `key must be 16, 24 or 32 bytes`
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	block, err := aes.NewCipher(key)
+	if nil != err {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if nil != err {
+		return nil, err
+	}
+
+	return &AESGCM{aead: aead}, nil
+}
+
+// Encrypt `implement EncryptionProvider, the nonce is prepended to the output`
+func (e *AESGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(plaintext)+e.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); nil != err {
+		return nil, err
+	}
+
+	return e.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt `implement EncryptionProvider`
+func (e *AESGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := e.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return e.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
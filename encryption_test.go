@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAESGCM(t *testing.T) {
+	if _, err := NewAESGCM([]byte("short")); nil == err {
+		t.Error("invalid key size error should be catch!")
+	}
+
+	provider, err := NewAESGCM(bytes.Repeat([]byte{7}, 32))
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := provider.Encrypt([]byte("customer 42"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, []byte("customer")) {
+		t.Error("plaintext should not be visible")
+	}
+
+	plaintext, err := provider.Decrypt(ciphertext)
+	if nil != err || "customer 42" != string(plaintext) {
+		t.Errorf("round trip failed: %q %v", plaintext, err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := provider.Decrypt(ciphertext); nil == err {
+		t.Error("tampered ciphertext should be rejected")
+	}
+}
+
+func TestHistoryEncryption(t *testing.T) {
+	provider, _ := NewAESGCM(bytes.Repeat([]byte{1}, 16))
+	history := NewHistory(0)
+	history.Encryption = provider
+
+	payload := &PayLoad{MsgType: "text", Text: &Text{Content: "order for customer 42 failed"}}
+	_ = history.Record("ops", payload, nil)
+
+	history.mu.Lock()
+	stored := history.records.at(0).data
+	history.mu.Unlock()
+	if bytes.Contains(stored, []byte("customer")) {
+		t.Error("stored payload should be encrypted")
+	}
+
+	entries, err := history.Entries()
+	if nil != err || !strings.Contains(string(entries[0].Payload), "customer 42") {
+		t.Errorf("entries should be decrypted, got %v %v", entries, err)
+	}
+}
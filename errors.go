@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// sentinel errors matched with errors.Is against api errors
+var (
+	ErrTooFast         = errors.New("send too fast")
+	ErrInvalidToken    = errors.New("invalid access token")
+	ErrSignMismatch    = errors.New("sign not match")
+	ErrKeywordMismatch = errors.New("keywords not in content")
+	ErrIPNotAllowed    = errors.New("ip not in whitelist")
+)
+
+// DetailHeaders `response headers kept on errors, enough to quote in a support ticket`
+var DetailHeaders = []string{"Content-Type", "Date", "Retry-After", "Server", "X-Request-Id", "Eagleeye-Traceid"}
+
+// MaxDetailBody `bytes of the response body kept on errors`
+var MaxDetailBody = 4096
+
+// APIError `non-zero errcode returned by DingTalk`
+type APIError struct {
+	Code    int
+	Message string
+	//  DetailHeaders of the response and at most MaxDetailBody bytes of its body
+	Header http.Header
+	Body   []byte
+}
+
+// Error `implement error interface`
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api custom error: {code: %d, msg: %s}", e.Code, e.Message)
+}
+
+// Is `match the sentinel of the errcode`
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrTooFast:
+		return 130101 == e.Code
+	case ErrInvalidToken:
+		return 300001 == e.Code
+	case ErrSignMismatch:
+		return 310000 == e.Code && containsFold(e.Message, "sign")
+	case ErrKeywordMismatch:
+		return 310000 == e.Code && containsFold(e.Message, "keywords")
+	case ErrIPNotAllowed:
+		return 310000 == e.Code && containsFold(e.Message, "whitelist")
+	}
+
+	return false
+}
+
+// HTTPError `api answered with a status other than 200`
+type HTTPError struct {
+	StatusCode int
+	//  DetailHeaders of the response and at most MaxDetailBody bytes of its body
+	Header http.Header
+	Body   []byte
+}
+
+// Error `implement error interface`
+func (e *HTTPError) Error() string {
+	if 0 == len(e.Body) {
+		return fmt.Sprintf("api response error: %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("api response error: %d, body: %s", e.StatusCode, bodySnippet(e.Body))
+}
+
+// ResponseError `api answered 200 with a body that is not a DingTalk response`
+type ResponseError struct {
+	//  DetailHeaders of the response and at most MaxDetailBody bytes of its body
+	Header http.Header
+	Body   []byte
+	Err    error
+}
+
+// Error `implement error interface`
+func (e *ResponseError) Error() string {
+	return "response struct error: response is not a json anymore, " + e.Err.Error()
+}
+
+// Unwrap `expose the json error`
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// detailHeader the DetailHeaders present in header
+func detailHeader(header http.Header) http.Header {
+	detail := make(http.Header)
+	for _, key := range DetailHeaders {
+		key = http.CanonicalHeaderKey(key)
+		if values, ok := header[key]; ok {
+			detail[key] = values
+		}
+	}
+
+	return detail
+}
+
+// detailBody body cut to MaxDetailBody bytes
+func detailBody(body []byte) []byte {
+	if len(body) > MaxDetailBody {
+		body = body[:MaxDetailBody]
+	}
+
+	return append([]byte(nil), body...)
+}
+
+// bodySnippet first line of body, short enough for an error string
+func bodySnippet(body []byte) string {
+	const limit = 200
+	snippet := strings.TrimSpace(string(body))
+	if i := strings.IndexAny(snippet, "\r\n"); -1 != i {
+		snippet = snippet[:i] + "…"
+	}
+	if len(snippet) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(snippet[cut]) {
+			cut--
+		}
+		snippet = snippet[:cut] + "…"
+	}
+
+	return snippet
+}
+
+// RequestError `api could not be reached`
+type RequestError struct {
+	Err error
+}
+
+// Error `implement error interface`
+func (e *RequestError) Error() string {
+	return "api request error: " + e.Err.Error()
+}
+
+// Unwrap `expose the transport error, e.g. context.DeadlineExceeded`
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// TokenError `access token missing or unknown to DingTalk`
+type TokenError struct {
+	//  nil when the token was rejected before sending
+	*APIError
+	Reason string
+}
+
+// Error `implement error interface`
+func (e *TokenError) Error() string {
+	if nil == e.APIError {
+		return "token error: " + e.Reason
+	}
+
+	return fmt.Sprintf("%s (%s)", e.APIError.Error(), e.Reason)
+}
+
+// Is `always matches ErrInvalidToken`
+func (e *TokenError) Is(target error) bool {
+	return ErrInvalidToken == target
+}
+
+// Unwrap `expose the api error`
+func (e *TokenError) Unwrap() error {
+	if nil == e.APIError {
+		return nil
+	}
+
+	return e.APIError
+}
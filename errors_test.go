@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestTypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("access_token") {
+		case "fast":
+			_, _ = rw.Write([]byte(`{"errcode":130101,"errmsg":"send too fast"}`))
+		case "unknown":
+			_, _ = rw.Write([]byte(`{"errcode":300001,"errmsg":"token is not exist"}`))
+		case "keyword":
+			_, _ = rw.Write([]byte(`{"errcode":310000,"errmsg":"keywords not in content"}`))
+		case "sign":
+			_, _ = rw.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	send := func(token string) error {
+		webHook := NewWebHook(token)
+		webHook.APIURL = server.URL
+		webHook.Secret = "SECabc"
+		return webHook.SendTextMsg("hello", false)
+	}
+
+	var apiErr *APIError
+	if err := send("fast"); !errors.Is(err, ErrTooFast) || !errors.As(err, &apiErr) || 130101 != apiErr.Code {
+		t.Errorf("rate limit should match ErrTooFast and APIError, got %v", err)
+	}
+
+	var tokenErr *TokenError
+	if err := send("unknown"); !errors.Is(err, ErrInvalidToken) || !errors.As(err, &tokenErr) || nil == tokenErr.APIError {
+		t.Errorf("unknown token should be a TokenError, got %v", err)
+	}
+	if err := send(""); !errors.As(err, &tokenErr) || nil != tokenErr.APIError {
+		t.Errorf("empty token should be rejected locally, got %v", err)
+	}
+
+	var signErr *SignError
+	if err := send("keyword"); !errors.Is(err, ErrKeywordMismatch) || !errors.As(err, &signErr) {
+		t.Errorf("keyword failure should match ErrKeywordMismatch, got %v", err)
+	}
+	if err := send("sign"); !errors.Is(err, ErrSignMismatch) || errors.Is(err, ErrKeywordMismatch) {
+		t.Errorf("sign failure should only match ErrSignMismatch, got %v", err)
+	}
+
+	var httpErr *HTTPError
+	if err := send("down"); !errors.As(err, &httpErr) || http.StatusServiceUnavailable != httpErr.StatusCode {
+		t.Errorf("status failure should be an HTTPError, got %v", err)
+	}
+
+	webHook := NewWebHook("slow")
+	webHook.APIURL = server.URL
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var requestErr *RequestError
+	if err := webHook.SendTextMsgContext(ctx, "hello", false); !errors.As(err, &requestErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("deadline should be reachable through RequestError, got %v", err)
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("bad-gateway", "missing-param")
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	var httpErr *HTTPError
+	err := webHook.SendTextMsg("hello", false)
+	if !errors.As(err, &httpErr) || 502 != httpErr.StatusCode {
+		t.Fatalf("bad gateway should be an HTTPError, got %v", err)
+	}
+	if "text/html" != httpErr.Header.Get("Content-Type") || "" == httpErr.Header.Get("Date") {
+		t.Errorf("selected headers should be kept, got %v", httpErr.Header)
+	}
+	if !strings.Contains(string(httpErr.Body), "502 Bad Gateway") || !strings.Contains(err.Error(), "<h1>502 Bad Gateway</h1>") {
+		t.Errorf("body should be kept and quoted, got %q", err)
+	}
+
+	var apiErr *APIError
+	if err := webHook.SendTextMsg("hello", false); !errors.As(err, &apiErr) || !strings.Contains(string(apiErr.Body), "40035") || nil == apiErr.Header {
+		t.Errorf("api error should carry the response, got %v", err)
+	}
+
+	defer func(max int) { MaxDetailBody = max }(MaxDetailBody)
+	MaxDetailBody = 4
+	if body := detailBody([]byte("truncated")); "trun" != string(body) {
+		t.Errorf("body should be cut to MaxDetailBody, got %q", body)
+	}
+	if header := detailHeader(http.Header{"Set-Cookie": {"secret"}, "Retry-After": {"1"}}); 1 != len(header) {
+		t.Errorf("only DetailHeaders should be kept, got %v", header)
+	}
+}
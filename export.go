@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// export formats
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+)
+
+// HistoryFilter `select history entries, zero fields match everything`
+type HistoryFilter struct {
+	Since   time.Time
+	Until   time.Time
+	Channel string
+	Sender  string
+	Status  string
+}
+
+// Match `report whether entry passes the filter`
+func (f HistoryFilter) Match(entry HistoryEntry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	if "" != f.Channel && f.Channel != entry.Channel {
+		return false
+	}
+	if "" != f.Sender && f.Sender != entry.Sender {
+		return false
+	}
+
+	return "" == f.Status || f.Status == entry.Status
+}
+
+// Export `write the filtered history as csv or json`
+func (h *History) Export(w io.Writer, format string, filter HistoryFilter) error {
+	entries, err := h.Entries()
+	if nil != err {
+		return err
+	}
+
+	var selected []HistoryEntry
+	for _, entry := range entries {
+		if filter.Match(entry) {
+			selected = append(selected, entry)
+		}
+	}
+
+	switch format {
+	case ExportCSV:
+		return exportCSV(w, selected)
+	case ExportJSON:
+		return exportJSON(w, selected)
+	}
+
+	return fmt.Errorf("unknown export format: %q", format)
+}
+
+func exportCSV(w io.Writer, entries []HistoryEntry) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time", "channel", "msgtype", "status", "error", "payload", "sender"})
+	for _, entry := range entries {
+		_ = cw.Write([]string{
+			entry.Time.Format(time.RFC3339),
+			entry.Channel,
+			entry.MsgType,
+			entry.Status,
+			entry.Error,
+			string(entry.Payload),
+			entry.Sender,
+		})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func exportJSON(w io.Writer, entries []HistoryEntry) error {
+	type row struct {
+		Time    time.Time       `json:"time"`
+		Channel string          `json:"channel"`
+		MsgType string          `json:"msgtype"`
+		Status  string          `json:"status"`
+		Error   string          `json:"error,omitempty"`
+		Payload json.RawMessage `json:"payload,omitempty"`
+		Sender  string          `json:"sender,omitempty"`
+	}
+
+	rows := make([]row, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, row{entry.Time, entry.Channel, entry.MsgType, entry.Status, entry.Error, entry.Payload, entry.Sender})
+	}
+
+	return json.NewEncoder(w).Encode(rows)
+}
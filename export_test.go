@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistoryExport(t *testing.T) {
+	history := NewHistory(0)
+	payload := &PayLoad{MsgType: "text", Text: &Text{Content: "deploy done"}}
+	_ = history.Record("ci", payload, nil)
+	_ = history.Record("ops", payload, nil)
+	_ = history.Record("ops", payload, errors.New("api custom error"))
+
+	var buf bytes.Buffer
+	if err := history.Export(&buf, ExportCSV, HistoryFilter{Channel: "ops"}); nil != err {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 3 != len(rows) || "failed" != rows[2][3] {
+		t.Errorf("header and two ops rows expected, got %v", rows)
+	}
+
+	buf.Reset()
+	if err := history.Export(&buf, ExportJSON, HistoryFilter{Status: StatusSent, Since: time.Now().Add(-time.Minute)}); nil != err {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(decoded) || nil == decoded[0]["payload"] {
+		t.Errorf("two sent entries with payload expected, got %v", decoded)
+	}
+
+	if err := history.Export(&buf, "xml", HistoryFilter{}); nil == err {
+		t.Error("unknown format error should be catch!")
+	}
+	if err := history.Export(&buf, ExportJSON, HistoryFilter{Until: time.Now().Add(-time.Hour)}); nil != err || "[]\n" != buf.String()[len(buf.String())-3:] {
+		t.Errorf("empty range should export an empty list, got %q", buf.String())
+	}
+}
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Downgrade `the primary robot failed and a fallback delivered the message`
+type Downgrade struct {
+	//  position in WebHook.Fallbacks of the sender that delivered
+	Fallback int
+	//  failures of the primary and of the fallbacks tried before, in order
+	Errors []*TargetError
+}
+
+// WithFallback `deliver through fallbacks, in order, when the primary robot fails`
+func WithFallback(fallbacks ...Sender) Option {
+	return func(w *WebHook) {
+		w.Fallbacks = append(w.Fallbacks, fallbacks...)
+	}
+}
+
+// AsSender `w as a Sender, e.g. to serve as the fallback of another robot`
+func (w *WebHook) AsSender() Sender {
+	return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+		return w.send(context.WithValue(ctx, fallbackKey{}, true), payload)
+	})
+}
+
+// failover deliver through the fallbacks after the primary failed with err
+//
+// The message goes out unchanged by the primary, every fallback applies its own keywords, footers
+// and other decorations. failures is nil unless every fallback failed too.
+func (w *WebHook) failover(ctx context.Context, original *PayLoad, err error) (*SendResult, *MultiError) {
+	failures := &MultiError{}
+	failures.add(w.channel(), err)
+	for i, fallback := range w.Fallbacks {
+		result, err := fallback.Send(ctx, clonePayload(original))
+		if nil == err {
+			w.infof("dingtalk %s: delivered by fallback #%d after %v", w.channel(), i, failures)
+			if nil == result {
+				result = &SendResult{}
+			}
+			result.Downgrade = &Downgrade{Fallback: i, Errors: failures.Errors}
+			return result, nil
+		}
+		failures.add(fmt.Sprintf("fallback #%d", i), err)
+	}
+
+	return nil, failures
+}
+
+// shouldFailover the robot, not the message, is the problem, and the caller still waits
+func shouldFailover(ctx context.Context, err error) bool {
+	if nil == err || nil != ctx.Err() || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	return retryLater(err) || (errors.As(err, &apiErr) && nonDriftCodes[apiErr.Code])
+}
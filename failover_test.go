@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestFailover(t *testing.T) {
+	primary := webhooktest.NewServer()
+	defer primary.Close()
+	backup := webhooktest.NewServer()
+	defer backup.Close()
+	_ = primary.ReplyFixture("bad-gateway", "missing-param", "token-not-exist")
+	_ = backup.ReplyFixture("ok", "bad-gateway")
+
+	fallback := NewWebHook("backup")
+	fallback.APIURL = backup.URL
+	fallback.Name = "backup"
+	var provider []string
+	other := SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+		provider = append(provider, payload.Text.Content)
+		return nil, nil
+	})
+
+	webHook := NewWebHook("token", WithFallback(fallback.AsSender(), other))
+	webHook.APIURL = primary.URL
+	webHook.Keywords = []string{"[primary]"}
+	var lost []Failure
+	webHook.OnPermanentFailure = func(f Failure) { lost = append(lost, f) }
+
+	result, err := webHook.SendWithResult(&TextMessage{Content: "db-1 down"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if nil == result.Downgrade || 0 != result.Downgrade.Fallback || 1 != len(result.Downgrade.Errors) {
+		t.Fatalf("result should note the downgrade, got %+v", result.Downgrade)
+	}
+	var httpErr *HTTPError
+	if 0 != len(lost) {
+		t.Errorf("message delivered by a fallback should not be reported lost, got %v", lost)
+	}
+	if !errors.As(result.Downgrade.Errors[0], &httpErr) || "default" != result.Downgrade.Errors[0].Target {
+		t.Errorf("downgrade should keep the primary failure, got %v", result.Downgrade.Errors)
+	}
+	if payload, _ := backup.Requests()[0].Payload(); "db-1 down" != payload.Text.Content {
+		t.Errorf("fallback should get the message without the primary's decorations, got %q", payload.Text.Content)
+	}
+
+	if err := webHook.SendText("bad payload"); nil == err || 1 != len(backup.Requests()) {
+		t.Errorf("a rejected message should not fail over, got %v", err)
+	}
+
+	result, err = webHook.SendWithResult(&TextMessage{Content: "db-2 down"})
+	if nil != err || 1 != result.Downgrade.Fallback || 2 != len(result.Downgrade.Errors) || "db-2 down" != provider[0] {
+		t.Errorf("next fallback should be tried when one fails, got %v %+v", err, result)
+	}
+}
+
+func TestFailoverExhausted(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("bad-gateway", "bad-gateway")
+
+	fallback := NewWebHook("backup")
+	fallback.APIURL = server.URL
+	var lost []Failure
+	fallback.OnPermanentFailure = func(f Failure) { lost = append(lost, f) }
+	webHook := NewWebHook("token", WithFallback(fallback.AsSender()))
+	webHook.APIURL = server.URL
+	webHook.OnPermanentFailure = func(f Failure) { lost = append(lost, f) }
+
+	err := webHook.SendText("hello")
+	var multi *MultiError
+	if !errors.As(err, &multi) || 2 != multi.Total || "fallback #0" != multi.Failed()[1] {
+		t.Errorf("every failure should be reported, got %v", err)
+	}
+	if 1 != len(lost) || "default" != lost[0].Channel || 2 != len(lost[0].Errors) {
+		t.Errorf("message should be reported lost once, by the primary, got %+v", lost)
+	}
+}
+
+func TestFailoverCallerGaveUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	fallbacks := 0
+	webHook := NewWebHook("token", WithFallback(SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+		fallbacks++
+		return nil, nil
+	})))
+	webHook.APIURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := webHook.SendContext(ctx, &TextMessage{Content: "db-1 down"}); nil == err || 0 != fallbacks {
+		t.Errorf("a send the caller gave up on should not fail over, got %v after %d fallbacks", err, fallbacks)
+	}
+}
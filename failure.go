@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// Failure `a notification that could not be delivered`
+//
+// It carries enough context to open a ticket in an external system: what was sent, where to,
+// and every error seen while trying.
+type Failure struct {
+	Channel  string
+	Sender   SenderIdentity
+	Profile  RobotProfile
+	Payload  *PayLoad
+	Attempts int
+	//  one error per attempt, the last one is why delivery was given up
+	Errors []error
+	Time   time.Time
+}
+
+// Err `the error delivery was given up on`
+func (f Failure) Err() error {
+	if 0 == len(f.Errors) {
+		return nil
+	}
+
+	return f.Errors[len(f.Errors)-1]
+}
+
+// Summary `one line description, e.g. for a ticket title`
+func (f Failure) Summary() string {
+	summary := fmt.Sprintf("%s notification to %s lost after %d attempt(s): %v", f.Payload.MsgType, f.Channel, f.Attempts, f.Err())
+	if !f.Profile.IsZero() {
+		summary += " [" + f.Profile.String() + "]"
+	}
+
+	return summary
+}
+
+// reportFailure log a lost payload and hand it to OnPermanentFailure, silenced and dropped payloads are not lost
+func (w *WebHook) reportFailure(payload *PayLoad, errs []error) {
+	if 0 == len(errs) {
+		return
+	}
+	if last := errs[len(errs)-1]; ErrSilenced == last || ErrDropped == last {
+		return
+	}
+
+	failure := Failure{
+		Channel:  w.channel(),
+		Sender:   w.Sender,
+		Profile:  w.Profile,
+		Payload:  payload,
+		Attempts: len(errs),
+		Errors:   errs,
+		Time:     time.Now(),
+	}
+	w.errorf("dingtalk: %s", failure.Summary())
+	if nil != w.OnPermanentFailure {
+		w.OnPermanentFailure(failure)
+	}
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestOnPermanentFailure(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	var failures []Failure
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Name = "alerts"
+	webHook.OnPermanentFailure = func(f Failure) { failures = append(failures, f) }
+
+	_ = server.ReplyFixture("send-too-fast")
+	if err := webHook.SendTextMsg("disk full", false); nil == err {
+		t.Fatal("api error should be catch!")
+	}
+	if 1 != len(failures) {
+		t.Fatalf("one failure expected, got %d", len(failures))
+	}
+
+	failure := failures[0]
+	if "alerts" != failure.Channel || 1 != failure.Attempts || !errors.Is(failure.Err(), ErrTooFast) || "disk full" != failure.Payload.Text.Content {
+		t.Errorf("unexpected failure %+v", failure)
+	}
+	if !strings.Contains(failure.Summary(), "text notification to alerts lost after 1 attempt(s)") {
+		t.Errorf("unexpected summary %s", failure.Summary())
+	}
+
+	matcher, _ := MatchContent("disk")
+	webHook.Silence(matcher, time.Minute)
+	if err := webHook.SendTextMsg("disk full", false); ErrSilenced != err {
+		t.Fatalf("payload should be silenced, got %v", err)
+	}
+	if 1 != len(failures) {
+		t.Error("silenced payloads are not failures")
+	}
+}
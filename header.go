@@ -0,0 +1,39 @@
+package webhook
+
+import "net/http"
+
+// DefaultUserAgent `User-Agent of requests when WebHook.UserAgent is empty`
+const DefaultUserAgent = "dingtalk-webhook-go (+https://github.com/lddsb/dingtalk-webhook)"
+
+// WithHeader `add a header to every request, e.g. the auth header of an egress gateway`
+func WithHeader(key, value string) Option {
+	return func(w *WebHook) {
+		if nil == w.Header {
+			w.Header = make(http.Header)
+		}
+		w.Header.Add(key, value)
+	}
+}
+
+// WithUserAgent `identify the requests, e.g. "billing-service/1.4"`
+func WithUserAgent(userAgent string) Option {
+	return func(w *WebHook) {
+		w.UserAgent = userAgent
+	}
+}
+
+// setHeaders apply Header and UserAgent on top of the headers set by the library
+func (w *WebHook) setHeaders(req *http.Request) {
+	for key, values := range w.Header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	userAgent := w.UserAgent
+	if "" == userAgent {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestHeaders(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithHeader("X-Gateway-Auth", "s3cret"), WithHeader("X-Audit", "a"), WithHeader("X-Audit", "b"))
+	webHook.APIURL = server.URL
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+
+	webHook.UserAgent = "billing/1.4"
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	header := requests[0].Header
+	if "s3cret" != header.Get("X-Gateway-Auth") || 2 != len(header["X-Audit"]) || "application/json" != header.Get("Content-Type") {
+		t.Errorf("custom headers should be added, got %v", header)
+	}
+	if DefaultUserAgent != header.Get("User-Agent") || "billing/1.4" != requests[1].Header.Get("User-Agent") {
+		t.Errorf("user agent should be set, got %q and %q", header.Get("User-Agent"), requests[1].Header.Get("User-Agent"))
+	}
+}
@@ -0,0 +1,298 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// Compressor `compress archived payloads, plug in e.g. zstd by implementing it`
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor `gzip Compressor at the given level`
+type GzipCompressor struct {
+	Level int
+}
+
+// Compress `implement Compressor`
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := c.Level
+	if 0 == level {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if nil != err {
+		return nil, err
+	}
+	if _, err := zw.Write(data); nil != err {
+		return nil, err
+	}
+	if err := zw.Close(); nil != err {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress `implement Compressor`
+func (c GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if nil != err {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
+}
+
+// history entry statuses
+const (
+	StatusSent   = "sent"
+	StatusFailed = "failed"
+)
+
+// HistoryEntry `one archived send`
+type HistoryEntry struct {
+	Time    time.Time
+	Channel string
+	//  SenderIdentity of the service which sent it, empty when unknown
+	Sender  string
+	MsgType string
+	Status  string
+	//  JSON payload, nil when it exceeded MaxEntrySize
+	Payload []byte
+	Error   string
+}
+
+// History `archive of sent payloads with compression, size caps and retention`
+type History struct {
+	//  nil stores payloads uncompressed
+	Compressor Compressor
+	//  nil stores payloads in the clear, applied after compression
+	Encryption EncryptionProvider
+	//  entries older than this are dropped, 0 keeps them forever
+	Retention time.Duration
+	//  payloads bigger than this after compression are archived without body, 0 is unlimited
+	MaxEntrySize int
+	//  oldest entries are evicted once stored bodies exceed this, 0 is unlimited
+	MaxTotalSize int
+	//  ring buffer capacity, the oldest entry is evicted to make room, 0 uses DefaultHistoryEntries
+	MaxEntries int
+
+	mu      sync.Mutex
+	records historyRing
+	size    int
+	usage   HistoryUsage
+}
+
+// DefaultHistoryEntries `entries kept by a History without MaxEntries`
+const DefaultHistoryEntries = 10000
+
+// HistoryUsage `current size of a History and why entries left it`
+type HistoryUsage struct {
+	Entries int
+	Bytes   int
+	//  dropped after Retention
+	Expired int64
+	//  evicted to stay under MaxTotalSize
+	EvictedBySize int64
+	//  evicted to stay under MaxEntries
+	EvictedByCount int64
+}
+
+// historyRing oldest-first ring buffer growing up to its limit
+type historyRing struct {
+	buf   []historyRecord
+	head  int
+	count int
+}
+
+func (r *historyRing) len() int {
+	return r.count
+}
+
+// at i-th oldest record
+func (r *historyRing) at(i int) historyRecord {
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// push append record, the caller makes room first once limit is reached
+func (r *historyRing) push(record historyRecord, limit int) {
+	if r.count == len(r.buf) {
+		size := 2 * len(r.buf)
+		if size < 16 {
+			size = 16
+		}
+		if size > limit {
+			size = limit
+		}
+		buf := make([]historyRecord, size)
+		for i := 0; i < r.count; i++ {
+			buf[i] = r.at(i)
+		}
+		r.buf, r.head = buf, 0
+	}
+
+	r.buf[(r.head+r.count)%len(r.buf)] = record
+	r.count++
+}
+
+// pop remove and return the oldest record
+func (r *historyRing) pop() historyRecord {
+	record := r.buf[r.head]
+	r.buf[r.head] = historyRecord{}
+	r.head = (r.head + 1) % len(r.buf)
+	r.count--
+
+	return record
+}
+
+type historyRecord struct {
+	time    time.Time
+	channel string
+	sender  string
+	msgType string
+	data    []byte
+	err     string
+}
+
+// NewHistory `gzip compressed history keeping entries for retention`
+func NewHistory(retention time.Duration) *History {
+	return &History{Compressor: GzipCompressor{}, Retention: retention}
+}
+
+// Record `archive a payload sent to channel and the outcome of sending it`
+func (h *History) Record(channel string, payload *PayLoad, sendErr error) error {
+	return h.RecordAs("", channel, payload, sendErr)
+}
+
+// RecordAs `Record attributing the payload to sender`
+func (h *History) RecordAs(sender, channel string, payload *PayLoad, sendErr error) error {
+	data, err := json.Marshal(payload)
+	if nil != err {
+		return err
+	}
+	if nil != h.Compressor {
+		if data, err = h.Compressor.Compress(data); nil != err {
+			return err
+		}
+	}
+	if nil != h.Encryption {
+		if data, err = h.Encryption.Encrypt(data); nil != err {
+			return err
+		}
+	}
+
+	record := historyRecord{time: time.Now(), channel: channel, sender: sender, msgType: payload.MsgType, data: data}
+	if 0 != h.MaxEntrySize && len(data) > h.MaxEntrySize {
+		record.data = nil
+	}
+	if nil != sendErr {
+		record.err = sendErr.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	limit := h.MaxEntries
+	if limit <= 0 {
+		limit = DefaultHistoryEntries
+	}
+	for h.records.len() >= limit {
+		h.size -= len(h.records.pop().data)
+		h.usage.EvictedByCount++
+	}
+	h.records.push(record, limit)
+	h.size += len(record.data)
+	h.prune(record.time)
+
+	return nil
+}
+
+// Entries `retained entries, oldest first`
+func (h *History) Entries() ([]HistoryEntry, error) {
+	h.mu.Lock()
+	h.prune(time.Now())
+	records := make([]historyRecord, h.records.len())
+	for i := range records {
+		records[i] = h.records.at(i)
+	}
+	h.mu.Unlock()
+
+	entries := make([]HistoryEntry, 0, len(records))
+	for _, record := range records {
+		entry := HistoryEntry{Time: record.time, Channel: record.channel, Sender: record.sender, MsgType: record.msgType, Status: StatusSent, Error: record.err}
+		if "" != record.err {
+			entry.Status = StatusFailed
+		}
+		if nil != record.data {
+			data, err := h.decode(record.data)
+			if nil != err {
+				return nil, err
+			}
+			entry.Payload = data
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// decode undo encryption and compression of a stored body
+func (h *History) decode(data []byte) ([]byte, error) {
+	var err error
+	if nil != h.Encryption {
+		if data, err = h.Encryption.Decrypt(data); nil != err {
+			return nil, err
+		}
+	}
+	if nil != h.Compressor {
+		if data, err = h.Compressor.Decompress(data); nil != err {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// Size `bytes currently held by stored bodies`
+func (h *History) Size() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.size
+}
+
+// Usage `entries, bytes and eviction counters`
+func (h *History) Usage() HistoryUsage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	usage := h.usage
+	usage.Entries, usage.Bytes = h.records.len(), h.size
+	return usage
+}
+
+// prune apply retention and the total size cap, caller holds the lock
+func (h *History) prune(now time.Time) {
+	for 0 < h.records.len() {
+		record := h.records.at(0)
+		expired := 0 != h.Retention && now.Sub(record.time) > h.Retention
+		oversize := 0 != h.MaxTotalSize && h.size > h.MaxTotalSize
+		switch {
+		case expired:
+			h.usage.Expired++
+		case oversize:
+			h.usage.EvictedBySize++
+		default:
+			return
+		}
+		h.size -= len(h.records.pop().data)
+	}
+}
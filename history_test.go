@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryCompression(t *testing.T) {
+	history := NewHistory(0)
+	payload := &PayLoad{MsgType: "markdown", Markdown: &Markdown{
+		Title: "report",
+		Text:  strings.Repeat("| host | cpu | mem |\n", 500),
+	}}
+
+	if err := history.Record("ops", payload, errors.New("boom")); nil != err {
+		t.Fatal(err)
+	}
+	if size := history.Size(); size > len(payload.Markdown.Text)/10 {
+		t.Errorf("payload should be compressed, stored %d bytes", size)
+	}
+
+	entries, err := history.Entries()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(entries) || "boom" != entries[0].Error || "markdown" != entries[0].MsgType {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if !strings.Contains(string(entries[0].Payload), "| host | cpu | mem |") {
+		t.Error("payload should round trip through the compressor")
+	}
+}
+
+func TestHistoryCapsAndRetention(t *testing.T) {
+	history := &History{MaxEntrySize: 100, MaxTotalSize: 250}
+	small := &PayLoad{MsgType: "text", Text: &Text{Content: "ok"}}
+	big := &PayLoad{MsgType: "text", Text: &Text{Content: strings.Repeat("x", 200)}}
+
+	_ = history.Record("ops", big, nil)
+	entries, _ := history.Entries()
+	if nil != entries[0].Payload {
+		t.Error("oversized payload body should not be stored")
+	}
+
+	for i := 0; i < 10; i++ {
+		_ = history.Record("ops", small, nil)
+	}
+	if history.Size() > 250 {
+		t.Errorf("total size cap should be enforced, got %d", history.Size())
+	}
+
+	history.Retention = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if entries, _ := history.Entries(); 0 != len(entries) {
+		t.Errorf("expired entries should be dropped, got %d", len(entries))
+	}
+}
+
+func TestHistoryRingBuffer(t *testing.T) {
+	history := &History{MaxEntries: 20}
+	for i := 0; i < 50; i++ {
+		_ = history.Record("ops", &PayLoad{MsgType: "text", Text: &Text{Content: strconv.Itoa(i)}}, nil)
+	}
+
+	entries, err := history.Entries()
+	if nil != err || 20 != len(entries) {
+		t.Fatalf("ring buffer should keep 20 entries, got %d %v", len(entries), err)
+	}
+	if !strings.Contains(string(entries[0].Payload), `"30"`) || !strings.Contains(string(entries[19].Payload), `"49"`) {
+		t.Errorf("the newest entries should be kept oldest first, got %s .. %s", entries[0].Payload, entries[19].Payload)
+	}
+
+	usage := history.Usage()
+	if 20 != usage.Entries || 30 != usage.EvictedByCount || history.Size() != usage.Bytes {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	history.Retention = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, _ = history.Entries()
+	if usage := history.Usage(); 0 != usage.Entries || 20 != usage.Expired || 0 != usage.Bytes {
+		t.Errorf("expired entries should be counted, got %+v", usage)
+	}
+}
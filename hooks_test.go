@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendHooks(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("ok", "send-too-fast")
+
+	var lastErr error
+	sent := 0
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.OnBeforeSend = func(payload *PayLoad) {
+		rewriteContent(payload, func(content string) string {
+			return strings.Replace(content, "hunter2", "***", -1)
+		})
+	}
+	webHook.OnAfterSend = func(payload *PayLoad, result *SendResult, err error) {
+		sent++
+		lastErr = err
+		if nil == result {
+			t.Error("result should be passed once the api was reached")
+		}
+	}
+
+	if err := webHook.SendTextMsg("password hunter2", false); nil != err {
+		t.Fatal(err)
+	}
+	if payload, _ := server.Requests()[0].Payload(); "password ***" != payload.Text.Content {
+		t.Errorf("OnBeforeSend should be able to scrub content, got %q", payload.Text.Content)
+	}
+
+	_ = webHook.SendTextMsg("hello", false)
+	if 2 != sent || !errors.Is(lastErr, ErrTooFast) {
+		t.Errorf("OnAfterSend should see every outcome, got %d %v", sent, lastErr)
+	}
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+)
+
+var idempotencyTagPattern = regexp.MustCompile(`idem:([0-9a-f]{12})`)
+
+// IdempotencyTag `short tag identifying the content of a payload`
+//
+// Identical payloads get the same tag whichever replica sends them and however often they are
+// retried, so log scrapers and digests can recognize duplicates.
+func IdempotencyTag(payload *PayLoad) string {
+	bs, _ := json.Marshal(payload)
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:6])
+}
+
+// ParseIdempotencyTag `tag embedded in a message body by WebHook.IdempotencyFooter`
+func ParseIdempotencyTag(content string) (string, bool) {
+	m := idempotencyTagPattern.FindStringSubmatch(content)
+	if nil == m {
+		return "", false
+	}
+
+	return m[1], true
+}
+
+// idempotencyFooter append tag in a way readers barely notice
+func idempotencyFooter(payload *PayLoad, tag string) {
+	rewriteContent(payload, func(content string) string {
+		if "text" == payload.MsgType || "link" == payload.MsgType {
+			//  zero width space keeps the tag on its own, almost empty looking line
+			return content + "\n\u200bidem:" + tag
+		}
+		return content + "\n\n<font color=#dddddd>idem:" + tag + "</font>"
+	})
+}
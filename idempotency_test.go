@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestIdempotencyFooter(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	replicas := []*WebHook{NewWebHook("token", WithSenderIdentity("billing", "")), NewWebHook("token")}
+	for _, webHook := range replicas {
+		webHook.APIURL = server.URL
+		webHook.IdempotencyFooter = true
+		webHook.SenderFooter = true
+		_ = webHook.SendTextMsg("disk full", false)
+	}
+	_ = replicas[0].SendMarkdownMsg("disk", "#### disk full", false)
+
+	var tags []string
+	for _, request := range server.Requests() {
+		payload, _ := request.Payload()
+		tag, ok := ParseIdempotencyTag(payload.Text.Content + payload.Markdown.Text)
+		if !ok {
+			t.Fatalf("tag should be embedded, got %q", request.Body)
+		}
+		tags = append(tags, tag)
+	}
+	if tags[0] != tags[1] {
+		t.Errorf("same content should get the same tag, got %v", tags)
+	}
+	if tags[0] == tags[2] {
+		t.Errorf("different content should get different tags, got %v", tags)
+	}
+
+	payload, _ := server.Requests()[2].Payload()
+	if !strings.HasSuffix(payload.Markdown.Text, "<font color=#dddddd>idem:"+tags[2]+"</font>") {
+		t.Errorf("markdown tag should be greyed out, got %q", payload.Markdown.Text)
+	}
+	if _, ok := ParseIdempotencyTag("no tag here"); ok {
+		t.Error("untagged content should not parse")
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoSenderIdentity `returned by robots with RequireSender when no identity is configured`
+var ErrNoSenderIdentity = errors.New("sender identity is required by this robot")
+
+// SenderIdentity `service and team a shared robot's traffic is attributed to`
+type SenderIdentity struct {
+	Service string
+	Team    string
+}
+
+// IsZero `no identity configured`
+func (s SenderIdentity) IsZero() bool {
+	return "" == s.Service && "" == s.Team
+}
+
+// String `service/team, empty for the zero identity`
+func (s SenderIdentity) String() string {
+	switch {
+	case s.IsZero():
+		return ""
+	case "" == s.Team:
+		return s.Service
+	case "" == s.Service:
+		return "/" + s.Team
+	}
+
+	return s.Service + "/" + s.Team
+}
+
+// WithSenderIdentity `attribute every send to service and team`
+func WithSenderIdentity(service, team string) Option {
+	return func(w *WebHook) {
+		w.Sender = SenderIdentity{Service: service, Team: team}
+	}
+}
+
+// SenderMetrics `Metrics which also want the sender identity of every send`
+type SenderMetrics interface {
+	Metrics
+	ObserveSenderSend(sender SenderIdentity, channel, msgType string, latency time.Duration, err error)
+}
+
+// observeSend report to m, with the sender when m understands it
+func observeSend(m Metrics, sender SenderIdentity, channel, msgType string, latency time.Duration, err error) {
+	if sm, ok := m.(SenderMetrics); ok {
+		sm.ObserveSenderSend(sender, channel, msgType, latency, err)
+		return
+	}
+
+	m.ObserveSend(channel, msgType, latency, err)
+}
+
+// senderFooter append the sender to the visible body
+func (w *WebHook) senderFooter(payload *PayLoad) {
+	if !w.SenderFooter || w.Sender.IsZero() {
+		return
+	}
+
+	rewriteContent(payload, func(content string) string {
+		if "markdown" == payload.MsgType || "actionCard" == payload.MsgType {
+			return content + "\n\n<font color=#999999>sent by " + w.Sender.String() + "</font>"
+		}
+		return content + "\n\n— sent by " + w.Sender.String()
+	})
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSenderIdentity(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	stats := NewStats()
+	var failures []Failure
+	webHook := NewWebHook("token", WithSenderIdentity("billing", "payments"))
+	webHook.APIURL = server.URL
+	webHook.Name = "shared"
+	webHook.SenderFooter = true
+	webHook.History = NewHistory(time.Hour)
+	webHook.Metrics = NewBudgetMonitor(nil)
+	webHook.Metrics.(*BudgetMonitor).Next = stats
+	webHook.OnPermanentFailure = func(f Failure) { failures = append(failures, f) }
+
+	if err := webHook.SendMarkdownMsg("deploy", "#### done", false); nil != err {
+		t.Fatal(err)
+	}
+	_ = server.ReplyFixture("send-too-fast")
+	_ = webHook.SendTextMsg("hi", false)
+
+	var payload PayLoad
+	_ = json.Unmarshal(server.Requests()[0].Body, &payload)
+	if !strings.HasSuffix(payload.Markdown.Text, "<font color=#999999>sent by billing/payments</font>") {
+		t.Errorf("footer should be appended, got %q", payload.Markdown.Text)
+	}
+	_ = json.Unmarshal(server.Requests()[1].Body, &payload)
+	if "hi\n\n— sent by billing/payments" != payload.Text.Content {
+		t.Errorf("footer should be appended, got %q", payload.Text.Content)
+	}
+
+	if sender := stats.SenderSnapshot()["billing/payments"]; 1 != sender.Sent || 1 != sender.Throttled {
+		t.Errorf("sends should be counted per sender through the budget monitor, got %+v", sender)
+	}
+	if 1 != len(failures) || "billing" != failures[0].Sender.Service {
+		t.Errorf("failure should carry the sender, got %+v", failures)
+	}
+
+	var buf bytes.Buffer
+	if err := webHook.History.Export(&buf, ExportCSV, HistoryFilter{Sender: "billing/payments", Status: StatusFailed}); nil != err {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); 2 != len(lines) || !strings.HasSuffix(lines[1], ",billing/payments") {
+		t.Errorf("history should be attributed to the sender, got %s", buf.String())
+	}
+}
+
+func TestRequireSender(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.RequireSender = true
+	if err := webHook.SendTextMsg("hi", false); !errors.Is(err, ErrNoSenderIdentity) {
+		t.Errorf("anonymous send should be refused, got %v", err)
+	}
+	if 0 != len(server.Requests()) {
+		t.Error("nothing should reach the api")
+	}
+}
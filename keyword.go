@@ -0,0 +1,51 @@
+package webhook
+
+import "strings"
+
+// injectKeyword make sure the body carries one of Keywords, keyword-secured robots reject it otherwise
+func (w *WebHook) injectKeyword(payload *PayLoad) {
+	if 0 == len(w.Keywords) {
+		return
+	}
+	if "feedCard" == payload.MsgType && nil != payload.FeedCard {
+		w.injectFeedCardKeyword(payload.FeedCard)
+		return
+	}
+
+	rewriteContent(payload, func(content string) string {
+		for _, keyword := range w.Keywords {
+			if strings.Contains(content, keyword) {
+				return content
+			}
+		}
+
+		if w.KeywordPrefix && "text" == payload.MsgType {
+			return w.Keywords[0] + " " + content
+		}
+		//  markdown headings only work at the start of a line
+		if w.KeywordPrefix {
+			return w.Keywords[0] + "\n\n" + content
+		}
+		return content + "\n\n" + w.Keywords[0]
+	})
+}
+
+// injectFeedCardKeyword a feed card has no body, carry the keyword in the title of its first link
+func (w *WebHook) injectFeedCardKeyword(card *FeedCard) {
+	if 0 == len(card.Links) {
+		return
+	}
+	for _, link := range card.Links {
+		for _, keyword := range w.Keywords {
+			if strings.Contains(link.Title, keyword) {
+				return
+			}
+		}
+	}
+
+	if w.KeywordPrefix {
+		card.Links[0].Title = w.Keywords[0] + " " + card.Links[0].Title
+	} else {
+		card.Links[0].Title += " " + w.Keywords[0]
+	}
+}
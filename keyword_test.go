@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestKeywordInjection(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Keywords = []string{"[alert]", "[notice]"}
+
+	_ = webHook.SendTextMsg("disk full", false)
+	_ = webHook.SendTextMsg("[notice] deploy done", false)
+	webHook.KeywordPrefix = true
+	_ = webHook.SendMarkdownMsg("deploy", "#### done", false)
+	_ = webHook.SendTextMsg("disk full", false)
+
+	requests := server.Requests()
+	first, _ := requests[0].Payload()
+	second, _ := requests[1].Payload()
+	third, _ := requests[2].Payload()
+	if "disk full\n\n[alert]" != first.Text.Content {
+		t.Errorf("keyword should be appended, got %q", first.Text.Content)
+	}
+	if "[notice] deploy done" != second.Text.Content {
+		t.Errorf("content carrying a keyword should be left alone, got %q", second.Text.Content)
+	}
+	fourth, _ := requests[3].Payload()
+	if "[alert]\n\n#### done" != third.Markdown.Text || "[alert] disk full" != fourth.Text.Content {
+		t.Errorf("keyword should be prepended, got %q and %q", third.Markdown.Text, fourth.Text.Content)
+	}
+
+	links := []LinkMsg{{Title: "disk full", MessageURL: "https://example.com/1"}, {Title: "[notice] cpu", MessageURL: "https://example.com/2"}}
+	_ = webHook.SendLinkCardMsg(links[:1])
+	_ = webHook.SendLinkCardMsg(links)
+	var fifth, sixth PayLoad
+	_ = json.Unmarshal(server.Requests()[4].Body, &fifth)
+	_ = json.Unmarshal(server.Requests()[5].Body, &sixth)
+	if "[alert] disk full" != fifth.FeedCard.Links[0].Title || "disk full" != sixth.FeedCard.Links[0].Title {
+		t.Errorf("feed card keyword should go in the first title, got %+v and %+v", fifth.FeedCard.Links, sixth.FeedCard.Links)
+	}
+	if "disk full" != links[0].Title {
+		t.Errorf("caller links should not be changed, got %q", links[0].Title)
+	}
+}
+
+func TestKeywordSecuredRobot(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	server.Secure(webhooktest.Security{Keywords: []string{"[ops]"}, Secret: "secret"})
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Secret = "secret"
+	if err := webHook.SendTextMsg("disk full", false); !errors.Is(err, ErrKeywordMismatch) {
+		t.Errorf("keyword mismatch should be catch! got %v", err)
+	}
+
+	webHook.Keywords = []string{"[ops]"}
+	if err := webHook.SendTextMsg("disk full", false); nil != err {
+		t.Errorf("injected keyword should pass, got %v", err)
+	}
+	if err := webHook.SendLinkCardMsg([]LinkMsg{{Title: "disk full", MessageURL: "https://example.com", PicURL: "https://example.com/a.png"}}); nil != err {
+		t.Errorf("keyword injected into a feed card should pass, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LifecycleEnv `lifecycle announcements are only sent when this env var is true`
+const LifecycleEnv = "DINGTALK_LIFECYCLE_NOTIFY"
+
+// ServiceInfo `what a lifecycle announcement reports`
+type ServiceInfo struct {
+	Name    string
+	Version string
+	//  see ConfigHash
+	ConfigHash string
+}
+
+// ConfigHash `short fingerprint of a config file, to spot mismatched rollouts`
+func ConfigHash(config []byte) string {
+	sum := sha256.Sum256(config)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// NotifyStartup `announce that the service started, no-op unless LifecycleEnv is set`
+func (w *WebHook) NotifyStartup(info ServiceInfo) error {
+	return w.notifyLifecycle("started", info, "")
+}
+
+// NotifyShutdown `announce that the service is stopping, no-op unless LifecycleEnv is set`
+func (w *WebHook) NotifyShutdown(info ServiceInfo, reason string) error {
+	return w.notifyLifecycle("stopping", info, reason)
+}
+
+func (w *WebHook) notifyLifecycle(event string, info ServiceInfo, reason string) error {
+	if enabled, _ := strconv.ParseBool(os.Getenv(LifecycleEnv)); !enabled {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	title := fmt.Sprintf("%s %s", info.Name, event)
+	lines := []string{
+		"#### " + title,
+		"- version: " + orDash(info.Version),
+		"- host: " + orDash(host),
+		"- config: " + orDash(info.ConfigHash),
+		"- time: " + FormatTime(time.Now()),
+	}
+	if "" != reason {
+		lines = append(lines, "- reason: "+reason)
+	}
+
+	return w.SendMarkdownMsg(title, strings.Join(lines, "\n"), false)
+}
+
+func orDash(s string) string {
+	if "" == s {
+		return "-"
+	}
+
+	return s
+}
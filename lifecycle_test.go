@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNotifyLifecycle(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload PayLoad
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		texts = append(texts, payload.Markdown.Text)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	info := ServiceInfo{Name: "billing", Version: "v1.2.3", ConfigHash: ConfigHash([]byte("port: 80"))}
+
+	_ = os.Unsetenv(LifecycleEnv)
+	if err := webHook.NotifyStartup(info); nil != err || 0 != len(texts) {
+		t.Fatal("announcements should be off without the env flag")
+	}
+
+	_ = os.Setenv(LifecycleEnv, "true")
+	defer os.Unsetenv(LifecycleEnv)
+	if err := webHook.NotifyStartup(info); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.NotifyShutdown(info, "SIGTERM"); nil != err {
+		t.Fatal(err)
+	}
+
+	if 2 != len(texts) {
+		t.Fatalf("two announcements expected, got %d", len(texts))
+	}
+	if !strings.Contains(texts[0], "billing started") || !strings.Contains(texts[0], "v1.2.3") || !strings.Contains(texts[0], info.ConfigHash) {
+		t.Errorf("unexpected startup text: %s", texts[0])
+	}
+	if !strings.Contains(texts[1], "reason: SIGTERM") {
+		t.Errorf("unexpected shutdown text: %s", texts[1])
+	}
+	if 12 != len(info.ConfigHash) {
+		t.Errorf("config hash should be short, got %s", info.ConfigHash)
+	}
+}
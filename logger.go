@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Logger `minimal logging interface, adapt your logging framework to it`
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// StdLogger `Logger writing to a standard library logger, levels become a prefix`
+func StdLogger(l *log.Logger) Logger {
+	return stdLogger{l}
+}
+
+type stdLogger struct {
+	l *log.Logger
+}
+
+// Debugf `implement Logger`
+func (s stdLogger) Debugf(format string, args ...interface{}) {
+	s.l.Printf("[DEBUG] "+format, args...)
+}
+
+// Infof `implement Logger`
+func (s stdLogger) Infof(format string, args ...interface{}) {
+	s.l.Printf("[INFO] "+format, args...)
+}
+
+// Errorf `implement Logger`
+func (s stdLogger) Errorf(format string, args ...interface{}) {
+	s.l.Printf("[ERROR] "+format, args...)
+}
+
+// WithDebug `dump every request and response to logger, see WebHook.Debug`
+func WithDebug(logger Logger) Option {
+	return func(w *WebHook) {
+		w.Logger = logger
+		w.Debug = true
+	}
+}
+
+// infof log at info level when a Logger is set
+func (w *WebHook) infof(format string, args ...interface{}) {
+	if nil != w.Logger {
+		w.Logger.Infof(format, args...)
+	}
+}
+
+// errorf log at error level when a Logger is set
+func (w *WebHook) errorf(format string, args ...interface{}) {
+	if nil != w.Logger {
+		w.Logger.Errorf(format, args...)
+	}
+}
+
+// debugf log at debug level when Debug is on
+func (w *WebHook) debugf(format string, args ...interface{}) {
+	if w.Debug && nil != w.Logger {
+		w.Logger.Debugf(format, args...)
+	}
+}
+
+// redactURL hide the access token, the signature is only valid for an hour and stays
+func redactURL(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if nil != err {
+		return "(unparsable url)"
+	}
+
+	query := u.Query()
+	if "" != query.Get("access_token") {
+		query.Set("access_token", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// dumpHeader one "Key: value" line per header, sorted
+func dumpHeader(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, strings.Join(header[key], ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
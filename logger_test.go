@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (r *recordingLogger) Debugf(format string, args ...interface{}) {
+	r.lines = append(r.lines, "debug "+fmt.Sprintf(format, args...))
+}
+
+func (r *recordingLogger) Infof(format string, args ...interface{}) {
+	r.lines = append(r.lines, "info "+fmt.Sprintf(format, args...))
+}
+
+func (r *recordingLogger) Errorf(format string, args ...interface{}) {
+	r.lines = append(r.lines, "error "+fmt.Sprintf(format, args...))
+}
+
+func TestDebugDump(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	server.ReplyFixture("keywords-not-in-content")
+
+	logger := &recordingLogger{}
+	webHook := NewWebHook("secret-token", WithDebug(logger))
+	webHook.APIURL = server.URL
+
+	_ = webHook.SendTextMsg("hello", false)
+	if 3 != len(logger.lines) || !strings.HasPrefix(logger.lines[2], "error dingtalk: text notification to default lost") {
+		t.Fatalf("request and response should be dumped before the failure, got %q", logger.lines)
+	}
+	request, response := logger.lines[0], logger.lines[1]
+	if strings.Contains(request, "secret-token") || !strings.Contains(request, "access_token=REDACTED") {
+		t.Errorf("token should be redacted, got %s", request)
+	}
+	if !strings.Contains(request, `"content":"hello"`) {
+		t.Errorf("json body should be dumped, got %s", request)
+	}
+	if !strings.Contains(response, "200 OK") || !strings.Contains(response, "Content-Type: ") || !strings.Contains(response, "310000") {
+		t.Errorf("status, headers and body should be dumped, got %s", response)
+	}
+
+	logger.lines = nil
+	webHook.Debug = false
+	_ = webHook.SendTextMsg("hello", false)
+	if 0 != len(logger.lines) {
+		t.Errorf("successful sends should only be logged with Debug, got %q", logger.lines)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := StdLogger(log.New(&buf, "", 0))
+	logger.Errorf("code %d", 1)
+	if "[ERROR] code 1\n" != buf.String() {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestLoggerRetries(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("bad-gateway")
+
+	logger := &recordingLogger{}
+	webHook := NewWebHook("token", WithRetry(&RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	webHook.APIURL = server.URL
+	webHook.Logger = logger
+	webHook.Limiter = NewLeakyBucket(1, 150*time.Millisecond)
+
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(logger.lines) || !strings.HasPrefix(logger.lines[0], "info dingtalk default: attempt 1 failed, retrying in") {
+		t.Errorf("retry should be logged, got %q", logger.lines)
+	}
+
+	logger.lines = nil
+	webHook.Debug = true
+	_ = webHook.SendTextMsg("hello", false)
+	if 3 != len(logger.lines) || !strings.HasPrefix(logger.lines[0], "debug dingtalk default: rate limiter held the request for") {
+		t.Errorf("rate limiter wait should be logged, got %q", logger.lines)
+	}
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// MentionOverflow `what happens to mentions beyond WebHook.MaxMentions`
+type MentionOverflow int
+
+const (
+	// MentionSummarize `mention the first MaxMentions members, the rest are summed up as "and N others"`
+	MentionSummarize MentionOverflow = iota
+	// MentionSplit `mention the rest in follow-up messages, so every member is notified`
+	MentionSplit
+)
+
+// capMentions summarize mentions beyond MaxMentions, split payloads are already within the cap
+func (w *WebHook) capMentions(payload *PayLoad) {
+	kept, dropped := w.partitionMentions(payload)
+	if 0 == len(dropped) {
+		return
+	}
+
+	payload.At = kept
+	rewriteContent(payload, func(content string) string {
+		others := "others"
+		if 1 == len(dropped) {
+			others = "other"
+		}
+		return fmt.Sprintf("%s and %d %s", message.StripMentions(content, dropped), len(dropped), others)
+	})
+}
+
+// splitMentions payload within MaxMentions followed by "cc" messages mentioning everyone else
+func (w *WebHook) splitMentions(payload *PayLoad) []*PayLoad {
+	if MentionSplit != w.MentionOverflow {
+		return []*PayLoad{payload}
+	}
+	kept, dropped := w.partitionMentions(payload)
+	if 0 == len(dropped) {
+		return []*PayLoad{payload}
+	}
+
+	first := clonePayload(payload)
+	first.At = kept
+	rewriteContent(first, func(content string) string {
+		return message.StripMentions(content, dropped)
+	})
+
+	parts := []*PayLoad{first}
+	mobiles := len(payload.At.AtMobiles) - len(kept.AtMobiles)
+	for start := 0; start < len(dropped); start += w.MaxMentions {
+		end := start + w.MaxMentions
+		if end > len(dropped) {
+			end = len(dropped)
+		}
+
+		at := &At{}
+		for i := start; i < end; i++ {
+			if i < mobiles {
+				at.AtMobiles = append(at.AtMobiles, dropped[i])
+			} else {
+				at.AtUserIds = append(at.AtUserIds, dropped[i])
+			}
+		}
+		content := "cc @" + strings.Join(dropped[start:end], " @")
+		part := &PayLoad{MsgType: payload.MsgType, At: at}
+		if "markdown" == payload.MsgType {
+			part.Markdown = &Markdown{Title: payload.Markdown.Title, Text: content}
+		} else {
+			part.Text = &Text{Content: content}
+		}
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// partitionMentions at section within MaxMentions and the targets left out, mobiles before user ids
+func (w *WebHook) partitionMentions(payload *PayLoad) (*At, []string) {
+	at := payload.At
+	if w.MaxMentions <= 0 || nil == at || len(at.AtMobiles)+len(at.AtUserIds) <= w.MaxMentions {
+		return at, nil
+	}
+	//  only text and markdown carry mentions
+	if nil == payload.Text && nil == payload.Markdown {
+		return at, nil
+	}
+
+	kept := *at
+	kept.AtMobiles, kept.AtUserIds = nil, nil
+	var dropped []string
+	for _, mobile := range at.AtMobiles {
+		if len(kept.AtMobiles) < w.MaxMentions {
+			kept.AtMobiles = append(kept.AtMobiles, mobile)
+		} else {
+			dropped = append(dropped, mobile)
+		}
+	}
+	for _, id := range at.AtUserIds {
+		if len(kept.AtMobiles)+len(kept.AtUserIds) < w.MaxMentions {
+			kept.AtUserIds = append(kept.AtUserIds, id)
+		} else {
+			dropped = append(dropped, id)
+		}
+	}
+
+	return &kept, dropped
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestMaxMentions(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.MaxMentions = 2
+
+	err := webHook.Send(&MarkdownMessage{Title: "t", Text: "disk full", AtMobiles: []string{"13800000001", "13800000002"}, AtUserIDs: []string{"u1", "u2"}})
+	if nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	webhooktest.AssertAtMobiles(t, requests[0], "13800000001", "13800000002")
+	webhooktest.AssertAtUserIDs(t, requests[0])
+	payload, _ := requests[0].Payload()
+	if "disk full\n\n@13800000001 @13800000002 and 2 others" != payload.Markdown.Text {
+		t.Errorf("overflow should be summarized, got %q", payload.Markdown.Text)
+	}
+
+	webHook.MentionOverflow = MentionSplit
+	err = webHook.SendTextMsg("disk full", false, "13800000001", "13800000002", "13800000003")
+	if nil != err {
+		t.Fatal(err)
+	}
+	requests = server.Requests()[1:]
+	if 2 != len(requests) {
+		t.Fatalf("rest should be mentioned in a follow-up, got %d requests", len(requests))
+	}
+	webhooktest.AssertAtMobiles(t, requests[0], "13800000001", "13800000002")
+	webhooktest.AssertAtMobiles(t, requests[1], "13800000003")
+	payload, _ = requests[1].Payload()
+	if "cc @13800000003" != payload.Text.Content {
+		t.Errorf("unexpected follow-up %q", payload.Text.Content)
+	}
+}
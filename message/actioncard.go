@@ -0,0 +1,71 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ActionCardBuilder `build an action card with a single jump or a list of buttons`
+type ActionCardBuilder struct {
+	msg ActionCardMessage
+}
+
+// NewActionCardBuilder `builder for a card titled title`
+func NewActionCardBuilder(title string) *ActionCardBuilder {
+	return &ActionCardBuilder{msg: ActionCardMessage{Title: title}}
+}
+
+// Text `markdown body of the card`
+func (b *ActionCardBuilder) Text(markdown string) *ActionCardBuilder {
+	b.msg.Text = markdown
+	return b
+}
+
+// Markdown `body built with a MarkdownBuilder, its title is ignored`
+func (b *ActionCardBuilder) Markdown(body *MarkdownBuilder) *ActionCardBuilder {
+	return b.Text(body.String())
+}
+
+// Single `make the whole card jump to url`
+func (b *ActionCardBuilder) Single(title, url string) *ActionCardBuilder {
+	b.msg.SingleTitle, b.msg.SingleURL = title, url
+	return b
+}
+
+// Button `append a button`
+func (b *ActionCardBuilder) Button(title, url string) *ActionCardBuilder {
+	b.msg.Buttons = append(b.msg.Buttons, Button{Title: title, ActionURL: url})
+	return b
+}
+
+// Horizontal `lay the buttons out side by side instead of stacked`
+func (b *ActionCardBuilder) Horizontal() *ActionCardBuilder {
+	b.msg.BtnOrientation = true
+	return b
+}
+
+// Build `validate and return the card`
+func (b *ActionCardBuilder) Build() (*ActionCardMessage, error) {
+	msg := b.msg
+	switch {
+	case "" == msg.Title:
+		return nil, errors.New("action card title is empty")
+	case "" == msg.Text:
+		return nil, errors.New("action card text is empty")
+	case "" != msg.SingleURL && 0 != len(msg.Buttons):
+		return nil, errors.New("action card can not have both a single jump and buttons")
+	case "" == msg.SingleURL && 0 == len(msg.Buttons):
+		return nil, errors.New("action card needs a single jump or at least one button")
+	case "" != msg.SingleURL && "" == msg.SingleTitle:
+		return nil, errors.New("action card single jump title is empty")
+	}
+
+	for i, button := range msg.Buttons {
+		if "" == button.Title || "" == button.ActionURL {
+			return nil, fmt.Errorf("action card button %d needs a title and an url", i)
+		}
+	}
+	msg.Buttons = append([]Button(nil), msg.Buttons...)
+
+	return &msg, nil
+}
@@ -0,0 +1,52 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestActionCardBuilder(t *testing.T) {
+	msg, err := NewActionCardBuilder("Release").
+		Markdown(NewMarkdownBuilder().H3("v1.2.3").Text("ready to ship")).
+		Button("Approve", "https://ci/approve").
+		Button("Reject", "https://ci/reject").
+		Horizontal().
+		Build()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	payload, _ := msg.Payload()
+	want := []Button{{Title: "Approve", ActionURL: "https://ci/approve"}, {Title: "Reject", ActionURL: "https://ci/reject"}}
+	if !reflect.DeepEqual(want, payload.ActionCard.Buttons) {
+		t.Errorf("unexpected buttons %v", payload.ActionCard.Buttons)
+	}
+	if "1" != payload.ActionCard.BtnOrientation || "### v1.2.3\n\nready to ship" != payload.ActionCard.Text {
+		t.Errorf("unexpected card %+v", payload.ActionCard)
+	}
+
+	msg, err = NewActionCardBuilder("Release").Text("notes").Single("Read more", "https://ci/notes").Build()
+	if nil != err {
+		t.Fatal(err)
+	}
+	payload, _ = msg.Payload()
+	if "https://ci/notes" != payload.ActionCard.SingleURL || 0 != len(payload.ActionCard.Buttons) {
+		t.Errorf("single jump card expected, got %+v", payload.ActionCard)
+	}
+}
+
+func TestActionCardBuilderValidation(t *testing.T) {
+	builders := map[string]*ActionCardBuilder{
+		"no title":   NewActionCardBuilder("").Text("x").Button("a", "https://a"),
+		"no text":    NewActionCardBuilder("t").Button("a", "https://a"),
+		"no action":  NewActionCardBuilder("t").Text("x"),
+		"both":       NewActionCardBuilder("t").Text("x").Single("a", "https://a").Button("b", "https://b"),
+		"bad button": NewActionCardBuilder("t").Text("x").Button("", "https://a"),
+		"no single":  NewActionCardBuilder("t").Text("x").Single("", "https://a"),
+	}
+	for name, builder := range builders {
+		if _, err := builder.Build(); nil == err {
+			t.Errorf("%s: validation error should be catch!", name)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package message
+
+// AtOption `one mention setting of a text or markdown message`
+type AtOption func(at *At)
+
+// AtAll `mention everyone in the group`
+func AtAll() AtOption {
+	return func(at *At) {
+		at.IsAtAll = true
+	}
+}
+
+// AtMobiles `mention members by mobile number`
+func AtMobiles(mobiles ...string) AtOption {
+	return func(at *At) {
+		at.AtMobiles = append(at.AtMobiles, mobiles...)
+	}
+}
+
+// AtUserIDs `mention members by DingTalk user id`
+func AtUserIDs(ids ...string) AtOption {
+	return func(at *At) {
+		at.AtUserIds = append(at.AtUserIds, ids...)
+	}
+}
+
+// ConfirmAtAll `mention everyone on robots that require a confirmation token, see At.Confirmation`
+func ConfirmAtAll(token string) AtOption {
+	return func(at *At) {
+		at.IsAtAll = true
+		at.confirm = token
+	}
+}
@@ -0,0 +1,32 @@
+package message
+
+// FeedCardBuilder `build a feed card, validated against the feedCard schema`
+type FeedCardBuilder struct {
+	links []LinkMsg
+}
+
+// NewFeedCardBuilder `empty builder`
+func NewFeedCardBuilder() *FeedCardBuilder {
+	return &FeedCardBuilder{}
+}
+
+// Link `append a link, picURL may be empty`
+func (b *FeedCardBuilder) Link(title, messageURL, picURL string) *FeedCardBuilder {
+	b.links = append(b.links, LinkMsg{Title: title, MessageURL: messageURL, PicURL: picURL})
+	return b
+}
+
+// Build `validate the links and return the card`
+//
+// DingTalk accepts 1 to 10 links, each with a title and an absolute messageUrl, violations are
+// returned together as a *ValidationError.
+func (b *FeedCardBuilder) Build() (*FeedCardMessage, error) {
+	msg := &FeedCardMessage{Links: append([]LinkMsg(nil), b.links...)}
+
+	payload, _ := msg.Payload()
+	if err := ValidatePayload(payload); nil != err {
+		return nil, err
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,38 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFeedCardBuilder(t *testing.T) {
+	msg, err := NewFeedCardBuilder().
+		Link("build #1", "https://ci/1", "https://ci/1.png").
+		Link("build #2", "dingtalk://dingtalkclient/page/link?url=x", "").
+		Build()
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 2 != len(msg.Links) || "https://ci/1.png" != msg.Links[0].PicURL {
+		t.Errorf("unexpected links %+v", msg.Links)
+	}
+
+	if _, err := NewFeedCardBuilder().Build(); nil == err {
+		t.Error("empty feed card error should be catch!")
+	}
+
+	builder := NewFeedCardBuilder()
+	for i := 0; i < 11; i++ {
+		builder.Link(fmt.Sprintf("build #%d", i), "https://ci", "")
+	}
+	if _, err := builder.Build(); nil == err || !strings.Contains(err.Error(), "at most 10") {
+		t.Errorf("too many links error should be catch!, got %v", err)
+	}
+
+	_, err = NewFeedCardBuilder().Link("", "ci/1", "not a url").Build()
+	validation, ok := err.(*ValidationError)
+	if !ok || 3 != len(validation.Violations) {
+		t.Errorf("missing title, relative url and bad picture should be reported, got %v", err)
+	}
+}
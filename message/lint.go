@@ -0,0 +1,79 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxListDepth `deepest list nesting DingTalk renders, deeper items are flattened`
+const MaxListDepth = 2
+
+// LintWarning `a markdown construct DingTalk ignores or renders badly`
+type LintWarning struct {
+	Line    int
+	Rule    string
+	Message string
+}
+
+// String `line prefixed warning`
+func (l LintWarning) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", l.Line, l.Message, l.Rule)
+}
+
+var (
+	lintListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d+\.)\s`)
+	lintTableRow  = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	lintTableRule = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	lintHTMLTag   = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9]*)\b[^>]*>`)
+	lintStrike    = regexp.MustCompile(`~~[^~]+~~`)
+)
+
+// html tags the DingTalk clients do render
+var lintAllowedTags = map[string]bool{"font": true, "br": true}
+
+// Lint `flag markdown DingTalk does not support, line numbers start at 1`
+func Lint(markdown string) []LintWarning {
+	var warnings []LintWarning
+	add := func(line int, rule, message string) {
+		warnings = append(warnings, LintWarning{Line: line, Rule: rule, Message: message})
+	}
+
+	inFence := false
+	for i, line := range strings.Split(markdown, "\n") {
+		n := i + 1
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if !inFence {
+				add(n, "code-block", "fenced code blocks are shown as plain text")
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if lintTableRule.MatchString(line) && strings.Contains(line, "|") || lintTableRow.MatchString(line) {
+			add(n, "table", "tables are not supported, use a list or key: value lines")
+		}
+
+		if m := lintListItem.FindStringSubmatch(line); nil != m {
+			indent := len(strings.Replace(m[1], "\t", "    ", -1))
+			if depth := indent/2 + 1; depth > MaxListDepth {
+				add(n, "list-depth", fmt.Sprintf("list nested %d levels deep, only %d are rendered", depth, MaxListDepth))
+			}
+		}
+
+		for _, tag := range lintHTMLTag.FindAllStringSubmatch(line, -1) {
+			if !lintAllowedTags[strings.ToLower(tag[1])] {
+				add(n, "html", fmt.Sprintf("html tag <%s> is shown verbatim", tag[1]))
+			}
+		}
+
+		if lintStrike.MatchString(line) {
+			add(n, "strikethrough", "strikethrough is not supported")
+		}
+	}
+
+	return warnings
+}
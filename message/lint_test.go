@@ -0,0 +1,41 @@
+package message
+
+import "testing"
+
+func TestLint(t *testing.T) {
+	markdown := "#### report\n" +
+		"- level 1\n" +
+		"  - level 2\n" +
+		"    - level 3\n" +
+		"| host | cpu |\n" +
+		"| --- | --- |\n" +
+		"<div>hi</div> <font color=#ff0000>red</font>\n" +
+		"```\n" +
+		"<b>inside code</b>\n" +
+		"```\n" +
+		"~~old~~"
+
+	want := []LintWarning{
+		{Line: 4, Rule: "list-depth"},
+		{Line: 5, Rule: "table"},
+		{Line: 6, Rule: "table"},
+		{Line: 7, Rule: "html"},
+		{Line: 7, Rule: "html"},
+		{Line: 8, Rule: "code-block"},
+		{Line: 11, Rule: "strikethrough"},
+	}
+
+	got := Lint(markdown)
+	if len(want) != len(got) {
+		t.Fatalf("expected %d warnings, got %v", len(want), got)
+	}
+	for i := range want {
+		if want[i].Line != got[i].Line || want[i].Rule != got[i].Rule {
+			t.Errorf("warning %d: want line %d %s, got %s", i, want[i].Line, want[i].Rule, got[i])
+		}
+	}
+
+	if warnings := Lint("#### ok\n> quote\n- item\n  - nested\n**bold** [link](https://x.y)"); 0 != len(warnings) {
+		t.Errorf("supported markdown should be clean, got %v", warnings)
+	}
+}
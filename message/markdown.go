@@ -0,0 +1,115 @@
+package message
+
+import (
+	"strings"
+)
+
+// MarkdownBuilder `build DingTalk markdown block by block`
+//
+// Blocks are separated by a blank line, which is what DingTalk needs to render a line break;
+// a single newline is only used between the items of one list.
+type MarkdownBuilder struct {
+	title  string
+	toc    bool
+	blocks []string
+}
+
+// NewMarkdownBuilder `empty builder`
+func NewMarkdownBuilder() *MarkdownBuilder {
+	return &MarkdownBuilder{}
+}
+
+// Title `message title shown in notifications, defaults to the first heading`
+func (b *MarkdownBuilder) Title(title string) *MarkdownBuilder {
+	b.title = title
+	return b
+}
+
+// TOC `prepend a summary of the sections when the message is built`
+func (b *MarkdownBuilder) TOC() *MarkdownBuilder {
+	b.toc = true
+	return b
+}
+
+// H1 `level 1 heading`
+func (b *MarkdownBuilder) H1(text string) *MarkdownBuilder {
+	return b.heading(1, text)
+}
+
+// H2 `level 2 heading`
+func (b *MarkdownBuilder) H2(text string) *MarkdownBuilder {
+	return b.heading(2, text)
+}
+
+// H3 `level 3 heading`
+func (b *MarkdownBuilder) H3(text string) *MarkdownBuilder {
+	return b.heading(3, text)
+}
+
+// H4 `level 4 heading`
+func (b *MarkdownBuilder) H4(text string) *MarkdownBuilder {
+	return b.heading(4, text)
+}
+
+// Text `plain paragraph`
+func (b *MarkdownBuilder) Text(text string) *MarkdownBuilder {
+	return b.add(text)
+}
+
+// Bold `bold paragraph`
+func (b *MarkdownBuilder) Bold(text string) *MarkdownBuilder {
+	return b.add("**" + text + "**")
+}
+
+// Quote `quoted paragraph, every line is quoted`
+func (b *MarkdownBuilder) Quote(text string) *MarkdownBuilder {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+
+	return b.add(strings.Join(lines, "\n"))
+}
+
+// Link `link paragraph`
+func (b *MarkdownBuilder) Link(text, url string) *MarkdownBuilder {
+	return b.add("[" + text + "](" + url + ")")
+}
+
+// Image `image paragraph`
+func (b *MarkdownBuilder) Image(alt, url string) *MarkdownBuilder {
+	return b.add("![" + alt + "](" + url + ")")
+}
+
+// Bullet `unordered list with one item per argument`
+func (b *MarkdownBuilder) Bullet(items ...string) *MarkdownBuilder {
+	if 0 == len(items) {
+		return b
+	}
+
+	return b.add("- " + strings.Join(items, "\n- "))
+}
+
+// String `markdown text built so far`
+func (b *MarkdownBuilder) String() string {
+	return strings.Join(b.blocks, "\n\n")
+}
+
+// Build `markdown message ready to send`
+func (b *MarkdownBuilder) Build() *MarkdownMessage {
+	return &MarkdownMessage{Title: b.title, Text: b.String(), TOC: b.toc}
+}
+
+func (b *MarkdownBuilder) heading(level int, text string) *MarkdownBuilder {
+	if "" == b.title {
+		b.title = text
+	}
+
+	return b.add(strings.Repeat("#", level) + " " + text)
+}
+
+// add append a block, trailing newlines would break the block separation
+func (b *MarkdownBuilder) add(block string) *MarkdownBuilder {
+	b.blocks = append(b.blocks, strings.TrimRight(block, "\n"))
+	return b
+}
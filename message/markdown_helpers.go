@@ -0,0 +1,101 @@
+package message
+
+import (
+	"strings"
+)
+
+// LineBreak `forced line break, DingTalk joins lines separated by a single newline`
+const LineBreak = "  \n"
+
+// Table `render rows DingTalk-style, as one list item per row`
+//
+// DingTalk has no tables, so the first column becomes the bold item title and the other
+// columns follow as "header: value", e.g. "- **api** · status: up · p95: 20ms".
+func Table(headers []string, rows [][]string) string {
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if 0 == len(row) {
+			continue
+		}
+
+		parts := []string{"**" + row[0] + "**"}
+		for i := 1; i < len(row); i++ {
+			if i < len(headers) && "" != headers[i] {
+				parts = append(parts, headers[i]+": "+row[i])
+			} else {
+				parts = append(parts, row[i])
+			}
+		}
+		lines = append(lines, "- "+strings.Join(parts, " · "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// CodeBlock `render code as a quote keeping its lines and indentation`
+//
+// Fenced blocks show up with their backticks in DingTalk, a quote with forced line breaks is the
+// closest it renders. lang is shown as a label when set.
+func CodeBlock(lang, code string) string {
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := strings.Replace(line[:len(line)-len(trimmed)], "\t", "    ", -1)
+		//  leading spaces are collapsed, non-breaking ones survive
+		lines[i] = "> " + strings.Repeat("\u00a0", len(indent)) + trimmed
+	}
+	if "" != lang {
+		lines = append([]string{"> *" + lang + "*"}, lines...)
+	}
+
+	return strings.Join(lines, LineBreak)
+}
+
+// Image `standalone image`
+func Image(url string) string {
+	return "![](" + url + ")"
+}
+
+// KVList `key: value lines, the shape DingTalk renders best for structured fields`
+type KVList struct {
+	lines []string
+}
+
+// NewKVList `empty list`
+func NewKVList() *KVList {
+	return &KVList{}
+}
+
+// Add `append a pair, empty values are shown as -`
+func (l *KVList) Add(key, value string) *KVList {
+	l.lines = append(l.lines, "- **"+key+"**: "+orDash(value))
+	return l
+}
+
+// String `markdown list`
+func (l *KVList) String() string {
+	return strings.Join(l.lines, "\n")
+}
+
+// Table `table block, see Table`
+func (b *MarkdownBuilder) Table(headers []string, rows [][]string) *MarkdownBuilder {
+	return b.add(Table(headers, rows))
+}
+
+// CodeBlock `code block, see CodeBlock`
+func (b *MarkdownBuilder) CodeBlock(lang, code string) *MarkdownBuilder {
+	return b.add(CodeBlock(lang, code))
+}
+
+// KV `key: value block`
+func (b *MarkdownBuilder) KV(list *KVList) *MarkdownBuilder {
+	return b.add(list.String())
+}
+
+func orDash(s string) string {
+	if "" == s {
+		return "-"
+	}
+
+	return s
+}
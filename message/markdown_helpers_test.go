@@ -0,0 +1,31 @@
+package message
+
+import "testing"
+
+func TestMarkdownHelpers(t *testing.T) {
+	table := Table([]string{"service", "status", "p95"}, [][]string{{"api", "up", "20ms"}, {}, {"db", "down"}})
+	if "- **api** · status: up · p95: 20ms\n- **db** · status: down" != table {
+		t.Errorf("unexpected table:\n%s", table)
+	}
+
+	code := CodeBlock("go", "if err != nil {\n\treturn err\n}\n")
+	if "> *go*  \n> if err != nil {  \n> \u00a0\u00a0\u00a0\u00a0return err  \n> }" != code {
+		t.Errorf("unexpected code block %q", code)
+	}
+
+	kv := NewKVList().Add("host", "web-1").Add("region", "").String()
+	if "- **host**: web-1\n- **region**: -" != kv {
+		t.Errorf("unexpected kv list:\n%s", kv)
+	}
+
+	text := NewMarkdownBuilder().
+		H3("Status").
+		Table([]string{"service", "status"}, [][]string{{"api", "up"}}).
+		CodeBlock("", "panic: boom").
+		KV(NewKVList().Add("host", "web-1")).
+		Text(Image("https://grafana/render/1.png")).
+		String()
+	if warnings := Lint(text); 0 != len(warnings) {
+		t.Errorf("helpers should produce lint-clean markdown, got %v", warnings)
+	}
+}
@@ -0,0 +1,34 @@
+package message
+
+import "testing"
+
+func TestMarkdownBuilder(t *testing.T) {
+	msg := NewMarkdownBuilder().
+		H3("Deploy finished").
+		Text("billing v1.2.3 is live\n").
+		Bold("no errors").
+		Quote("rolled out\nin 3 zones").
+		Bullet("zone a", "zone b").
+		Bullet().
+		Link("dashboard", "https://grafana/d/1").
+		Image("graph", "https://grafana/render/1.png").
+		Build()
+
+	want := "### Deploy finished\n\n" +
+		"billing v1.2.3 is live\n\n" +
+		"**no errors**\n\n" +
+		"> rolled out\n> in 3 zones\n\n" +
+		"- zone a\n- zone b\n\n" +
+		"[dashboard](https://grafana/d/1)\n\n" +
+		"![graph](https://grafana/render/1.png)"
+	if want != msg.Text {
+		t.Errorf("unexpected markdown:\n%s", msg.Text)
+	}
+	if "Deploy finished" != msg.Title {
+		t.Errorf("title should default to the first heading, got %q", msg.Title)
+	}
+
+	if title := NewMarkdownBuilder().Title("custom").H1("heading").Build().Title; "custom" != title {
+		t.Errorf("explicit title should win, got %q", title)
+	}
+}
@@ -0,0 +1,52 @@
+package message
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// mentionIndex position of the "@target" token in text at or after from, -1 when missing
+//
+// The token must end where the target does, so "@u1" is not found in "@u12".
+func mentionIndex(text, target string, from int) int {
+	token := "@" + target
+	for from <= len(text) {
+		i := strings.Index(text[from:], token)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		end := i + len(token)
+		if r, _ := utf8.DecodeRuneInString(text[end:]); end == len(text) || !(unicode.IsLetter(r) || unicode.IsDigit(r) || '_' == r) {
+			return i
+		}
+		from = i + 1
+	}
+
+	return -1
+}
+
+// HasMention `text contains the "@target" token`
+func HasMention(text, target string) bool {
+	return mentionIndex(text, target, 0) >= 0
+}
+
+// StripMentions `remove the "@target" tokens of targets from text, with the space before them`
+func StripMentions(text string, targets []string) string {
+	for _, target := range targets {
+		if "" == target {
+			continue
+		}
+		for i := mentionIndex(text, target, 0); i >= 0; i = mentionIndex(text, target, i) {
+			start, end := i, i+1+len(target)
+			if start > 0 && ' ' == text[start-1] {
+				start--
+			}
+			text = text[:start] + text[end:]
+			i = start
+		}
+	}
+
+	return strings.TrimRight(text, " ")
+}
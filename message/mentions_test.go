@@ -0,0 +1,27 @@
+package message
+
+import "testing"
+
+func TestHasMention(t *testing.T) {
+	if HasMention("ping @u12", "u1") {
+		t.Error("@u1 should not be found in @u12")
+	}
+	if !HasMention("ping @u12 and @u1, thanks", "u1") || !HasMention("ping @u1", "u1") {
+		t.Error("@u1 followed by punctuation or the end should be found")
+	}
+}
+
+func TestStripMentions(t *testing.T) {
+	if got := StripMentions("ping @u1 @u12 @u1, done", []string{"u1"}); "ping @u12, done" != got {
+		t.Errorf("only the @u1 tokens should be removed, got %q", got)
+	}
+	if got := StripMentions("ping @u12", []string{"u1"}); "ping @u12" != got {
+		t.Errorf("@u12 should be left alone, got %q", got)
+	}
+}
+
+func TestAppendMentionsBoundary(t *testing.T) {
+	if got := AppendMentions("ping @u12", &At{AtUserIds: []string{"u1"}}); "ping @u12\n\n@u1" != got {
+		t.Errorf("@u1 should be appended next to @u12, got %q", got)
+	}
+}
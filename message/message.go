@@ -0,0 +1,225 @@
+package message
+
+import (
+	"errors"
+	"strings"
+)
+
+// Message `anything that can be turned into a DingTalk payload`
+type Message interface {
+	Payload() (*PayLoad, error)
+}
+
+// Payload `implement Message, a raw payload is sent as is`
+func (p *PayLoad) Payload() (*PayLoad, error) {
+	return p, nil
+}
+
+// TextMessage `text message`
+type TextMessage struct {
+	Content   string
+	IsAtAll   bool
+	AtMobiles []string
+	//  the only way to mention members whose mobile is hidden
+	AtUserIDs []string
+	//  applied on top of the fields above
+	At []AtOption
+}
+
+// Payload `implement Message`
+func (m *TextMessage) Payload() (*PayLoad, error) {
+	at := newAt(m.IsAtAll, m.AtMobiles, m.AtUserIDs, m.At...)
+
+	return &PayLoad{
+		MsgType: "text",
+		Text:    &Text{Content: m.Content},
+		At:      at,
+	}, nil
+}
+
+// LinkMessage `link message`
+//
+// DingTalk ignores mentions on link messages, so At is rejected unless AtAsMarkdown sends the
+// link as a markdown message instead.
+type LinkMessage struct {
+	Title      string
+	Text       string
+	PicURL     string
+	MessageURL string
+	At         []AtOption
+	//  turn a link with mentions into markdown embedding the link
+	AtAsMarkdown bool
+}
+
+// Payload `implement Message`
+func (m *LinkMessage) Payload() (*PayLoad, error) {
+	if 0 != len(m.At) {
+		if !m.AtAsMarkdown {
+			return nil, &ValidationError{MsgType: "link", Violations: []string{"at: mentions are not supported by link, set AtAsMarkdown to send it as markdown"}}
+		}
+		return m.markdown().Payload()
+	}
+
+	return &PayLoad{
+		MsgType: "link",
+		Link:    &Link{Title: m.Title, Text: m.Text, PicURL: m.PicURL, MessageURL: m.MessageURL},
+	}, nil
+}
+
+// markdown the link rendered as a markdown message keeping its mentions
+func (m *LinkMessage) markdown() *MarkdownMessage {
+	body := NewMarkdownBuilder().Title(m.Title).H4("[" + m.Title + "](" + m.MessageURL + ")")
+	if "" != m.PicURL {
+		body.Image("", m.PicURL)
+	}
+	if "" != m.Text {
+		body.Text(m.Text)
+	}
+
+	msg := body.Build()
+	msg.At = m.At
+	return msg
+}
+
+// MarkdownMessage `markdown message`
+type MarkdownMessage struct {
+	Title     string
+	Text      string
+	IsAtAll   bool
+	AtMobiles []string
+	AtUserIDs []string
+	//  applied on top of the fields above
+	At []AtOption
+	//  prepend a TableOfContents summary, handy for long reports read on mobile
+	TOC bool
+}
+
+// Payload `implement Message`
+func (m *MarkdownMessage) Payload() (*PayLoad, error) {
+	text := m.Text
+	if m.TOC {
+		text = withTableOfContents(text)
+	}
+	at := newAt(m.IsAtAll, m.AtMobiles, m.AtUserIDs, m.At...)
+
+	return &PayLoad{
+		MsgType:  "markdown",
+		Markdown: &Markdown{Title: m.Title, Text: AppendMentions(text, at)},
+		At:       at,
+	}, nil
+}
+
+// ActionCardMessage `action card message, either a single jump or a list of buttons`
+//
+// LinkTitles and LinkURLs are the older parallel-slice form of Buttons and only used when
+// neither SingleURL nor Buttons is set.
+type ActionCardMessage struct {
+	Title          string
+	Text           string
+	SingleTitle    string
+	SingleURL      string
+	Buttons        []Button
+	LinkTitles     []string
+	LinkURLs       []string
+	HideAvatar     bool
+	BtnOrientation bool
+}
+
+// Payload `implement Message`
+func (m *ActionCardMessage) Payload() (*PayLoad, error) {
+	if "" != m.SingleURL || 0 != len(m.Buttons) {
+		card := &ActionCard{
+			Title:          m.Title,
+			Text:           m.Text,
+			SingleTitle:    m.SingleTitle,
+			SingleURL:      m.SingleURL,
+			BtnOrientation: orientation(m.BtnOrientation),
+			HideAvatar:     hideAvatar(m.HideAvatar),
+			Buttons:        m.Buttons,
+		}
+		return &PayLoad{MsgType: "actionCard", ActionCard: card}, nil
+	}
+
+	//  validation is empty
+	if 0 == len(m.LinkTitles) || 0 == len(m.LinkURLs) {
+		return nil, errors.New("links or titles is empty！")
+	}
+	//  validation is equal
+	if len(m.LinkURLs) != len(m.LinkTitles) {
+		return nil, errors.New("links length and titles length is not equal！")
+	}
+
+	card := &ActionCard{Title: m.Title, Text: m.Text}
+	//  hide robot avatar
+	card.HideAvatar = hideAvatar(m.HideAvatar)
+	//  button sort
+	card.BtnOrientation = orientation(m.BtnOrientation)
+	//  inject to button
+	for i := range m.LinkTitles {
+		card.Buttons = append(card.Buttons, Button{Title: m.LinkTitles[i], ActionURL: m.LinkURLs[i]})
+	}
+
+	return &PayLoad{MsgType: "actionCard", ActionCard: card}, nil
+}
+
+// FeedCardMessage `feed card message`
+type FeedCardMessage struct {
+	Links []LinkMsg
+}
+
+// Payload `implement Message`
+func (m *FeedCardMessage) Payload() (*PayLoad, error) {
+	return &PayLoad{MsgType: "feedCard", FeedCard: &FeedCard{Links: m.Links}}, nil
+}
+
+// AppendMentions `markdown only highlights members whose @ appears in the text, append the missing ones`
+func AppendMentions(text string, at *At) string {
+	if nil == at {
+		return text
+	}
+
+	var missing []string
+	for _, target := range append(append([]string(nil), at.AtMobiles...), at.AtUserIds...) {
+		if "" != target && !HasMention(text, target) {
+			missing = append(missing, "@"+target)
+		}
+	}
+	if 0 == len(missing) {
+		return text
+	}
+
+	return text + "\n\n" + strings.Join(missing, " ")
+}
+
+// orientation btnOrientation value, "1" lays the buttons out horizontally
+func orientation(horizontal bool) string {
+	if horizontal {
+		return "1"
+	}
+
+	return "0"
+}
+
+// hideAvatar hideAvatar value, "1" hides the robot avatar
+func hideAvatar(hide bool) string {
+	if hide {
+		return "1"
+	}
+
+	return "0"
+}
+
+// newAt at section, nil when nobody is mentioned
+//
+// The slices are copied, options appending to them must not write into the caller's arrays.
+func newAt(isAtAll bool, mobiles, userIDs []string, options ...AtOption) *At {
+	at := &At{AtMobiles: append([]string(nil), mobiles...), AtUserIds: append([]string(nil), userIDs...), IsAtAll: isAtAll}
+	for _, option := range options {
+		option(at)
+	}
+	if !at.IsAtAll && 0 == len(at.AtMobiles) && 0 == len(at.AtUserIds) {
+		return nil
+	}
+
+	return at
+}
@@ -0,0 +1,91 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAtOptions(t *testing.T) {
+	msg := &TextMessage{Content: "hi", AtMobiles: []string{"13800138000"}, At: []AtOption{AtMobiles("13900139000"), AtUserIDs("manager4711")}}
+	payload, _ := msg.Payload()
+
+	want := &At{AtMobiles: []string{"13800138000", "13900139000"}, AtUserIds: []string{"manager4711"}}
+	if !reflect.DeepEqual(want, payload.At) {
+		t.Errorf("options should add to the positional mentions, got %+v", payload.At)
+	}
+
+	payload, _ = (&MarkdownMessage{Title: "t", Text: "x"}).Payload()
+	if nil != payload.At {
+		t.Error("at section should be omitted when nobody is mentioned")
+	}
+}
+
+func TestMarkdownMentions(t *testing.T) {
+	msg := &MarkdownMessage{Title: "t", Text: "ping @13800138000", AtMobiles: []string{"13800138000"}, AtUserIDs: []string{"manager4711"}}
+	payload, _ := msg.Payload()
+
+	if "ping @13800138000\n\n@manager4711" != payload.Markdown.Text {
+		t.Errorf("missing mentions should be appended once, got %q", payload.Markdown.Text)
+	}
+	if "manager4711" != payload.At.AtUserIds[0] {
+		t.Errorf("user ids should be sent in the at section, got %+v", payload.At)
+	}
+
+	payload, _ = (&TextMessage{Content: "hi", AtUserIDs: []string{"manager4711"}}).Payload()
+	if "hi" != payload.Text.Content || "manager4711" != payload.At.AtUserIds[0] {
+		t.Errorf("text content should be left alone, got %+v", payload)
+	}
+}
+
+func TestLinkMessageAt(t *testing.T) {
+	msg := &LinkMessage{Title: "Build failed", Text: "billing #42", MessageURL: "https://ci/42", PicURL: "https://ci/42.png", At: []AtOption{AtMobiles("13800138000")}}
+	if _, err := msg.Payload(); nil == err {
+		t.Error("link with mentions error should be catch!")
+	}
+
+	msg.AtAsMarkdown = true
+	payload, err := msg.Payload()
+	if nil != err {
+		t.Fatal(err)
+	}
+	want := "#### [Build failed](https://ci/42)\n\n![](https://ci/42.png)\n\nbilling #42\n\n@13800138000"
+	if "markdown" != payload.MsgType || "Build failed" != payload.Markdown.Title || want != payload.Markdown.Text {
+		t.Errorf("link should be sent as markdown, got %+v", payload.Markdown)
+	}
+	if "13800138000" != payload.At.AtMobiles[0] {
+		t.Errorf("mentions should be kept, got %+v", payload.At)
+	}
+}
+
+func TestAtOptionsKeepFields(t *testing.T) {
+	mobiles := make([]string, 1, 4)
+	mobiles[0] = "13800138000"
+	msg := &TextMessage{Content: "hi", AtMobiles: mobiles, At: []AtOption{AtMobiles("13900139000")}}
+
+	first, _ := msg.Payload()
+	msg.At = []AtOption{AtMobiles("13700137000")}
+	_, _ = msg.Payload()
+
+	if "13900139000" != first.At.AtMobiles[1] {
+		t.Errorf("options should not write into the message fields, got %v", first.At.AtMobiles)
+	}
+	if 1 != len(msg.AtMobiles) {
+		t.Errorf("message mobiles should be left alone, got %v", msg.AtMobiles)
+	}
+}
+
+func TestActionCardHideAvatar(t *testing.T) {
+	for _, msg := range []*ActionCardMessage{
+		{Title: "t", Text: "x", SingleTitle: "open", SingleURL: "https://ci/42", HideAvatar: true},
+		{Title: "t", Text: "x", Buttons: []Button{{Title: "open", ActionURL: "https://ci/42"}}, HideAvatar: true},
+		{Title: "t", Text: "x", LinkTitles: []string{"open"}, LinkURLs: []string{"https://ci/42"}, HideAvatar: true},
+	} {
+		payload, err := msg.Payload()
+		if nil != err {
+			t.Fatal(err)
+		}
+		if "1" != payload.ActionCard.HideAvatar {
+			t.Errorf("hideAvatar should be carried by every form of the card, got %+v", payload.ActionCard)
+		}
+	}
+}
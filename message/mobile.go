@@ -0,0 +1,50 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	mainlandMobile      = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	internationalMobile = regexp.MustCompile(`^\+[1-9]\d{0,3}-?\d{4,14}$`)
+)
+
+// MobilePolicy `what happens to mentioned mobiles the robot's validator rejects`
+type MobilePolicy int
+
+// mobile policies, applied to the body and the at section alike
+const (
+	//  mention them anyway
+	MobileSendAnyway MobilePolicy = iota
+	//  leave them out and report them to the robot's callback
+	MobileSkip
+	//  fail the send with an *InvalidMobileError
+	MobileReject
+)
+
+// InvalidMobileError `mentioned mobiles rejected under MobileReject`
+type InvalidMobileError struct {
+	Mobiles []string
+}
+
+// Error `implement error interface`
+func (e *InvalidMobileError) Error() string {
+	return fmt.Sprintf("invalid mobiles mentioned: %s", strings.Join(e.Mobiles, ", "))
+}
+
+// IsMainlandMobile `11 digit mainland China mobile, e.g. 13800138000`
+func IsMainlandMobile(mobile string) bool {
+	return mainlandMobile.MatchString(mobile)
+}
+
+// IsInternationalMobile `mobile with a country code, e.g. +852-51234567 or +6581234567`
+func IsInternationalMobile(mobile string) bool {
+	return internationalMobile.MatchString(mobile)
+}
+
+// IsMobile `mainland or international mobile`
+func IsMobile(mobile string) bool {
+	return IsMainlandMobile(mobile) || IsInternationalMobile(mobile)
+}
@@ -0,0 +1,19 @@
+package message
+
+import "testing"
+
+func TestIsMobile(t *testing.T) {
+	valid := []string{"13800138000", "+852-51234567", "+6581234567", "+1-4155550123"}
+	invalid := []string{"", "12345", "23800138000", "138-0013-8000", "852-51234567", "+0-12345678"}
+
+	for _, mobile := range valid {
+		if !IsMobile(mobile) {
+			t.Errorf("%s should be valid", mobile)
+		}
+	}
+	for _, mobile := range invalid {
+		if IsMobile(mobile) {
+			t.Errorf("%s should be invalid", mobile)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Package message builds and validates DingTalk robot payloads.
+//
+// It has no dependency on the rest of the library, so services that only render messages, or
+// hand them to another process for delivery, do not pull in the transport and queue code.
+package message
+
+// LinkMsg `link message struct`
+type LinkMsg struct {
+	Title      string `json:"title"`
+	MessageURL string `json:"messageUrl"`
+	PicURL     string `json:"picUrl"`
+}
+
+// ActionCard `action card message struct`
+type ActionCard struct {
+	Text           string   `json:"text"`
+	Title          string   `json:"title"`
+	SingleTitle    string   `json:"singleTitle"`
+	SingleURL      string   `json:"singleUrl"`
+	BtnOrientation string   `json:"btnOrientation"`
+	HideAvatar     string   `json:"hideAvatar,omitempty"` //  deprecated by DingTalk, see the webhook package compat.go
+	Buttons        []Button `json:"btns"`
+}
+
+// Button `action card button`
+type Button struct {
+	Title     string `json:"title"`
+	ActionURL string `json:"actionUrl"`
+}
+
+// Text `text message section`
+type Text struct {
+	Content string `json:"content"`
+}
+
+// Link `link message section`
+type Link struct {
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	PicURL     string `json:"picURL"`
+	MessageURL string `json:"messageUrl"`
+}
+
+// Markdown `markdown message section`
+type Markdown struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// FeedCard `feed card message section`
+type FeedCard struct {
+	Links []LinkMsg `json:"links"`
+}
+
+// At `mentions of a text or markdown message`
+type At struct {
+	AtMobiles []string `json:"atMobiles"`
+	AtUserIds []string `json:"atUserIds,omitempty"`
+	IsAtAll   bool     `json:"isAtAll"`
+
+	//  token given to ConfirmAtAll
+	confirm string
+}
+
+// Confirmation `token given to ConfirmAtAll, empty when @all was not confirmed`
+func (a *At) Confirmation() string {
+	return a.confirm
+}
+
+// PayLoad payload, only the section matching MsgType is set and serialized
+type PayLoad struct {
+	MsgType    string      `json:"msgtype"`
+	Text       *Text       `json:"text,omitempty"`
+	Link       *Link       `json:"link,omitempty"`
+	Markdown   *Markdown   `json:"markdown,omitempty"`
+	ActionCard *ActionCard `json:"actionCard,omitempty"`
+	FeedCard   *FeedCard   `json:"feedCard,omitempty"`
+	At         *At         `json:"at,omitempty"`
+	//  json body posted as is instead of the sections above, see the webhook package SendRaw
+	Raw []byte `json:"-"`
+}
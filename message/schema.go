@@ -0,0 +1,217 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldSchema `description of one field inside a message section`
+type FieldSchema struct {
+	JSON     string        `json:"json"`
+	Required bool          `json:"required,omitempty"`
+	MaxLen   int           `json:"maxLen,omitempty"`
+	URL      bool          `json:"url,omitempty"`
+	Enum     []string      `json:"enum,omitempty"`
+	MinItems int           `json:"minItems,omitempty"`
+	MaxItems int           `json:"maxItems,omitempty"`
+	Items    []FieldSchema `json:"items,omitempty"` //  set for list fields
+}
+
+// MessageSchema `machine readable definition of a DingTalk msgtype`
+//
+// The payload section is always keyed by the msgtype itself, so adding a new kind of message is
+// a matter of appending a MessageSchema and the builder and validator below pick it up.
+type MessageSchema struct {
+	MsgType   string        `json:"msgtype"`
+	Fields    []FieldSchema `json:"fields"`
+	AtSupport bool          `json:"atSupport,omitempty"`
+}
+
+// Schemas `all message kinds known to this library`
+var Schemas = []MessageSchema{
+	{
+		MsgType:   "text",
+		AtSupport: true,
+		Fields: []FieldSchema{
+			{JSON: "content", Required: true, MaxLen: 20000},
+		},
+	},
+	{
+		MsgType: "link",
+		Fields: []FieldSchema{
+			{JSON: "title", Required: true},
+			{JSON: "text", Required: true},
+			{JSON: "messageUrl", Required: true, URL: true},
+			{JSON: "picURL", URL: true},
+		},
+	},
+	{
+		MsgType:   "markdown",
+		AtSupport: true,
+		Fields: []FieldSchema{
+			{JSON: "title", Required: true},
+			{JSON: "text", Required: true, MaxLen: 20000},
+		},
+	},
+	{
+		MsgType: "actionCard",
+		Fields: []FieldSchema{
+			{JSON: "title", Required: true},
+			{JSON: "text", Required: true, MaxLen: 20000},
+			{JSON: "singleTitle"},
+			{JSON: "singleUrl", URL: true},
+			{JSON: "btnOrientation", Enum: []string{"", "0", "1"}},
+			{JSON: "btns", Items: []FieldSchema{
+				{JSON: "title", Required: true},
+				{JSON: "actionUrl", Required: true, URL: true},
+			}},
+		},
+	},
+	{
+		MsgType: "feedCard",
+		Fields: []FieldSchema{
+			{JSON: "links", MinItems: 1, MaxItems: 10, Items: []FieldSchema{
+				{JSON: "title", Required: true},
+				{JSON: "messageUrl", Required: true, URL: true},
+				{JSON: "picUrl", URL: true},
+			}},
+		},
+	},
+}
+
+// SchemaFor `look up the schema of a msgtype`
+func SchemaFor(msgType string) (MessageSchema, bool) {
+	for _, schema := range Schemas {
+		if schema.MsgType == msgType {
+			return schema, true
+		}
+	}
+
+	return MessageSchema{}, false
+}
+
+// ValidationError `every schema violation found in a payload`
+type ValidationError struct {
+	MsgType    string
+	Violations []string
+}
+
+// Error `implement error interface`
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s message: %s", e.MsgType, strings.Join(e.Violations, "; "))
+}
+
+// BuildPayload `build a payload of any known msgtype from its section fields`
+func BuildPayload(msgType string, fields map[string]interface{}) (*PayLoad, error) {
+	bs, err := json.Marshal(map[string]interface{}{"msgtype": msgType, msgType: fields})
+	if nil != err {
+		return nil, err
+	}
+
+	var payload PayLoad
+	if err := json.Unmarshal(bs, &payload); nil != err {
+		return nil, err
+	}
+
+	if err := ValidatePayload(&payload); nil != err {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+// ValidatePayload `check the active section of a payload against its schema`
+func ValidatePayload(payload *PayLoad) error {
+	schema, ok := SchemaFor(payload.MsgType)
+	if !ok {
+		return fmt.Errorf("unknown msgtype: %q", payload.MsgType)
+	}
+
+	bs, err := json.Marshal(payload)
+	if nil != err {
+		return err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(bs, &doc); nil != err {
+		return err
+	}
+
+	section, _ := doc[schema.MsgType].(map[string]interface{})
+	violations := validateFields(schema.MsgType, schema.Fields, section)
+	if !schema.AtSupport && hasAt(payload) {
+		violations = append(violations, "at: mentions are not supported by "+schema.MsgType)
+	}
+
+	if 0 != len(violations) {
+		return &ValidationError{MsgType: schema.MsgType, Violations: violations}
+	}
+
+	return nil
+}
+
+// check one json object against field schemas
+func validateFields(path string, fields []FieldSchema, object map[string]interface{}) []string {
+	var violations []string
+	for _, field := range fields {
+		name := path + "." + field.JSON
+		value := object[field.JSON]
+
+		if nil != field.Items || 0 != field.MinItems || 0 != field.MaxItems {
+			items, _ := value.([]interface{})
+			if 0 != field.MinItems && len(items) < field.MinItems {
+				violations = append(violations, fmt.Sprintf("%s: at least %d items required", name, field.MinItems))
+			}
+			if 0 != field.MaxItems && len(items) > field.MaxItems {
+				violations = append(violations, fmt.Sprintf("%s: at most %d items allowed", name, field.MaxItems))
+			}
+			for i, item := range items {
+				object, _ := item.(map[string]interface{})
+				violations = append(violations, validateFields(fmt.Sprintf("%s[%d]", name, i), field.Items, object)...)
+			}
+			continue
+		}
+
+		str, _ := value.(string)
+		if "" == str {
+			if field.Required {
+				violations = append(violations, name+": required")
+			}
+			continue
+		}
+		if 0 != field.MaxLen && utf8.RuneCountInString(str) > field.MaxLen {
+			violations = append(violations, fmt.Sprintf("%s: longer than %d characters", name, field.MaxLen))
+		}
+		if field.URL && !isURL(str) {
+			violations = append(violations, name+": not an absolute url")
+		}
+		if nil != field.Enum && !inStrings(str, field.Enum) {
+			violations = append(violations, fmt.Sprintf("%s: must be one of %q", name, field.Enum))
+		}
+	}
+
+	return violations
+}
+
+// payload mentions anyone
+func hasAt(payload *PayLoad) bool {
+	return nil != payload.At && (payload.At.IsAtAll || 0 != len(payload.At.AtMobiles) || 0 != len(payload.At.AtUserIds))
+}
+
+// absolute url check, DingTalk also accepts its own dingtalk:// scheme
+func isURL(str string) bool {
+	u, err := url.Parse(str)
+	return nil == err && "" != u.Scheme && ("" != u.Host || "dingtalk" == u.Scheme)
+}
+
+func inStrings(str string, list []string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPayload(t *testing.T) {
+	payload, err := BuildPayload("markdown", map[string]interface{}{"title": "deploy", "text": "#### done"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "deploy" != payload.Markdown.Title || "markdown" != payload.MsgType {
+		t.Errorf("payload fields should be filled, got %+v", payload.Markdown)
+	}
+
+	_, err = BuildPayload("feedCard", map[string]interface{}{
+		"links": []map[string]string{{"title": "", "messageUrl": "not a url"}},
+	})
+	validation, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("validation error should be catch, got %v", err)
+	}
+	joined := strings.Join(validation.Violations, "\n")
+	if !strings.Contains(joined, "feedCard.links[0].title: required") || !strings.Contains(joined, "feedCard.links[0].messageUrl: not an absolute url") {
+		t.Errorf("unexpected violations: %v", validation.Violations)
+	}
+
+	if _, err := BuildPayload("voice", nil); nil == err {
+		t.Error("unknown msgtype error should be catch!")
+	}
+}
+
+func TestValidatePayloadAt(t *testing.T) {
+	payload := &PayLoad{MsgType: "link", Link: &Link{Title: "t", Text: "x", MessageURL: "https://ci/42"}, At: &At{IsAtAll: true}}
+	if err := ValidatePayload(payload); nil == err {
+		t.Error("link message with mentions should be rejected")
+	}
+}
@@ -0,0 +1,100 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tocSection one heading and the number of list items below it
+type tocSection struct {
+	title string
+	items int
+}
+
+// TableOfContents `summary of the sections of a long markdown report`
+//
+// Sections are the headings of the highest level used more than once, each listed with the
+// number of list items it contains, e.g. "- Errors (3)". Reports with fewer than two sections
+// get an empty summary.
+func TableOfContents(markdown string) string {
+	var headings [7][]tocSection
+	var current [7]int
+	fenced := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+
+		if level := headingLevel(trimmed); 0 != level {
+			headings[level] = append(headings[level], tocSection{title: strings.TrimSpace(trimmed[level:])})
+			current[level] = len(headings[level])
+			//  a new heading closes every deeper section
+			for deeper := level + 1; deeper < len(current); deeper++ {
+				current[deeper] = 0
+			}
+			continue
+		}
+
+		if isListItem(trimmed) {
+			for level := range current {
+				if 0 != current[level] {
+					headings[level][current[level]-1].items++
+				}
+			}
+		}
+	}
+
+	for _, sections := range headings {
+		if len(sections) < 2 {
+			continue
+		}
+
+		lines := make([]string, 0, len(sections))
+		for _, section := range sections {
+			lines = append(lines, fmt.Sprintf("- %s (%d)", section.title, section.items))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	return ""
+}
+
+// withTableOfContents prepend the summary, separated like any other markdown block
+func withTableOfContents(markdown string) string {
+	toc := TableOfContents(markdown)
+	if "" == toc {
+		return markdown
+	}
+
+	return toc + "\n\n" + markdown
+}
+
+// headingLevel 1-6 for atx headings, 0 otherwise
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && '#' == line[level] {
+		level++
+	}
+	if 0 == level || level > 6 || level == len(line) || ' ' != line[level] {
+		return 0
+	}
+
+	return level
+}
+
+func isListItem(line string) bool {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "+ ") {
+		return true
+	}
+
+	digits := 0
+	for digits < len(line) && '0' <= line[digits] && line[digits] <= '9' {
+		digits++
+	}
+	return 0 != digits && strings.HasPrefix(line[digits:], ". ")
+}
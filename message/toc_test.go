@@ -0,0 +1,32 @@
+package message
+
+import "testing"
+
+func TestTableOfContents(t *testing.T) {
+	report := "# Nightly report\n\n" +
+		"## Errors\n\n- db timeout\n- cache miss\n\n### Details\n\n1. first\n\n" +
+		"## Warnings\n\n```\n- not a list item\n## not a heading\n```\n\n" +
+		"## Passed\n\n* suite a\n+ suite b\n"
+
+	want := "- Errors (3)\n- Warnings (0)\n- Passed (2)"
+	if got := TableOfContents(report); want != got {
+		t.Errorf("unexpected summary:\n%s", got)
+	}
+
+	if "" != TableOfContents("# Title\n\n## Only section\n\n- item") {
+		t.Error("single section reports should get no summary")
+	}
+}
+
+func TestMarkdownMessageTOC(t *testing.T) {
+	msg := NewMarkdownBuilder().TOC().H2("Errors").Bullet("a").H2("Passed").Bullet("b", "c").Build()
+
+	payload, _ := msg.Payload()
+	want := "- Errors (1)\n- Passed (2)\n\n## Errors\n\n- a\n\n## Passed\n\n- b\n- c"
+	if want != payload.Markdown.Text {
+		t.Errorf("summary should be prepended, got:\n%s", payload.Markdown.Text)
+	}
+	if msg.Text == payload.Markdown.Text {
+		t.Error("message text should be left untouched")
+	}
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendMessages(t *testing.T) {
+	var got []PayLoad
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload PayLoad
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		got = append(got, payload)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	messages := []Message{
+		&TextMessage{Content: "hello", AtMobiles: []string{"13800138000"}},
+		&LinkMessage{Title: "link", Text: "text", MessageURL: "https://www.dingtalk.com"},
+		&MarkdownMessage{Title: "md", Text: "#### md", IsAtAll: true},
+		&ActionCardMessage{Title: "card", Text: "text", LinkTitles: []string{"open"}, LinkURLs: []string{"https://www.dingtalk.com"}, BtnOrientation: true},
+		&FeedCardMessage{Links: []LinkMsg{{Title: "feed", MessageURL: "https://www.dingtalk.com"}}},
+		knownGoodPayloads()["text"],
+	}
+	for _, msg := range messages {
+		if err := webHook.Send(msg); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	if 6 != len(got) {
+		t.Fatalf("6 payloads expected, got %d", len(got))
+	}
+	if "13800138000" != got[0].At.AtMobiles[0] || "link" != got[1].MsgType || !got[2].At.IsAtAll {
+		t.Errorf("unexpected payloads: %+v", got[:3])
+	}
+	if "1" != got[3].ActionCard.BtnOrientation || "https://www.dingtalk.com" != got[3].ActionCard.Buttons[0].ActionURL {
+		t.Errorf("unexpected action card: %+v", got[3].ActionCard)
+	}
+	if "feed" != got[4].FeedCard.Links[0].Title || "[self-test] text" != got[5].Text.Content {
+		t.Errorf("unexpected payloads: %+v", got[4:])
+	}
+
+	if err := webHook.Send(&ActionCardMessage{Title: "card", LinkTitles: []string{"a"}}); nil == err {
+		t.Error("invalid action card error should be catch!")
+	}
+}
@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Metrics `per-send observations, channel is WebHook.Name and never the token`
+type Metrics interface {
+	ObserveSend(channel, msgType string, latency time.Duration, err error)
+}
+
+// ChannelStats `counters of one channel`
+type ChannelStats struct {
+	Sent      int64
+	Failed    int64
+	Throttled int64
+	//  silenced or dropped by transform rules
+	Suppressed   int64
+	TotalLatency time.Duration
+	LastError    string
+	LastErrorAt  time.Time
+}
+
+// Stats `in-memory Metrics partitioned by channel`
+//
+// Channels and senders beyond MaxKeys are counted under OverflowKey, so a misbehaving caller
+// inventing names can not grow it without bound.
+type Stats struct {
+	//  distinct channels, and distinct senders, tracked; 0 uses DefaultStatsKeys
+	MaxKeys int
+
+	mu       sync.Mutex
+	channels map[string]*ChannelStats
+	senders  map[string]*ChannelStats
+	overflow int64
+}
+
+// Stats key limits
+const (
+	DefaultStatsKeys = 1000
+	OverflowKey      = "(other)"
+)
+
+// NewStats `empty stats`
+func NewStats() *Stats {
+	return &Stats{channels: make(map[string]*ChannelStats), senders: make(map[string]*ChannelStats)}
+}
+
+// ObserveSend `implement Metrics`
+func (s *Stats) ObserveSend(channel, msgType string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count(s.channels, channel, latency, err)
+}
+
+// ObserveSenderSend `implement SenderMetrics, sends are also counted per sender`
+func (s *Stats) ObserveSenderSend(sender SenderIdentity, channel, msgType string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count(s.channels, channel, latency, err)
+	if !sender.IsZero() {
+		s.count(s.senders, sender.String(), latency, err)
+	}
+}
+
+// Overflowed `observations counted under OverflowKey because MaxKeys was reached`
+func (s *Stats) Overflowed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.overflow
+}
+
+// count add one observation to the counters of key, caller holds the lock
+func (s *Stats) count(counters map[string]*ChannelStats, key string, latency time.Duration, err error) {
+	limit := s.MaxKeys
+	if limit <= 0 {
+		limit = DefaultStatsKeys
+	}
+
+	stats, ok := counters[key]
+	if !ok && len(counters) >= limit {
+		s.overflow++
+		key = OverflowKey
+		stats, ok = counters[key]
+	}
+	if !ok {
+		stats = &ChannelStats{}
+		counters[key] = stats
+	}
+
+	switch {
+	case nil == err:
+		stats.Sent++
+		stats.TotalLatency += latency
+		return
+	case ErrSilenced == err || ErrDropped == err:
+		stats.Suppressed++
+		return
+	case isThrottled(err):
+		stats.Throttled++
+	}
+
+	stats.Failed++
+	stats.LastError = err.Error()
+	stats.LastErrorAt = time.Now()
+}
+
+// Snapshot `copy of the counters keyed by channel`
+func (s *Stats) Snapshot() map[string]ChannelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return snapshot(s.channels)
+}
+
+// SenderSnapshot `copy of the counters keyed by sender identity`
+func (s *Stats) SenderSnapshot() map[string]ChannelStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return snapshot(s.senders)
+}
+
+func snapshot(counters map[string]*ChannelStats) map[string]ChannelStats {
+	copied := make(map[string]ChannelStats, len(counters))
+	for key, stats := range counters {
+		copied[key] = *stats
+	}
+
+	return copied
+}
+
+// isThrottled report whether DingTalk, or the local cool-down after it, rejected the send for going too fast
+func isThrottled(err error) bool {
+	if errors.Is(err, ErrTooFast) {
+		return true
+	}
+
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && 130101 == apiErr.Code
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsPartitionedByChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if "secret-token-b" == r.URL.Query().Get("access_token") {
+			_, _ = rw.Write([]byte(`{"errcode":130101,"errmsg":"send too fast"}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	stats := NewStats()
+	ops := NewWebHook("secret-token-a")
+	ops.APIURL, ops.Name, ops.Metrics = server.URL, "ops", stats
+	ci := NewWebHook("secret-token-b")
+	ci.APIURL, ci.Metrics = server.URL, stats
+
+	_ = ops.SendTextMsg("a", false)
+	_ = ops.SendTextMsg("b", false)
+	ops.Silence(MatcherFunc(func(*PayLoad) bool { return true }), time.Minute)
+	_ = ops.SendTextMsg("c", false)
+	_ = ci.SendTextMsg("d", false)
+
+	snapshot := stats.Snapshot()
+	if got := snapshot["ops"]; 2 != got.Sent || 1 != got.Suppressed || 0 != got.Failed {
+		t.Errorf("unexpected ops stats: %+v", got)
+	}
+	if got := snapshot["default"]; 1 != got.Failed || 1 != got.Throttled {
+		t.Errorf("unnamed webhook should be labelled default, got %+v", got)
+	}
+	for channel := range snapshot {
+		if strings.Contains(channel, "secret") {
+			t.Errorf("token leaked into channel label %q", channel)
+		}
+	}
+}
+
+func TestStatsMaxKeys(t *testing.T) {
+	stats := NewStats()
+	stats.MaxKeys = 2
+	for _, channel := range []string{"a", "b", "c", "d", "a"} {
+		stats.ObserveSend(channel, "text", time.Millisecond, nil)
+	}
+
+	snapshot := stats.Snapshot()
+	if 3 != len(snapshot) || 2 != snapshot["a"].Sent || 2 != snapshot[OverflowKey].Sent {
+		t.Errorf("channels beyond MaxKeys should be folded, got %+v", snapshot)
+	}
+	if 2 != stats.Overflowed() {
+		t.Errorf("2 overflowed observations expected, got %d", stats.Overflowed())
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	for _, err := range []error{
+		ErrTooFast,
+		&APIError{Code: 130101, Message: "send too fast"},
+		fmt.Errorf("ops: %w", &APIError{Code: 130101}),
+		&ProfileError{Channel: "ops", Err: ErrTooFast},
+	} {
+		if !isThrottled(err) {
+			t.Errorf("%v should count as throttled", err)
+		}
+	}
+	if isThrottled(&APIError{Code: 300001}) || isThrottled(nil) {
+		t.Error("other errors should not count as throttled")
+	}
+}
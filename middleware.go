@@ -0,0 +1,46 @@
+package webhook
+
+import "context"
+
+// Sender `sends one payload, what Middleware wraps`
+type Sender interface {
+	Send(ctx context.Context, payload *PayLoad) (*SendResult, error)
+}
+
+// SenderFunc `adapt a plain function to Sender`
+type SenderFunc func(ctx context.Context, payload *PayLoad) (*SendResult, error)
+
+// Send `implement Sender`
+func (f SenderFunc) Send(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+	return f(ctx, payload)
+}
+
+// Middleware `wrap a Sender, e.g. for logging, audit, rewriting or custom auth`
+//
+// Middleware sees the payload as built, before transform rules, keywords and the other
+// decorations of the WebHook, and the result and error the caller gets. It may change the
+// payload, short-circuit by not calling next, or call next more than once.
+type Middleware func(next Sender) Sender
+
+// Use `append middleware, the first one added runs outermost`
+func (w *WebHook) Use(middleware ...Middleware) *WebHook {
+	w.Middleware = append(w.Middleware, middleware...)
+	return w
+}
+
+// WithMiddleware `wrap every send in middleware`
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(w *WebHook) {
+		w.Use(middleware...)
+	}
+}
+
+// sender the send pipeline wrapped in Middleware
+func (w *WebHook) sender() Sender {
+	var sender Sender = SenderFunc(w.transmit)
+	for i := len(w.Middleware) - 1; i >= 0; i-- {
+		sender = w.Middleware[i](sender)
+	}
+
+	return sender
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestMiddleware(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Sender) Sender {
+			return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+				calls = append(calls, name+" before")
+				result, err := next.Send(ctx, payload)
+				calls = append(calls, name+" after")
+				return result, err
+			})
+		}
+	}
+	upper := func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+			rewriteContent(payload, strings.ToUpper)
+			return next.Send(ctx, payload)
+		})
+	}
+
+	webHook := NewWebHook("token", WithMiddleware(trace("outer")))
+	webHook.APIURL = server.URL
+	webHook.Use(trace("inner"), upper)
+
+	if _, err := webHook.SendWithResult(&TextMessage{Content: "hello"}); nil != err {
+		t.Fatal(err)
+	}
+	if "outer before,inner before,inner after,outer after" != strings.Join(calls, ",") {
+		t.Errorf("middleware should nest in order, got %v", calls)
+	}
+	if payload, _ := server.Requests()[0].Payload(); "HELLO" != payload.Text.Content {
+		t.Errorf("middleware should be able to rewrite, got %q", payload.Text.Content)
+	}
+
+	blocked := errors.New("blocked")
+	webHook.Use(func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+			return nil, blocked
+		})
+	})
+	if err := webHook.SendMarkdownMsg("t", "x", false); blocked != err || 1 != len(server.Requests()) {
+		t.Errorf("middleware should be able to short-circuit, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"strings"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// WithMobilePolicy `apply policy to mentioned mobiles validator rejects, a nil validator uses IsMobile`
+func WithMobilePolicy(policy MobilePolicy, validator func(mobile string) bool) Option {
+	return func(w *WebHook) {
+		w.InvalidMobilePolicy = policy
+		w.MobileValidator = validator
+	}
+}
+
+// validMobile apply MobileValidator, IsMobile when there is none
+func (w *WebHook) validMobile(mobile string) bool {
+	if nil == w.MobileValidator {
+		return IsMobile(mobile)
+	}
+
+	return w.MobileValidator(mobile)
+}
+
+// checkMobiles apply InvalidMobilePolicy to the mobiles mentioned by payload
+func (w *WebHook) checkMobiles(payload *PayLoad) error {
+	if MobileSendAnyway == w.InvalidMobilePolicy || nil == payload.At {
+		return nil
+	}
+
+	var valid, invalid []string
+	for _, mobile := range payload.At.AtMobiles {
+		if w.validMobile(mobile) {
+			valid = append(valid, mobile)
+		} else {
+			invalid = append(invalid, mobile)
+		}
+	}
+	if 0 == len(invalid) {
+		return nil
+	}
+
+	if MobileReject == w.InvalidMobilePolicy {
+		return &InvalidMobileError{Mobiles: invalid}
+	}
+	payload.At.AtMobiles = valid
+	rewriteContent(payload, func(content string) string {
+		//  the mention line may be left empty
+		return strings.TrimRight(message.StripMentions(content, invalid), "\n")
+	})
+	if nil != w.OnInvalidMobile {
+		for _, mobile := range invalid {
+			w.OnInvalidMobile(mobile)
+		}
+	}
+
+	return nil
+}
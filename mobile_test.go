@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestMobileValidator(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithMobilePolicy(MobileSkip, nil))
+	webHook.APIURL = server.URL
+	msg := &MarkdownMessage{Title: "t", Text: "ping", AtMobiles: []string{"+852-51234567", "12345"}}
+
+	_ = webHook.Send(msg)
+	webHook.MobileValidator = IsMainlandMobile
+	_ = webHook.Send(msg)
+	webHook.MobileValidator = func(string) bool { return true }
+	_ = webHook.Send(msg)
+
+	want := []string{"ping\n\n@+852-51234567", "ping", "ping\n\n@+852-51234567 @12345"}
+	for i, request := range server.Requests() {
+		if payload, _ := request.Payload(); want[i] != payload.Markdown.Text {
+			t.Errorf("send %d should mention %q, got %q", i, want[i], payload.Markdown.Text)
+		}
+	}
+}
+
+func TestInvalidMobilePolicy(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	if err := webHook.SendTextMsg("ping", false, "12345"); nil != err {
+		t.Fatal(err)
+	}
+	webhooktest.AssertAtMobiles(t, server.Requests()[0], "12345")
+
+	var skipped []string
+	webHook.InvalidMobilePolicy = MobileSkip
+	webHook.OnInvalidMobile = func(mobile string) { skipped = append(skipped, mobile) }
+	if err := webHook.SendTextMsg("ping", false, "13800138000", "12345"); nil != err {
+		t.Fatal(err)
+	}
+	webhooktest.AssertAtMobiles(t, server.Requests()[1], "13800138000")
+	if 1 != len(skipped) || "12345" != skipped[0] {
+		t.Errorf("invalid mobile should be reported, got %q", skipped)
+	}
+
+	webHook.InvalidMobilePolicy = MobileReject
+	err := webHook.Send(&MarkdownMessage{Title: "t", Text: "ping", At: []AtOption{AtMobiles("12345")}})
+	if invalid, ok := err.(*InvalidMobileError); !ok || "12345" != invalid.Mobiles[0] {
+		t.Errorf("invalid mobile error should be catch!, got %v", err)
+	}
+	if 2 != len(server.Requests()) {
+		t.Error("rejected message should not be sent")
+	}
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TargetError `the failure of one target of a fanout`
+type TargetError struct {
+	//  robot name, or message position for batches
+	Target string
+	Err    error
+}
+
+// Error `implement error interface`
+func (e *TargetError) Error() string {
+	return e.Target + ": " + e.Err.Error()
+}
+
+// Unwrap `expose the error of the target`
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError `every failure of a fanout, the targets not listed succeeded`
+//
+// errors.Is and errors.As match when any of the target errors matches, Unwrap() []error
+// exposes them to newer Go versions and other multi-error aware code as well.
+type MultiError struct {
+	Errors []*TargetError
+	//  targets attempted, failed or not
+	Total int
+}
+
+// Error `implement error interface`
+func (e *MultiError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		parts[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d of %d failed: %s", len(e.Errors), e.Total, strings.Join(parts, "; "))
+}
+
+// Unwrap `the target errors`
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// Is `any target error matches target`
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As `first target error assignable to target`
+func (e *MultiError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Failed `names of the failed targets, in order`
+func (e *MultiError) Failed() []string {
+	targets := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		targets[i] = err.Target
+	}
+
+	return targets
+}
+
+// Err `e, or nil when nothing failed`
+func (e *MultiError) Err() error {
+	if 0 == len(e.Errors) {
+		return nil
+	}
+
+	return e
+}
+
+// add record the failure of target, nil errors are ignored
+func (e *MultiError) add(target string, err error) {
+	e.Total++
+	if nil != err {
+		e.Errors = append(e.Errors, &TargetError{Target: target, Err: err})
+	}
+}
+
+// SendBatch `send independent messages, unlike a Chain a failure does not stop the rest`
+//
+// The error is a *MultiError naming the failed messages by position, e.g. "#2".
+func (w *WebHook) SendBatch(ctx context.Context, messages ...Message) error {
+	result := &MultiError{}
+	for i, msg := range messages {
+		result.add(fmt.Sprintf("#%d", i), w.SendContext(ctx, msg))
+	}
+
+	return result.Err()
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestMultiError(t *testing.T) {
+	tokenErr := &TokenError{Reason: "access token is empty"}
+	multi := &MultiError{}
+	multi.add("ops", nil)
+	multi.add("dev", &APIError{Code: 130101})
+	multi.add("qa", tokenErr)
+
+	var err error = multi
+	if !errors.Is(err, ErrTooFast) || !errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrSignMismatch) {
+		t.Error("errors.Is should look at every target")
+	}
+	var found *TokenError
+	if !errors.As(err, &found) || tokenErr != found {
+		t.Error("errors.As should find the token error")
+	}
+	if failed := multi.Failed(); 2 != len(failed) || "dev" != failed[0] || "qa" != failed[1] {
+		t.Errorf("failed targets should be listed in order, got %v", failed)
+	}
+	if 2 != len(multi.Unwrap()) || 3 != multi.Total {
+		t.Errorf("unexpected multi error %+v", multi)
+	}
+	if nil != (&MultiError{Total: 2}).Err() {
+		t.Error("multi error without failures should be nil")
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("ok", "send-too-fast")
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	err := webHook.SendBatch(context.Background(), &TextMessage{Content: "a"}, &TextMessage{Content: "b"}, &ActionCardMessage{}, &TextMessage{Content: "d"})
+	var multi *MultiError
+	if !errors.As(err, &multi) || 4 != multi.Total {
+		t.Fatalf("multi error should be catch! got %v", err)
+	}
+	if failed := multi.Failed(); 2 != len(failed) || "#1" != failed[0] || "#2" != failed[1] {
+		t.Errorf("second and third message should fail, got %v", failed)
+	}
+	if 3 != len(server.Requests()) {
+		t.Errorf("failures should not stop the batch, got %d requests", len(server.Requests()))
+	}
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import "github.com/lddsb/dingtalk-webhook/message"
+
+// LinkMsg `link of a feed card, see package message`
+type LinkMsg = message.LinkMsg
+
+// ActionCard `action card message section`
+type ActionCard = message.ActionCard
+
+// Button `action card button`
+type Button = message.Button
+
+// Text `text message section`
+type Text = message.Text
+
+// Link `link message section`
+type Link = message.Link
+
+// Markdown `markdown message section`
+type Markdown = message.Markdown
+
+// FeedCard `feed card message section`
+type FeedCard = message.FeedCard
+
+// At `mentions of a text or markdown message`
+type At = message.At
+
+// PayLoad `payload, only the section matching MsgType is set and serialized`
+type PayLoad = message.PayLoad
+
+// Message `anything that can be turned into a DingTalk payload`
+type Message = message.Message
+
+// TextMessage `text message`
+type TextMessage = message.TextMessage
+
+// LinkMessage `link message`
+type LinkMessage = message.LinkMessage
+
+// MarkdownMessage `markdown message`
+type MarkdownMessage = message.MarkdownMessage
+
+// ActionCardMessage `action card message, either a single jump or a list of buttons`
+type ActionCardMessage = message.ActionCardMessage
+
+// FeedCardMessage `feed card message`
+type FeedCardMessage = message.FeedCardMessage
+
+// AtOption `one mention setting of a text or markdown message`
+type AtOption = message.AtOption
+
+// AtAll `mention everyone in the group`
+func AtAll() AtOption {
+	return message.AtAll()
+}
+
+// AtMobiles `mention members by mobile number`
+func AtMobiles(mobiles ...string) AtOption {
+	return message.AtMobiles(mobiles...)
+}
+
+// AtUserIDs `mention members by DingTalk user id`
+func AtUserIDs(ids ...string) AtOption {
+	return message.AtUserIDs(ids...)
+}
+
+// MobilePolicy `what happens to mentioned mobiles WebHook.MobileValidator rejects`
+type MobilePolicy = message.MobilePolicy
+
+// mobile policies, see WebHook.InvalidMobilePolicy
+const (
+	MobileSkip       = message.MobileSkip
+	MobileReject     = message.MobileReject
+	MobileSendAnyway = message.MobileSendAnyway
+)
+
+// InvalidMobileError `mentioned mobiles rejected under MobileReject`
+type InvalidMobileError = message.InvalidMobileError
+
+// IsMainlandMobile `11 digit mainland China mobile, e.g. 13800138000`
+func IsMainlandMobile(mobile string) bool {
+	return message.IsMainlandMobile(mobile)
+}
+
+// IsInternationalMobile `mobile with a country code, e.g. +852-51234567 or +6581234567`
+func IsInternationalMobile(mobile string) bool {
+	return message.IsInternationalMobile(mobile)
+}
+
+// IsMobile `mainland or international mobile`
+func IsMobile(mobile string) bool {
+	return message.IsMobile(mobile)
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RobotPool `spread sends over several robots of a group, as DingTalk suggests to get past 20 per minute`
+//
+// Robots are used round robin, skipping the ones that already sent Limit messages within Period.
+// When all are saturated the send waits for the first free slot. A robot answering ErrTooFast
+// anyway is treated as saturated for a full Period and the message goes to the next one.
+type RobotPool struct {
+	//  sends per robot within Period, 0 uses HardLimit
+	Limit int
+	//  0 uses HardLimitPeriod
+	Period time.Duration
+
+	mu     sync.Mutex
+	robots []*pooledRobot
+	next   int
+}
+
+// pooledRobot a robot and the times of its recent sends
+type pooledRobot struct {
+	webHook *WebHook
+	sent    []time.Time
+}
+
+// RobotUsage `how much of its budget a pooled robot used`
+type RobotUsage struct {
+	Name string
+	//  sends within the last Period
+	Sent int
+}
+
+// NewRobotPool `pool of robots posting to the same group`
+func NewRobotPool(robots ...*WebHook) *RobotPool {
+	p := &RobotPool{}
+	for _, robot := range robots {
+		p.robots = append(p.robots, &pooledRobot{webHook: robot})
+	}
+
+	return p
+}
+
+// Send `send msg through the next robot with budget left`
+func (p *RobotPool) Send(msg Message) error {
+	_, err := p.SendWithResultContext(context.Background(), msg)
+	return err
+}
+
+// SendContext `Send bounded by ctx, including the wait for a free robot`
+func (p *RobotPool) SendContext(ctx context.Context, msg Message) error {
+	_, err := p.SendWithResultContext(ctx, msg)
+	return err
+}
+
+// SendWithResultContext `SendContext returning the result of the robot that sent`
+func (p *RobotPool) SendWithResultContext(ctx context.Context, msg Message) (*SendResult, error) {
+	if 0 == len(p.robots) {
+		return nil, errors.New("robot pool is empty")
+	}
+	payload, err := msg.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	var result *SendResult
+	for attempt := 0; attempt < len(p.robots); attempt++ {
+		robot, err := p.acquire(ctx)
+		if nil != err {
+			return nil, err
+		}
+
+		result, err = robot.webHook.SendWithResultContext(ctx, clonePayload(payload))
+		if !errors.Is(err, ErrTooFast) {
+			return result, err
+		}
+		p.saturate(robot)
+	}
+
+	return result, ErrTooFast
+}
+
+// Usage `budget used by every robot, in pool order`
+func (p *RobotPool) Usage() []RobotUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	usage := make([]RobotUsage, len(p.robots))
+	for i, robot := range p.robots {
+		p.expire(robot, now)
+		usage[i] = RobotUsage{Name: robot.webHook.channel(), Sent: len(robot.sent)}
+	}
+
+	return usage
+}
+
+// acquire reserve a send on the next robot with budget, waiting for one if needed
+func (p *RobotPool) acquire(ctx context.Context) (*pooledRobot, error) {
+	for {
+		p.mu.Lock()
+		now := time.Now()
+		var free time.Time
+		for i := range p.robots {
+			robot := p.robots[(p.next+i)%len(p.robots)]
+			p.expire(robot, now)
+			if len(robot.sent) < p.limit() {
+				robot.sent = append(robot.sent, now)
+				p.next = (p.next + i + 1) % len(p.robots)
+				p.mu.Unlock()
+				return robot, nil
+			}
+			if at := robot.sent[0].Add(p.period()); free.IsZero() || at.Before(free) {
+				free = at
+			}
+		}
+		p.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(free))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// saturate use up the budget of a robot DingTalk throttled
+func (p *RobotPool) saturate(robot *pooledRobot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	robot.sent = robot.sent[:0]
+	for i := 0; i < p.limit(); i++ {
+		robot.sent = append(robot.sent, now)
+	}
+}
+
+// expire forget sends older than Period
+func (p *RobotPool) expire(robot *pooledRobot, now time.Time) {
+	i := 0
+	for i < len(robot.sent) && now.Sub(robot.sent[i]) >= p.period() {
+		i++
+	}
+	robot.sent = robot.sent[i:]
+}
+
+func (p *RobotPool) limit() int {
+	if p.Limit < 1 {
+		return HardLimit
+	}
+
+	return p.Limit
+}
+
+func (p *RobotPool) period() time.Duration {
+	if 0 == p.Period {
+		return HardLimitPeriod
+	}
+
+	return p.Period
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRobotPool(t *testing.T) {
+	servers := make([]*webhooktest.Server, 3)
+	robots := make([]*WebHook, 3)
+	for i := range servers {
+		servers[i] = webhooktest.NewServer()
+		defer servers[i].Close()
+		robots[i] = NewWebHook("token")
+		robots[i].APIURL = servers[i].URL
+		robots[i].Name = string(rune('a' + i))
+	}
+	_ = servers[1].ReplyFixture("send-too-fast")
+
+	pool := NewRobotPool(robots...)
+	pool.Limit, pool.Period = 2, 50*time.Millisecond
+	for i := 0; i < 4; i++ {
+		if err := pool.Send(&TextMessage{Content: "hello"}); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	//  a, b throttled so c, a, c
+	if 2 != len(servers[0].Requests()) || 1 != len(servers[1].Requests()) || 2 != len(servers[2].Requests()) {
+		t.Errorf("sends should rotate and skip the throttled robot, got %d %d %d",
+			len(servers[0].Requests()), len(servers[1].Requests()), len(servers[2].Requests()))
+	}
+	usage := pool.Usage()
+	if "a" != usage[0].Name || 2 != usage[0].Sent || 2 != usage[1].Sent {
+		t.Errorf("usage should count sends and saturation, got %+v", usage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.SendContext(ctx, &TextMessage{Content: "hello"}); context.DeadlineExceeded != err {
+		t.Errorf("saturated pool should wait within ctx, got %v", err)
+	}
+
+	start := time.Now()
+	if err := pool.Send(&TextMessage{Content: "hello"}); nil != err {
+		t.Fatal(err)
+	}
+	if time.Since(start) > pool.Period {
+		t.Errorf("send should go out once a slot frees up, took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Preview `a message as it would be posted, for review tooling and golden tests`
+type Preview struct {
+	Payload  *PayLoad
+	JSON     []byte
+	Warnings []LintWarning
+}
+
+// Preview `run msg through everything send does up to the request, without sending`
+//
+// Transform rules, keywords, footers and anonymization of w are applied, silences, limits and
+// history are not. msg itself is left untouched.
+func (w *WebHook) Preview(msg Message) (*Preview, error) {
+	built, err := msg.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	payload := clonePayload(built)
+	if err := w.prepare(payload); nil != err {
+		return nil, err
+	}
+	applyCompat(payload)
+
+	bs, err := json.Marshal(payload)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Preview{Payload: payload, JSON: bs, Warnings: lintPayload(payload)}, nil
+}
+
+// clonePayload copy every section, so rewriting the copy leaves the original alone
+func clonePayload(payload *PayLoad) *PayLoad {
+	clone := *payload
+	if nil != payload.Text {
+		text := *payload.Text
+		clone.Text = &text
+	}
+	if nil != payload.Link {
+		link := *payload.Link
+		clone.Link = &link
+	}
+	if nil != payload.Markdown {
+		markdown := *payload.Markdown
+		clone.Markdown = &markdown
+	}
+	if nil != payload.ActionCard {
+		card := *payload.ActionCard
+		card.Buttons = append([]Button(nil), card.Buttons...)
+		clone.ActionCard = &card
+	}
+	if nil != payload.FeedCard {
+		clone.FeedCard = &FeedCard{Links: append([]LinkMsg(nil), payload.FeedCard.Links...)}
+	}
+	if nil != payload.At {
+		at := *payload.At
+		at.AtMobiles = append([]string(nil), at.AtMobiles...)
+		at.AtUserIds = append([]string(nil), at.AtUserIds...)
+		clone.At = &at
+	}
+
+	return &clone
+}
+
+var (
+	previewHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	previewList    = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s+(.*)$`)
+	previewImage   = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	previewLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	previewBold    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	previewItalic  = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	previewTag     = regexp.MustCompile(`&lt;(/?(?:font|br)\b.*?)&gt;`)
+	previewAnyTag  = regexp.MustCompile(`</?(?:font|br)\b[^>]*>`)
+)
+
+// HTML `html fragment approximating how the DingTalk client shows the message`
+func (p *Preview) HTML() string {
+	var b strings.Builder
+	payload := p.Payload
+	fmt.Fprintf(&b, "<div class=\"dingtalk-message dingtalk-%s\">\n", html.EscapeString(payload.MsgType))
+	switch {
+	case nil != payload.Text:
+		fmt.Fprintf(&b, "<p>%s</p>\n", strings.Replace(html.EscapeString(payload.Text.Content), "\n", "<br>", -1))
+	case nil != payload.Link:
+		fmt.Fprintf(&b, "<a href=\"%s\">\n", html.EscapeString(payload.Link.MessageURL))
+		fmt.Fprintf(&b, "<h4>%s</h4>\n<p>%s</p>\n", html.EscapeString(payload.Link.Title), html.EscapeString(payload.Link.Text))
+		if "" != payload.Link.PicURL {
+			fmt.Fprintf(&b, "<img src=\"%s\">\n", html.EscapeString(payload.Link.PicURL))
+		}
+		b.WriteString("</a>\n")
+	case nil != payload.Markdown:
+		b.WriteString(markdownHTML(payload.Markdown.Text))
+	case nil != payload.ActionCard:
+		card := payload.ActionCard
+		b.WriteString(markdownHTML(card.Text))
+		fmt.Fprintf(&b, "<div class=\"buttons orientation-%s\">\n", html.EscapeString(orientationName(card.BtnOrientation)))
+		if "" != card.SingleTitle {
+			fmt.Fprintf(&b, "<a class=\"button\" href=\"%s\">%s</a>\n", html.EscapeString(card.SingleURL), html.EscapeString(card.SingleTitle))
+		}
+		for _, button := range card.Buttons {
+			fmt.Fprintf(&b, "<a class=\"button\" href=\"%s\">%s</a>\n", html.EscapeString(button.ActionURL), html.EscapeString(button.Title))
+		}
+		b.WriteString("</div>\n")
+	case nil != payload.FeedCard:
+		b.WriteString("<ul>\n")
+		for _, link := range payload.FeedCard.Links {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">", html.EscapeString(link.MessageURL))
+			if "" != link.PicURL {
+				fmt.Fprintf(&b, "<img src=\"%s\"> ", html.EscapeString(link.PicURL))
+			}
+			fmt.Fprintf(&b, "%s</a></li>\n", html.EscapeString(link.Title))
+		}
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</div>\n")
+
+	return b.String()
+}
+
+// Terminal `plain text rendering for a terminal, bold and headings use ansi escapes`
+func (p *Preview) Terminal() string {
+	var lines []string
+	payload := p.Payload
+	switch {
+	case nil != payload.Text:
+		lines = append(lines, payload.Text.Content)
+	case nil != payload.Link:
+		lines = append(lines, ansiBold(payload.Link.Title), payload.Link.Text, "-> "+payload.Link.MessageURL)
+	case nil != payload.Markdown:
+		lines = append(lines, markdownTerminal(payload.Markdown.Text))
+	case nil != payload.ActionCard:
+		card := payload.ActionCard
+		lines = append(lines, markdownTerminal(card.Text), "")
+		var buttons []string
+		if "" != card.SingleTitle {
+			buttons = append(buttons, "[ "+card.SingleTitle+" ]")
+		}
+		for _, button := range card.Buttons {
+			buttons = append(buttons, "[ "+button.Title+" ]")
+		}
+		if "1" == card.BtnOrientation {
+			lines = append(lines, strings.Join(buttons, " "))
+		} else {
+			lines = append(lines, buttons...)
+		}
+	case nil != payload.FeedCard:
+		for _, link := range payload.FeedCard.Links {
+			lines = append(lines, "• "+link.Title+" -> "+link.MessageURL)
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// orientationName css friendly btnOrientation
+func orientationName(btnOrientation string) string {
+	if "1" == btnOrientation {
+		return "horizontal"
+	}
+
+	return "vertical"
+}
+
+// markdownHTML render the markdown subset DingTalk supports
+func markdownHTML(markdown string) string {
+	var b strings.Builder
+	var paragraph []string
+	inList := false
+	flush := func() {
+		if 0 != len(paragraph) {
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.Join(paragraph, "<br>"))
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case "" == trimmed:
+			flush()
+			closeList()
+		case previewHeading.MatchString(trimmed):
+			flush()
+			closeList()
+			m := previewHeading.FindStringSubmatch(trimmed)
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", len(m[1]), inlineHTML(m[2]), len(m[1]))
+		case previewList.MatchString(line):
+			flush()
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			fmt.Fprintf(&b, "<li>%s</li>\n", inlineHTML(previewList.FindStringSubmatch(line)[1]))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			closeList()
+			fmt.Fprintf(&b, "<blockquote>%s</blockquote>\n", inlineHTML(strings.TrimSpace(trimmed[1:])))
+		default:
+			closeList()
+			paragraph = append(paragraph, inlineHTML(line))
+		}
+	}
+	flush()
+	closeList()
+
+	return b.String()
+}
+
+// inlineHTML escape a line and render images, links, emphasis and the font tags DingTalk keeps
+func inlineHTML(line string) string {
+	line = html.EscapeString(line)
+	line = previewTag.ReplaceAllStringFunc(line, html.UnescapeString)
+	line = previewImage.ReplaceAllString(line, `<img alt="$1" src="$2">`)
+	line = previewLink.ReplaceAllString(line, `<a href="$2">$1</a>`)
+	line = previewBold.ReplaceAllString(line, `<strong>$1</strong>`)
+	return previewItalic.ReplaceAllString(line, `<em>$1</em>`)
+}
+
+// markdownTerminal render markdown as terminal text
+func markdownTerminal(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case previewHeading.MatchString(trimmed):
+			lines[i] = ansiBold(inlineTerminal(previewHeading.FindStringSubmatch(trimmed)[2]))
+		case previewList.MatchString(line):
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines[i] = indent + "• " + inlineTerminal(previewList.FindStringSubmatch(line)[1])
+		case strings.HasPrefix(trimmed, ">"):
+			lines[i] = "│ " + inlineTerminal(strings.TrimSpace(trimmed[1:]))
+		default:
+			lines[i] = inlineTerminal(line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// inlineTerminal drop markup the terminal cannot show
+func inlineTerminal(line string) string {
+	line = previewAnyTag.ReplaceAllString(line, "")
+	line = previewImage.ReplaceAllString(line, "[image: $1]")
+	line = previewLink.ReplaceAllString(line, "$1 ($2)")
+	return previewBold.ReplaceAllString(line, "\x1b[1m$1\x1b[0m")
+}
+
+func ansiBold(s string) string {
+	return "\x1b[1m" + s + "\x1b[0m"
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	webHook := NewWebHook("token")
+	webHook.Keywords = []string{"alert"}
+
+	msg := &TextMessage{Content: "disk full"}
+	preview, err := webHook.Preview(msg)
+	if nil != err {
+		t.Fatal(err)
+	}
+	if `{"msgtype":"text","text":{"content":"disk full\n\nalert"}}` != string(preview.JSON) {
+		t.Errorf("json should be the decorated payload, got %s", preview.JSON)
+	}
+	if "disk full\n\nalert\n" != preview.Terminal() {
+		t.Errorf("unexpected terminal rendering %q", preview.Terminal())
+	}
+
+	payload := &PayLoad{MsgType: "markdown", Markdown: &Markdown{Title: "t", Text: "# alert"}}
+	if _, err := webHook.Preview(payload); nil != err || "# alert" != payload.Markdown.Text {
+		t.Errorf("previewing should not touch the message, got %q %v", payload.Markdown.Text, err)
+	}
+
+	if _, err := webHook.Preview(&ActionCardMessage{}); nil == err {
+		t.Error("invalid message error should be catch!")
+	}
+}
+
+func TestPreviewGolden(t *testing.T) {
+	messages := map[string]Message{
+		"markdown": &MarkdownMessage{
+			Title: "deploy",
+			Text: "#### Deploy <finished>\n\n- **service**: api\n- [logs](https://example.com/logs)\n\n" +
+				"> rolled out by *ci*\n\n![chart](https://example.com/c.png)\n<font color=#999999>sent by ci</font>",
+		},
+		"actionCard": &ActionCardMessage{
+			Title:          "approve",
+			Text:           "### Release v1.2\n\nready to go",
+			LinkTitles:     []string{"Approve", "Reject"},
+			LinkURLs:       []string{"https://example.com/a", "https://example.com/r"},
+			BtnOrientation: true,
+		},
+	}
+
+	webHook := NewWebHook("token")
+	for name, msg := range messages {
+		preview, err := webHook.Preview(msg)
+		if nil != err {
+			t.Fatal(err)
+		}
+
+		if "markdown" == name && !strings.Contains(preview.HTML(), "&lt;finished&gt;") {
+			t.Error("html outside the font tags DingTalk keeps should be escaped")
+		}
+
+		for ext, got := range map[string]string{".html": preview.HTML(), ".txt": preview.Terminal()} {
+			path := filepath.Join("testdata", "preview", name+ext)
+			if *updateFixtures {
+				if err := ioutil.WriteFile(path, []byte(got), 0644); nil != err {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			want, err := ioutil.ReadFile(path)
+			if nil != err {
+				t.Fatalf("missing golden file, run go test -update: %v", err)
+			}
+			if !bytes.Equal(want, []byte(got)) {
+				t.Errorf("%s rendering changed\nwant: %s\ngot: %s", path, want, got)
+			}
+		}
+	}
+
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import "strings"
+
+// RobotProfile `who a robot's group belongs to, so the on-call knows whom to call when delivery fails`
+type RobotProfile struct {
+	Group      string `json:"group,omitempty"`
+	Owner      string `json:"owner,omitempty"`
+	Purpose    string `json:"purpose,omitempty"`
+	Escalation string `json:"escalation,omitempty"`
+}
+
+// IsZero `no profile configured`
+func (p RobotProfile) IsZero() bool {
+	return RobotProfile{} == p
+}
+
+// String `e.g. group ops-alerts, owner alice, escalation bob`
+func (p RobotProfile) String() string {
+	var parts []string
+	for _, field := range [][2]string{{"group", p.Group}, {"owner", p.Owner}, {"purpose", p.Purpose}, {"escalation", p.Escalation}} {
+		if "" != field[1] {
+			parts = append(parts, field[0]+" "+field[1])
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// WithProfile `describe the group the robot posts to`
+func WithProfile(profile RobotProfile) Option {
+	return func(w *WebHook) {
+		w.Profile = profile
+	}
+}
+
+// ProfileError `a failed send annotated with the profile of the robot`
+type ProfileError struct {
+	Channel string
+	Profile RobotProfile
+	Err     error
+}
+
+// Error `implement error interface`
+func (e *ProfileError) Error() string {
+	return e.Err.Error() + " [" + e.Channel + ": " + e.Profile.String() + "]"
+}
+
+// Unwrap `expose the send error`
+func (e *ProfileError) Unwrap() error {
+	return e.Err
+}
+
+// withProfile annotate err with the profile, silenced and dropped payloads were not failures
+func (w *WebHook) withProfile(err error) error {
+	if nil == err || w.Profile.IsZero() || ErrSilenced == err || ErrDropped == err {
+		return err
+	}
+
+	return &ProfileError{Channel: w.channel(), Profile: w.Profile, Err: err}
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRobotProfile(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	server.ReplyFixture("token-not-exist")
+
+	profile := RobotProfile{Group: "ops-alerts", Owner: "alice", Escalation: "bob"}
+	var failure Failure
+	webHook := NewWebHook("token", WithProfile(profile))
+	webHook.APIURL = server.URL
+	webHook.Name = "ops"
+	webHook.OnPermanentFailure = func(f Failure) {
+		failure = f
+	}
+
+	err := webHook.SendTextMsg("hello", false)
+	var profileErr *ProfileError
+	if !errors.As(err, &profileErr) || profile != profileErr.Profile || "ops" != profileErr.Channel {
+		t.Fatalf("profile error should be catch! got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Error("profile error should unwrap to the api error")
+	}
+	if !strings.HasSuffix(err.Error(), "[ops: group ops-alerts, owner alice, escalation bob]") {
+		t.Errorf("profile should be in the message, got %s", err)
+	}
+	if _, ok := failure.Err().(*TokenError); !ok || !strings.Contains(failure.Summary(), "owner alice") {
+		t.Errorf("failure should carry the bare error and the profile, got %s", failure.Summary())
+	}
+	if profile != webHook.DebugState()["profile"] {
+		t.Error("profile should be in the debug state")
+	}
+
+	webHook.Silence(MatcherFunc(func(*PayLoad) bool { return true }), time.Minute)
+	if err := webHook.SendTextMsg("hello", false); ErrSilenced != err {
+		t.Errorf("silenced sends are not failures, got %v", err)
+	}
+}
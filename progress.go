@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressReporter `report a long-running job at fixed milestones`
+//
+// Robot webhooks cannot edit a message once sent, so instead of updating one card the reporter
+// sends a new message each time a milestone is crossed, at most one per Update call.
+type ProgressReporter struct {
+	webHook    *WebHook
+	title      string
+	total      int64
+	start      time.Time
+	milestones []int
+
+	mu   sync.Mutex
+	next int
+}
+
+// DefaultMilestones `percentages announced by StartProgress`
+var DefaultMilestones = []int{25, 50, 75, 100}
+
+// StartProgress `announce a job of total units and return its reporter`
+func (w *WebHook) StartProgress(title string, total int64, milestones ...int) (*ProgressReporter, error) {
+	if total <= 0 {
+		return nil, fmt.Errorf("progress total must be positive, got %d", total)
+	}
+	if 0 == len(milestones) {
+		milestones = DefaultMilestones
+	}
+
+	p := &ProgressReporter{webHook: w, title: title, total: total, start: time.Now(), milestones: milestones}
+	return p, w.SendMarkdownMsg(title, fmt.Sprintf("#### %s\n\n%s 0%%\n\nstarted, %d items", title, progressBar(0), total), false)
+}
+
+// Update `record done units, a message is sent when a milestone is crossed`
+func (p *ProgressReporter) Update(done int64) error {
+	p.mu.Lock()
+	percent := int(done * 100 / p.total)
+	if percent > 100 {
+		percent = 100
+	}
+	crossed := false
+	for p.next < len(p.milestones) && percent >= p.milestones[p.next] {
+		p.next++
+		crossed = true
+	}
+	p.mu.Unlock()
+
+	if !crossed {
+		return nil
+	}
+
+	elapsed := time.Since(p.start)
+	status := "ETA " + estimateRemaining(elapsed, done, p.total).Round(time.Second).String()
+	if 100 == percent {
+		status = "done in " + elapsed.Round(time.Second).String()
+	}
+
+	text := fmt.Sprintf("#### %s\n\n%s %d%%\n\n%d/%d, %s", p.title, progressBar(percent), percent, done, p.total, status)
+	return p.webHook.SendMarkdownMsg(p.title, text, false)
+}
+
+// estimateRemaining linear extrapolation of the elapsed time
+func estimateRemaining(elapsed time.Duration, done, total int64) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
+
+// progressBar ten step bar
+func progressBar(percent int) string {
+	filled := percent / 10
+	return strings.Repeat("▓", filled) + strings.Repeat("░", 10-filled)
+}
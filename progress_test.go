@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload PayLoad
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		texts = append(texts, payload.Markdown.Text)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	if _, err := webHook.StartProgress("backfill", 0); nil == err {
+		t.Error("zero total error should be catch!")
+	}
+
+	progress, err := webHook.StartProgress("backfill", 200)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, done := range []int64{10, 49, 50, 60, 160, 200, 200} {
+		if err := progress.Update(done); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	//  start, 25%, 75% (crossing 50 and 75 at once), 100%
+	if 4 != len(texts) {
+		t.Fatalf("4 messages expected, got %d: %v", len(texts), texts)
+	}
+	if !strings.Contains(texts[1], "50/200") || !strings.Contains(texts[1], "ETA") {
+		t.Errorf("unexpected 25%% text: %s", texts[1])
+	}
+	if !strings.Contains(texts[2], "80%") || !strings.Contains(texts[3], "done in") {
+		t.Errorf("unexpected texts: %v", texts)
+	}
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	if got := estimateRemaining(time.Minute, 25, 100); 3*time.Minute != got {
+		t.Errorf("ETA should be 3m, got %s", got)
+	}
+	if 0 != estimateRemaining(time.Minute, 0, 100) {
+		t.Error("no ETA without progress")
+	}
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"net/url"
+
+	"github.com/lddsb/dingtalk-webhook/transport"
+)
+
+// WithoutProxy `connect to DingTalk directly, ignoring the proxy environment variables`
+func WithoutProxy() Option {
+	return func(w *WebHook) {
+		w.DisableProxy = true
+	}
+}
+
+// WithProxy `send through proxy whatever the environment says, see ParseProxy`
+func WithProxy(proxy *url.URL) Option {
+	return func(w *WebHook) {
+		w.Proxy = proxy
+	}
+}
+
+// ParseProxy `parse a proxy url, http, https, socks5 and socks5h are supported`
+func ParseProxy(raw string) (*url.URL, error) {
+	return transport.ParseProxy(raw)
+}
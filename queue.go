@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/queue"
+)
+
+// DefaultQueueInterval `how long QueuedWebHook.Run waits when the queue is empty or DingTalk is unreachable`
+const DefaultQueueInterval = 5 * time.Second
+
+// ErrNoDelayQueue `returned when scheduling on a Queue that is not a DelayQueue`
+var ErrNoDelayQueue = errors.New("queue cannot hold payloads back")
+
+// QueuedPayload `a payload stored in a Queue, see package queue`
+type QueuedPayload = queue.Item
+
+// Queue `store of payloads waiting to be sent`
+type Queue = queue.Queue
+
+// DelayQueue `Queue able to hold payloads back until a given time`
+type DelayQueue = queue.DelayQueue
+
+// MemoryQueue `DelayQueue held in memory, its payloads are lost with the process`
+type MemoryQueue = queue.MemoryQueue
+
+// NewMemoryQueue `empty queue`
+func NewMemoryQueue() *MemoryQueue {
+	return queue.NewMemoryQueue()
+}
+
+// FileQueue `DelayQueue kept in an append-only file, for a single process`
+type FileQueue = queue.FileQueue
+
+// OpenFileQueue `open or create the queue file at path`
+func OpenFileQueue(path string) (*FileQueue, error) {
+	return queue.OpenFileQueue(path)
+}
+
+// RedisQueue `DelayQueue in a redis list, shared by all replicas using the same key`
+type RedisQueue = queue.RedisQueue
+
+// RedisDoer `runs one redis command, adapt the redis client of your choice`
+type RedisDoer = queue.RedisDoer
+
+// RedisDoerFunc `adapt a plain function to RedisDoer`
+type RedisDoerFunc = queue.RedisDoerFunc
+
+// DefaultRedisLease `how long a replica may hold a claimed payload before others take it back`
+const DefaultRedisLease = queue.DefaultRedisLease
+
+// ErrNoEncryption `an encrypted record was read without an EncryptionProvider`
+var ErrNoEncryption = queue.ErrNoEncryption
+
+// OpenEncryptedFileQueue `OpenFileQueue keeping payloads encrypted by encryption on disk`
+func OpenEncryptedFileQueue(path string, encryption EncryptionProvider) (*FileQueue, error) {
+	return queue.OpenEncryptedFileQueue(path, encryption)
+}
+
+// NewRedisQueue `queue stored under key`
+func NewRedisQueue(redis RedisDoer, key string) *RedisQueue {
+	return queue.NewRedisQueue(redis, key)
+}
+
+// QueuedWebHook `send through a persistent Queue, so nothing is lost while DingTalk is down`
+//
+// Send only stores the payload. Run delivers the queue in order and keeps a payload queued as
+// long as DingTalk or the network is unavailable, so payloads left over by a previous process
+// are replayed first. A payload DingTalk rejects for good is reported to OnError and removed.
+type QueuedWebHook struct {
+	//  called with payloads removed without being sent
+	OnError func(item *QueuedPayload, err error)
+	//  pause of Run when the queue is empty or a send must be retried, 0 uses DefaultQueueInterval
+	Interval time.Duration
+
+	webHook *WebHook
+	queue   Queue
+}
+
+// NewQueuedWebHook `send through webHook, queueing in queue`
+func NewQueuedWebHook(webHook *WebHook, queue Queue) *QueuedWebHook {
+	return &QueuedWebHook{webHook: webHook, queue: queue}
+}
+
+// Send `queue msg for delivery`
+func (q *QueuedWebHook) Send(msg Message) error {
+	payload, err := msg.Payload()
+	if nil != err {
+		return err
+	}
+
+	return q.queue.Push(payload)
+}
+
+// SendAt `queue msg for delivery at t, the schedule survives restarts`
+//
+// The queue must be a DelayQueue. The message goes out with the first Drain after t, so Run
+// sends it up to Interval late. The returned id cancels it.
+func (q *QueuedWebHook) SendAt(t time.Time, msg Message) (string, error) {
+	queue, ok := q.queue.(DelayQueue)
+	if !ok {
+		return "", ErrNoDelayQueue
+	}
+
+	payload, err := msg.Payload()
+	if nil != err {
+		return "", err
+	}
+
+	return queue.PushAt(payload, t)
+}
+
+// SendAfter `queue msg for delivery once d has passed`
+func (q *QueuedWebHook) SendAfter(d time.Duration, msg Message) (string, error) {
+	return q.SendAt(time.Now().Add(d), msg)
+}
+
+// Cancel `drop a message queued with SendAt, false when it was already sent`
+func (q *QueuedWebHook) Cancel(id string) (bool, error) {
+	queue, ok := q.queue.(DelayQueue)
+	if !ok {
+		return false, ErrNoDelayQueue
+	}
+
+	return queue.Cancel(id)
+}
+
+// Drain `send queued payloads until the queue is empty or a send has to be retried later`
+//
+// The error that stopped the drain is returned, the payload concerned stays at the head of the queue.
+func (q *QueuedWebHook) Drain(ctx context.Context) error {
+	for {
+		item, err := q.queue.Claim()
+		if nil != err {
+			return err
+		}
+		if nil == item {
+			return nil
+		}
+
+		err = q.webHook.sendPayloadContext(ctx, item.Payload)
+		if retryLater(err) {
+			if releaseErr := q.queue.Release(item); nil != releaseErr {
+				return releaseErr
+			}
+			return err
+		}
+		if nil != err && nil != q.OnError {
+			q.OnError(item, err)
+		}
+		if err := q.queue.Ack(item); nil != err {
+			return err
+		}
+	}
+}
+
+// Run `drain the queue every Interval until ctx is done`
+func (q *QueuedWebHook) Run(ctx context.Context) error {
+	interval := q.Interval
+	if 0 == interval {
+		interval = DefaultQueueInterval
+	}
+
+	for {
+		if err := q.Drain(ctx); nil != err && nil == ctx.Err() {
+			q.webHook.infof("dingtalk %s: queue paused: %v", q.webHook.channel(), err)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// retryLater the payload was not delivered because DingTalk is unavailable, not because it is wrong
+func retryLater(err error) bool {
+	return IsTransient(err) ||
+		errors.Is(err, ErrTooFast) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
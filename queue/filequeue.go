@@ -0,0 +1,329 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// fileQueueCompactSize smallest file worth rewriting, below it settled records are left alone
+const fileQueueCompactSize = 64 << 10
+
+// fileQueueRecord one line of the queue file
+type fileQueueRecord struct {
+	Op       string           `json:"op"`
+	ID       string           `json:"id"`
+	Enqueued time.Time        `json:"enqueued,omitempty"`
+	Payload  *message.PayLoad `json:"payload,omitempty"`
+	//  encrypted payload json, instead of Payload when the queue has an Encryption
+	Sealed []byte `json:"sealed,omitempty"`
+	//  set by PushAt
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+}
+
+// FileQueue `DelayQueue kept in an append-only file, for a single process`
+//
+// Every push and ack is appended as a json line and synced before returning, opening the file
+// again replays the payloads that were never acked in their original order. The file is
+// rewritten once settled records take more room than the pending ones and it has grown past
+// 64 KiB.
+type FileQueue struct {
+	encryption Encryption
+
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	next    uint64
+	pending []*Item
+	claimed map[string]bool
+	//  bytes in the file and the bytes of each pending push record, the rest is settled
+	size    int64
+	records map[string]int64
+	live    int64
+}
+
+// OpenFileQueue `open or create the queue file at path`
+func OpenFileQueue(path string) (*FileQueue, error) {
+	return OpenEncryptedFileQueue(path, nil)
+}
+
+// OpenEncryptedFileQueue `OpenFileQueue keeping payloads encrypted by encryption on disk`
+//
+// Records written in the clear before encryption was turned on are still read.
+func OpenEncryptedFileQueue(path string, encryption Encryption) (*FileQueue, error) {
+	q := &FileQueue{path: path, claimed: make(map[string]bool), records: make(map[string]int64), encryption: encryption}
+	if err := q.replay(); nil != err {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if nil != err {
+		return nil, err
+	}
+	q.file = file
+
+	return q, nil
+}
+
+// replay rebuild the pending payloads from the file
+func (q *FileQueue) replay() error {
+	file, err := os.Open(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if nil != err {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for line := 1; ; line++ {
+		bs, err := reader.ReadBytes('\n')
+		if io.EOF == err {
+			if 0 == len(bs) {
+				return nil
+			}
+			//  a crash during a write leaves an unterminated last line, it was never acknowledged,
+			//  cut it off so the next record does not get appended onto it
+			return os.Truncate(q.path, offset)
+		}
+		if nil != err {
+			return err
+		}
+		offset += int64(len(bs))
+		q.size = offset
+
+		var record fileQueueRecord
+		if err := json.Unmarshal(bs, &record); nil != err {
+			return fmt.Errorf("%s:%d: %v", q.path, line, err)
+		}
+		if seq, err := strconv.ParseUint(record.ID, 10, 64); nil == err && seq >= q.next {
+			q.next = seq + 1
+		}
+		switch record.Op {
+		case "push":
+			if nil != record.Sealed {
+				if record.Payload, err = openPayload(q.encryption, record.Sealed); nil != err {
+					return fmt.Errorf("%s:%d: %v", q.path, line, err)
+				}
+			}
+			item := &Item{ID: record.ID, Payload: record.Payload, Enqueued: record.Enqueued}
+			if nil != record.NotBefore {
+				item.NotBefore = *record.NotBefore
+			}
+			q.pending = append(q.pending, item)
+			q.records[item.ID] = int64(len(bs))
+			q.live += int64(len(bs))
+		case "ack", "cancel":
+			q.remove(record.ID)
+		}
+	}
+}
+
+// Push `implement Queue`
+func (q *FileQueue) Push(payload *message.PayLoad) error {
+	_, err := q.PushAt(payload, time.Time{})
+	return err
+}
+
+// PushAt `implement DelayQueue`
+func (q *FileQueue) PushAt(payload *message.PayLoad, at time.Time) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item := &Item{ID: strconv.FormatUint(q.next, 10), Payload: payload, Enqueued: time.Now(), NotBefore: at}
+	record, err := q.pushRecord(item)
+	if nil != err {
+		return "", err
+	}
+	n, err := q.write(record)
+	if nil != err {
+		return "", err
+	}
+	q.next++
+	q.pending = append(q.pending, item)
+	q.records[item.ID] = n
+	q.live += n
+
+	return item.ID, nil
+}
+
+// Cancel `implement DelayQueue`
+func (q *FileQueue) Cancel(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.claimed[id] || !q.remove(id) {
+		return false, nil
+	}
+	if _, err := q.write(fileQueueRecord{Op: "cancel", ID: id}); nil != err {
+		return false, err
+	}
+
+	return true, q.maybeCompact()
+}
+
+// Claim `implement Queue`
+func (q *FileQueue) Claim() (*Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for _, item := range q.pending {
+		if !q.claimed[item.ID] && !now.Before(item.NotBefore) {
+			q.claimed[item.ID] = true
+			return item, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Ack `implement Queue`
+func (q *FileQueue) Ack(item *Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, err := q.write(fileQueueRecord{Op: "ack", ID: item.ID}); nil != err {
+		return err
+	}
+	delete(q.claimed, item.ID)
+	q.remove(item.ID)
+
+	return q.maybeCompact()
+}
+
+// Release `implement Queue`
+func (q *FileQueue) Release(item *Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.claimed, item.ID)
+	return nil
+}
+
+// Len `payloads not acked yet`
+func (q *FileQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+// Close `close the file, the queue survives for the next OpenFileQueue`
+func (q *FileQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.file.Close()
+}
+
+func (q *FileQueue) remove(id string) bool {
+	for i, item := range q.pending {
+		if item.ID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.live -= q.records[id]
+			delete(q.records, id)
+			return true
+		}
+	}
+
+	return false
+}
+
+// maybeCompact compact once the file is big and holds more settled than pending bytes
+func (q *FileQueue) maybeCompact() error {
+	if q.size >= fileQueueCompactSize && q.size-q.live > q.live {
+		return q.compact()
+	}
+
+	return nil
+}
+
+// pushRecord the record storing item, its payload sealed when the queue is encrypted
+func (q *FileQueue) pushRecord(item *Item) (fileQueueRecord, error) {
+	record := fileQueueRecord{Op: "push", ID: item.ID, Enqueued: item.Enqueued, Payload: item.Payload}
+	if !item.NotBefore.IsZero() {
+		notBefore := item.NotBefore
+		record.NotBefore = &notBefore
+	}
+	if nil != q.encryption {
+		sealed, err := sealPayload(q.encryption, item.Payload)
+		if nil != err {
+			return record, err
+		}
+		record.Payload, record.Sealed = nil, sealed
+	}
+
+	return record, nil
+}
+
+// write append a record and sync it to disk, n is the size of its line
+func (q *FileQueue) write(record fileQueueRecord) (n int64, err error) {
+	bs, err := json.Marshal(record)
+	if nil != err {
+		return 0, err
+	}
+	written, err := q.file.Write(append(bs, '\n'))
+	q.size += int64(written)
+	if nil != err {
+		return 0, err
+	}
+
+	return int64(written), q.file.Sync()
+}
+
+// compact rewrite the file with the pending payloads only
+func (q *FileQueue) compact() error {
+	tmp := q.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if nil != err {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	records := make(map[string]int64, len(q.pending))
+	var size int64
+	for _, item := range q.pending {
+		record, err := q.pushRecord(item)
+		if nil != err {
+			_ = file.Close()
+			return err
+		}
+		bs, _ := json.Marshal(record)
+		_, _ = writer.Write(append(bs, '\n'))
+		records[item.ID] = int64(len(bs) + 1)
+		size += int64(len(bs) + 1)
+	}
+	if err := writer.Flush(); nil != err {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); nil != err {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); nil != err {
+		return err
+	}
+	if err := os.Rename(tmp, q.path); nil != err {
+		return err
+	}
+
+	_ = q.file.Close()
+	q.file, err = os.OpenFile(q.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if nil != err {
+		return err
+	}
+	q.size, q.live, q.records = size, size, records
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package queue
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+func TestFileQueueReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox")
+
+	queue, err := OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"first", "second", "third"} {
+		if err := queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: content}}); nil != err {
+			t.Fatal(err)
+		}
+	}
+	item, _ := queue.Claim()
+	_ = queue.Ack(item)
+	//  claimed but never acked, e.g. the process died while sending
+	_, _ = queue.Claim()
+	_ = queue.Close()
+
+	//  torn write of a crashed process
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	_, _ = file.WriteString(`{"op":"push","id":"9","payl`)
+	_ = file.Close()
+
+	queue, err = OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	if 2 != queue.Len() {
+		t.Fatalf("2 payloads should be replayed, got %d", queue.Len())
+	}
+	for _, want := range []string{"second", "third"} {
+		item, _ := queue.Claim()
+		if nil == item || want != item.Payload.Text.Content {
+			t.Fatalf("replay should keep the order, want %s got %+v", want, item)
+		}
+		_ = queue.Ack(item)
+	}
+	if item, _ := queue.Claim(); nil != item {
+		t.Errorf("queue should be empty, got %+v", item)
+	}
+	if info, _ := os.Stat(path); 0 == info.Size() {
+		t.Error("small file should not be rewritten every time the queue drains")
+	}
+}
+
+func TestFileQueueCompact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox")
+
+	queue, err := OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	big := strings.Repeat("x", 4<<10)
+	for i := 0; i < 20; i++ {
+		_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: big}})
+	}
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "keep"}})
+
+	for i := 0; i < 5; i++ {
+		item, _ := queue.Claim()
+		_ = queue.Ack(item)
+	}
+	if data, _ := ioutil.ReadFile(path); 26 != strings.Count(string(data), "\n") {
+		t.Fatalf("file with more pending than settled bytes should be kept, got %d lines", strings.Count(string(data), "\n"))
+	}
+	for i := 0; i < 15; i++ {
+		item, _ := queue.Claim()
+		_ = queue.Ack(item)
+	}
+	if info, _ := os.Stat(path); info.Size() >= fileQueueCompactSize {
+		t.Errorf("mostly settled file should be rewritten, got %d bytes", info.Size())
+	}
+	if item, _ := queue.Claim(); nil == item || "keep" != item.Payload.Text.Content {
+		t.Errorf("pending payload should survive compaction, got %+v", item)
+	}
+}
+
+func TestFileQueueTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox")
+
+	queue, err := OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "first"}})
+	_ = queue.Close()
+
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	_, _ = file.WriteString(`{"op":"push","id":"1","payl`)
+	_ = file.Close()
+
+	if queue, err = OpenFileQueue(path); nil != err {
+		t.Fatal(err)
+	}
+	if err := queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "second"}}); nil != err {
+		t.Fatal(err)
+	}
+	_ = queue.Close()
+
+	queue, err = OpenFileQueue(path)
+	if nil != err {
+		t.Fatalf("queue should open again after a torn write, got %v", err)
+	}
+	defer queue.Close()
+	if 2 != queue.Len() {
+		t.Errorf("2 payloads should be replayed, got %d", queue.Len())
+	}
+}
+
+func TestFileQueueEncryption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox")
+	encryption := xorEncryption(42)
+
+	queue, err := OpenEncryptedFileQueue(path, encryption)
+	if nil != err {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"refund 41 failed", "refund 42 failed"} {
+		_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: content}})
+	}
+	item, _ := queue.Claim()
+	_ = queue.Ack(item)
+	//  rewrite the file with the pending payload only
+	_ = queue.compact()
+	_ = queue.Close()
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "refund") {
+		t.Errorf("payloads should be stored encrypted, got %s", data)
+	}
+
+	if _, err := OpenFileQueue(path); nil == err {
+		t.Error("missing encryption error should be catch!")
+	}
+	queue, err = OpenEncryptedFileQueue(path, encryption)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	if item, _ := queue.Claim(); nil == item || "refund 42 failed" != item.Payload.Text.Content {
+		t.Errorf("payload should be decrypted when replayed, got %+v", item)
+	}
+}
+
+func TestFileQueueRelease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	queue, err := OpenFileQueue(filepath.Join(dir, "outbox"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "first"}})
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "second"}})
+
+	first, _ := queue.Claim()
+	second, _ := queue.Claim()
+	if "second" != second.Payload.Text.Content {
+		t.Fatalf("claimed payloads should not be handed out twice, got %s", second.Payload.Text.Content)
+	}
+	_ = queue.Release(first)
+	if again, _ := queue.Claim(); again.ID != first.ID {
+		t.Errorf("released payload should be claimed next, got %s", again.ID)
+	}
+}
+
+func TestFileQueueDelay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox")
+
+	queue, err := OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	at := time.Now().Add(time.Hour)
+	maintenance, _ := queue.PushAt(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "maintenance"}}, at)
+	_, _ = queue.PushAt(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "reminder"}}, at)
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "now"}})
+	if item, _ := queue.Claim(); nil == item || "now" != item.Payload.Text.Content {
+		t.Fatalf("payloads due later should not hold the queue up, got %+v", item)
+	}
+	if ok, _ := queue.Cancel(maintenance); !ok {
+		t.Error("pending payload should be cancelled")
+	}
+	_ = queue.Close()
+
+	queue, err = OpenFileQueue(path)
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	if 2 != queue.Len() {
+		t.Fatalf("cancelled payload should not be replayed, got %d", queue.Len())
+	}
+	if item, _ := queue.Claim(); nil == item || "now" != item.Payload.Text.Content {
+		t.Errorf("only the undelayed payload should be claimable, got %+v", item)
+	}
+	if item, _ := queue.Claim(); nil != item {
+		t.Errorf("schedule should survive the restart, got %+v", item)
+	}
+}
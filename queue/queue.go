@@ -0,0 +1,183 @@
+// Package queue stores DingTalk payloads waiting to be sent.
+//
+// The queues only hold payloads, delivering them is up to the caller, e.g. the
+// QueuedWebHook of the webhook package.
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// ErrNoEncryption `an encrypted record was read without an Encryption`
+var ErrNoEncryption = errors.New("record is encrypted but no encryption provider is configured")
+
+// Item `a payload stored in a Queue`
+type Item struct {
+	ID       string
+	Payload  *message.PayLoad
+	Enqueued time.Time
+	//  zero unless pushed with DelayQueue.PushAt
+	NotBefore time.Time
+}
+
+// Queue `store of payloads waiting to be sent`
+//
+// A payload is claimed before it is sent and acked once it was, a claimed payload that is
+// released is handed out again, so delivery is at least once.
+type Queue interface {
+	//  append payload at the tail
+	Push(payload *message.PayLoad) error
+	//  claim the oldest unclaimed payload, nil when there is none
+	Claim() (*Item, error)
+	//  remove a claimed payload for good
+	Ack(item *Item) error
+	//  hand a claimed payload back, it is the next one claimed
+	Release(item *Item) error
+}
+
+// DelayQueue `Queue able to hold payloads back until a given time`
+type DelayQueue interface {
+	Queue
+	//  queue payload so it is not claimed before at, the id identifies it for Cancel
+	PushAt(payload *message.PayLoad, at time.Time) (string, error)
+	//  remove a payload pushed with PushAt before it is claimed, false when it is gone already
+	Cancel(id string) (bool, error)
+}
+
+// Encryption `encrypt payloads kept at rest, e.g. the webhook package AESGCM`
+type Encryption interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// sealPayload json of payload encrypted by encryption
+func sealPayload(encryption Encryption, payload *message.PayLoad) ([]byte, error) {
+	bs, err := json.Marshal(payload)
+	if nil != err {
+		return nil, err
+	}
+
+	return encryption.Encrypt(bs)
+}
+
+// openPayload undo sealPayload
+func openPayload(encryption Encryption, sealed []byte) (*message.PayLoad, error) {
+	if nil == encryption {
+		return nil, ErrNoEncryption
+	}
+
+	bs, err := encryption.Decrypt(sealed)
+	if nil != err {
+		return nil, err
+	}
+	var payload message.PayLoad
+	if err := json.Unmarshal(bs, &payload); nil != err {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+// MemoryQueue `DelayQueue held in memory, its payloads are lost with the process`
+type MemoryQueue struct {
+	mu      sync.Mutex
+	pending []*Item
+	claimed map[string]bool
+	next    uint64
+}
+
+// NewMemoryQueue `empty queue`
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{claimed: make(map[string]bool)}
+}
+
+// Push `implement Queue`
+func (q *MemoryQueue) Push(payload *message.PayLoad) error {
+	_, err := q.PushAt(payload, time.Time{})
+	return err
+}
+
+// PushAt `implement DelayQueue`
+func (q *MemoryQueue) PushAt(payload *message.PayLoad, at time.Time) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item := &Item{ID: strconv.FormatUint(q.next, 10), Payload: payload, Enqueued: time.Now(), NotBefore: at}
+	q.next++
+	q.pending = append(q.pending, item)
+
+	return item.ID, nil
+}
+
+// Cancel `implement DelayQueue`
+func (q *MemoryQueue) Cancel(id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.claimed[id] {
+		return false, nil
+	}
+
+	return q.remove(id), nil
+}
+
+// Claim `implement Queue`
+func (q *MemoryQueue) Claim() (*Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for _, item := range q.pending {
+		if !q.claimed[item.ID] && !now.Before(item.NotBefore) {
+			q.claimed[item.ID] = true
+			return item, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Ack `implement Queue`
+func (q *MemoryQueue) Ack(item *Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.claimed, item.ID)
+	q.remove(item.ID)
+
+	return nil
+}
+
+// Release `implement Queue`
+func (q *MemoryQueue) Release(item *Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.claimed, item.ID)
+	return nil
+}
+
+// Len `payloads not acked yet`
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.pending)
+}
+
+func (q *MemoryQueue) remove(id string) bool {
+	for i, item := range q.pending {
+		if item.ID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// xorEncryption reversible stand-in for a real cipher
+type xorEncryption byte
+
+func (e xorEncryption) Encrypt(plaintext []byte) ([]byte, error) {
+	out := make([]byte, len(plaintext))
+	for i, b := range plaintext {
+		out[i] = b ^ byte(e)
+	}
+
+	return out, nil
+}
+
+func (e xorEncryption) Decrypt(ciphertext []byte) ([]byte, error) {
+	if 0 == len(ciphertext) {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return e.Encrypt(ciphertext)
+}
+
+func TestSealPayload(t *testing.T) {
+	sealed, err := sealPayload(xorEncryption(42), &message.PayLoad{MsgType: "text", Text: &message.Text{Content: "hi"}})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	payload, err := openPayload(xorEncryption(42), sealed)
+	if nil != err || "hi" != payload.Text.Content {
+		t.Errorf("payload should survive sealing, got %+v, %v", payload, err)
+	}
+	if _, err := openPayload(nil, sealed); ErrNoEncryption != err {
+		t.Errorf("missing encryption error should be catch! got %v", err)
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	q := NewMemoryQueue()
+	for _, content := range []string{"first", "second"} {
+		if err := q.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: content}}); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	first, _ := q.Claim()
+	second, _ := q.Claim()
+	if "first" != first.Payload.Text.Content || "second" != second.Payload.Text.Content {
+		t.Fatalf("payloads should be claimed in order, got %+v %+v", first.Payload.Text, second.Payload.Text)
+	}
+	if item, _ := q.Claim(); nil != item {
+		t.Errorf("claimed payloads should not be handed out twice, got %+v", item)
+	}
+
+	_ = q.Release(first)
+	_ = q.Ack(second)
+	if item, _ := q.Claim(); nil == item || first.ID != item.ID {
+		t.Errorf("released payload should be claimed again, got %+v", item)
+	}
+	if 1 != q.Len() {
+		t.Errorf("acked payload should be removed, %d left", q.Len())
+	}
+}
+
+func TestMemoryQueueDelay(t *testing.T) {
+	q := NewMemoryQueue()
+	later, _ := q.PushAt(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "later"}}, time.Now().Add(time.Hour))
+	_ = q.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "now"}})
+
+	item, _ := q.Claim()
+	if nil == item || "now" != item.Payload.Text.Content {
+		t.Fatalf("delayed payload should be skipped, got %+v", item)
+	}
+	if item, _ := q.Claim(); nil != item {
+		t.Errorf("delayed payload should not be claimed before its time, got %+v", item)
+	}
+	if ok, _ := q.Cancel(later); !ok || 1 != q.Len() {
+		t.Errorf("delayed payload should be cancelled, %d left", q.Len())
+	}
+	if ok, _ := q.Cancel(item.ID); ok {
+		t.Error("claimed payload should not be cancelled")
+	}
+}
@@ -0,0 +1,285 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// DefaultRedisLease `how long a replica may hold a claimed payload before others take it back`
+const DefaultRedisLease = time.Minute
+
+// RedisDoer `runs one redis command, adapt the redis client of your choice`
+//
+// With go-redis:
+//
+//	queue.RedisDoerFunc(func(args ...interface{}) (interface{}, error) {
+//		reply, err := rdb.Do(ctx, args...).Result()
+//		if redis.Nil == err {
+//			return nil, nil
+//		}
+//		return reply, err
+//	})
+//
+// A nil reply must be returned as (nil, nil), bulk strings as string or []byte.
+type RedisDoer interface {
+	Do(args ...interface{}) (interface{}, error)
+}
+
+// RedisDoerFunc `adapt a plain function to RedisDoer`
+type RedisDoerFunc func(args ...interface{}) (interface{}, error)
+
+// Do `implement RedisDoer`
+func (f RedisDoerFunc) Do(args ...interface{}) (interface{}, error) {
+	return f(args...)
+}
+
+// claim queue the delayed payloads that are due, then pop the oldest payload into the processing list and lease it
+const redisClaimScript = `
+for _, raw in ipairs(redis.call('ZRANGEBYSCORE', KEYS[4], '-inf', ARGV[2])) do
+	redis.call('ZREM', KEYS[4], raw)
+	redis.call('LPUSH', KEYS[1], raw)
+end
+local raw = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+if raw then
+	redis.call('SET', KEYS[3] .. cjson.decode(raw).id, 1, 'PX', ARGV[1])
+end
+return raw`
+
+// ack drop a payload from the processing list
+const redisAckScript = `
+redis.call('LREM', KEYS[2], 1, ARGV[1])
+redis.call('DEL', KEYS[3] .. cjson.decode(ARGV[1]).id)
+return 1`
+
+// release move a payload back to the head of the queue
+const redisReleaseScript = `
+if 0 < redis.call('LREM', KEYS[2], 1, ARGV[1]) then
+	redis.call('RPUSH', KEYS[1], ARGV[1])
+end
+redis.call('DEL', KEYS[3] .. cjson.decode(ARGV[1]).id)
+return 1`
+
+// recover release payloads whose lease ran out, their replica is gone
+const redisRecoverScript = `
+local moved = 0
+for _, raw in ipairs(redis.call('LRANGE', KEYS[2], 0, -1)) do
+	if 0 == redis.call('EXISTS', KEYS[3] .. cjson.decode(raw).id) then
+		redis.call('LREM', KEYS[2], 1, raw)
+		redis.call('RPUSH', KEYS[1], raw)
+		moved = moved + 1
+	end
+end
+return moved`
+
+// cancel drop a delayed payload by id
+const redisCancelScript = `
+for _, raw in ipairs(redis.call('ZRANGE', KEYS[4], 0, -1)) do
+	if cjson.decode(raw).id == ARGV[1] then
+		redis.call('ZREM', KEYS[4], raw)
+		return 1
+	end
+end
+return 0`
+
+// redisItem json stored in the lists
+type redisItem struct {
+	ID        string           `json:"id"`
+	Enqueued  time.Time        `json:"enqueued"`
+	Payload   *message.PayLoad `json:"payload"`
+	NotBefore *time.Time       `json:"notBefore,omitempty"`
+	//  encrypted payload json, instead of Payload when the queue has an Encryption
+	Sealed []byte `json:"sealed,omitempty"`
+}
+
+// RedisQueue `DelayQueue in a redis list, shared by all replicas using the same key`
+//
+// Payloads are pushed on Key and claimed into Key+":processing" atomically, so each one is
+// handed to exactly one replica. A claim is leased for Lease, payloads of a replica that died
+// before acking are moved back to the head of the queue once their lease ran out. Lease must be
+// longer than a send including its retries, or a slow payload is sent twice. Payloads pushed
+// with PushAt wait in the sorted set Key+":delayed" until a Claim finds them due.
+type RedisQueue struct {
+	Key   string
+	Lease time.Duration
+	//  nil stores payloads in the clear, ids and times stay readable for the scripts either way
+	Encryption Encryption
+
+	redis RedisDoer
+
+	mu          sync.Mutex
+	raw         map[string]string
+	lastRecover time.Time
+}
+
+// NewRedisQueue `queue stored under key`
+func NewRedisQueue(redis RedisDoer, key string) *RedisQueue {
+	return &RedisQueue{Key: key, Lease: DefaultRedisLease, redis: redis, raw: make(map[string]string)}
+}
+
+// Push `implement Queue`
+func (q *RedisQueue) Push(payload *message.PayLoad) error {
+	raw, _, err := q.item(payload, nil)
+	if nil != err {
+		return err
+	}
+	_, err = q.redis.Do("LPUSH", q.Key, raw)
+
+	return err
+}
+
+// PushAt `implement DelayQueue`
+func (q *RedisQueue) PushAt(payload *message.PayLoad, at time.Time) (string, error) {
+	raw, id, err := q.item(payload, &at)
+	if nil != err {
+		return "", err
+	}
+	if _, err := q.redis.Do("ZADD", q.Key+":delayed", unixMilli(at), raw); nil != err {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Cancel `implement DelayQueue`
+func (q *RedisQueue) Cancel(id string) (bool, error) {
+	reply, err := q.eval(redisCancelScript, id)
+	if nil != err {
+		return false, err
+	}
+
+	return int64(1) == reply, nil
+}
+
+// item encode payload with a fresh id
+func (q *RedisQueue) item(payload *message.PayLoad, notBefore *time.Time) (string, string, error) {
+	seq, err := q.redis.Do("INCR", q.Key+":seq")
+	if nil != err {
+		return "", "", err
+	}
+
+	id := fmt.Sprint(seq)
+	item := redisItem{ID: id, Enqueued: time.Now(), Payload: payload, NotBefore: notBefore}
+	if nil != q.Encryption {
+		if item.Sealed, err = sealPayload(q.Encryption, payload); nil != err {
+			return "", "", err
+		}
+		item.Payload = nil
+	}
+	bs, err := json.Marshal(item)
+	if nil != err {
+		return "", "", err
+	}
+
+	return string(bs), id, nil
+}
+
+// Claim `implement Queue`
+func (q *RedisQueue) Claim() (*Item, error) {
+	if err := q.recover(); nil != err {
+		return nil, err
+	}
+
+	reply, err := q.eval(redisClaimScript, strconv.FormatInt(int64(q.lease()/time.Millisecond), 10), unixMilli(time.Now()))
+	if nil != err || nil == reply {
+		return nil, err
+	}
+
+	raw, ok := redisString(reply)
+	if !ok {
+		return nil, fmt.Errorf("redis queue: unexpected reply %T", reply)
+	}
+	var item redisItem
+	if err := json.Unmarshal([]byte(raw), &item); nil != err {
+		return nil, err
+	}
+	if nil != item.Sealed {
+		if item.Payload, err = openPayload(q.Encryption, item.Sealed); nil != err {
+			return nil, err
+		}
+	}
+
+	q.mu.Lock()
+	q.raw[item.ID] = raw
+	q.mu.Unlock()
+
+	claimed := &Item{ID: item.ID, Payload: item.Payload, Enqueued: item.Enqueued}
+	if nil != item.NotBefore {
+		claimed.NotBefore = *item.NotBefore
+	}
+
+	return claimed, nil
+}
+
+// Ack `implement Queue`
+func (q *RedisQueue) Ack(item *Item) error {
+	return q.settle(redisAckScript, item)
+}
+
+// Release `implement Queue`
+func (q *RedisQueue) Release(item *Item) error {
+	return q.settle(redisReleaseScript, item)
+}
+
+// settle run an ack or release script for a payload claimed by this replica
+func (q *RedisQueue) settle(script string, item *Item) error {
+	q.mu.Lock()
+	raw, ok := q.raw[item.ID]
+	delete(q.raw, item.ID)
+	q.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("redis queue: payload %s was not claimed here", item.ID)
+	}
+
+	_, err := q.eval(script, raw)
+	return err
+}
+
+// recover release expired leases, at most once per lease
+func (q *RedisQueue) recover() error {
+	q.mu.Lock()
+	due := time.Since(q.lastRecover) >= q.lease()
+	if due {
+		q.lastRecover = time.Now()
+	}
+	q.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	_, err := q.eval(redisRecoverScript)
+	return err
+}
+
+func (q *RedisQueue) lease() time.Duration {
+	if 0 == q.Lease {
+		return DefaultRedisLease
+	}
+
+	return q.Lease
+}
+
+func (q *RedisQueue) eval(script string, args ...interface{}) (interface{}, error) {
+	keys := []interface{}{"EVAL", script, 4, q.Key, q.Key + ":processing", q.Key + ":lease:", q.Key + ":delayed"}
+	return q.redis.Do(append(keys, args...)...)
+}
+
+// unixMilli redis score of t
+func unixMilli(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+func redisString(reply interface{}) (string, bool) {
+	switch reply := reply.(type) {
+	case string:
+		return reply, true
+	case []byte:
+		return string(reply), true
+	}
+
+	return "", false
+}
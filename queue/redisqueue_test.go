@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// fakeRedis keeps lists and leases in memory and runs the RedisQueue scripts natively
+type fakeRedis struct {
+	mu     sync.Mutex
+	seq    int64
+	lists  map[string][]string
+	leases map[string]time.Time
+	//  sorted set members and their scores
+	delayed map[string]int64
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{lists: make(map[string][]string), leases: make(map[string]time.Time), delayed: make(map[string]int64)}
+}
+
+func (r *fakeRedis) Do(args ...interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch args[0] {
+	case "INCR":
+		r.seq++
+		return r.seq, nil
+	case "LPUSH":
+		key := args[1].(string)
+		r.lists[key] = append([]string{args[2].(string)}, r.lists[key]...)
+		return int64(len(r.lists[key])), nil
+	case "ZADD":
+		score, _ := strconv.ParseInt(args[2].(string), 10, 64)
+		r.delayed[args[3].(string)] = score
+		return int64(1), nil
+	case "EVAL":
+		queue, processing, lease := args[3].(string), args[4].(string), args[5].(string)
+		argv := args[7:]
+		switch args[1] {
+		case redisClaimScript:
+			now, _ := strconv.ParseInt(argv[1].(string), 10, 64)
+			for _, raw := range r.due(now) {
+				delete(r.delayed, raw)
+				r.lists[queue] = append([]string{raw}, r.lists[queue]...)
+			}
+			list := r.lists[queue]
+			if 0 == len(list) {
+				return nil, nil
+			}
+			raw := list[len(list)-1]
+			r.lists[queue] = list[:len(list)-1]
+			r.lists[processing] = append([]string{raw}, r.lists[processing]...)
+			ms, _ := time.ParseDuration(argv[0].(string) + "ms")
+			r.leases[lease+r.id(raw)] = time.Now().Add(ms)
+			return raw, nil
+		case redisAckScript:
+			r.remove(processing, argv[0].(string))
+			delete(r.leases, lease+r.id(argv[0].(string)))
+			return int64(1), nil
+		case redisReleaseScript:
+			raw := argv[0].(string)
+			if r.remove(processing, raw) {
+				r.lists[queue] = append(r.lists[queue], raw)
+			}
+			delete(r.leases, lease+r.id(raw))
+			return int64(1), nil
+		case redisRecoverScript:
+			moved := int64(0)
+			for _, raw := range append([]string(nil), r.lists[processing]...) {
+				if expires, ok := r.leases[lease+r.id(raw)]; !ok || time.Now().After(expires) {
+					r.remove(processing, raw)
+					r.lists[queue] = append(r.lists[queue], raw)
+					moved++
+				}
+			}
+			return moved, nil
+		case redisCancelScript:
+			for raw := range r.delayed {
+				if argv[0] == r.id(raw) {
+					delete(r.delayed, raw)
+					return int64(1), nil
+				}
+			}
+			return int64(0), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported command %v", args[0])
+}
+
+// due delayed members with a score up to now, lowest first
+func (r *fakeRedis) due(now int64) []string {
+	var due []string
+	for raw, score := range r.delayed {
+		if score <= now {
+			due = append(due, raw)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return r.delayed[due[i]] < r.delayed[due[j]] })
+
+	return due
+}
+
+func (r *fakeRedis) id(raw string) string {
+	var item redisItem
+	_ = json.Unmarshal([]byte(raw), &item)
+	return item.ID
+}
+
+func (r *fakeRedis) remove(key, raw string) bool {
+	for i, item := range r.lists[key] {
+		if item == raw {
+			r.lists[key] = append(r.lists[key][:i], r.lists[key][i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestRedisQueue(t *testing.T) {
+	redis := newFakeRedis()
+	replica1 := NewRedisQueue(redis, "dingtalk:outbox")
+	replica2 := NewRedisQueue(redis, "dingtalk:outbox")
+	for _, content := range []string{"first", "second", "third"} {
+		if err := replica1.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: content}}); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	first, _ := replica1.Claim()
+	second, _ := replica2.Claim()
+	if "first" != first.Payload.Text.Content || "second" != second.Payload.Text.Content {
+		t.Fatalf("replicas should claim distinct payloads in order, got %s %s", first.Payload.Text.Content, second.Payload.Text.Content)
+	}
+	if err := replica2.Release(second); nil != err {
+		t.Fatal(err)
+	}
+	if again, _ := replica2.Claim(); nil == again || second.ID != again.ID {
+		t.Errorf("released payload should be claimed next, got %+v", again)
+	}
+	if err := replica1.Ack(first); nil != err {
+		t.Fatal(err)
+	}
+	if err := replica1.Ack(first); nil == err {
+		t.Error("acking twice error should be catch!")
+	}
+}
+
+func TestRedisQueueLease(t *testing.T) {
+	redis := newFakeRedis()
+	crashed := NewRedisQueue(redis, "dingtalk:outbox")
+	crashed.Lease = 10 * time.Millisecond
+	_ = crashed.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "orphan"}})
+	if item, _ := crashed.Claim(); nil == item {
+		t.Fatal("payload should be claimed")
+	}
+
+	survivor := NewRedisQueue(redis, "dingtalk:outbox")
+	survivor.Lease = 10 * time.Millisecond
+	if item, _ := survivor.Claim(); nil != item {
+		t.Fatalf("leased payload should not be handed out, got %+v", item)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if item, _ := survivor.Claim(); nil == item || "orphan" != item.Payload.Text.Content {
+		t.Errorf("payload of a dead replica should be recovered after its lease, got %+v", item)
+	}
+}
+
+func TestRedisQueueDelay(t *testing.T) {
+	queue := NewRedisQueue(newFakeRedis(), "dingtalk:outbox")
+	later, _ := queue.PushAt(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "later"}}, time.Now().Add(time.Hour))
+	_, _ = queue.PushAt(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "soon"}}, time.Now().Add(10*time.Millisecond))
+	_ = queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "now"}})
+
+	if item, _ := queue.Claim(); nil == item || "now" != item.Payload.Text.Content {
+		t.Fatalf("delayed payloads should not be claimed early, got %+v", item)
+	}
+	if item, _ := queue.Claim(); nil != item {
+		t.Fatalf("nothing should be due yet, got %+v", item)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if item, _ := queue.Claim(); nil == item || "soon" != item.Payload.Text.Content || item.NotBefore.IsZero() {
+		t.Errorf("due payload should be claimed, got %+v", item)
+	}
+	if ok, _ := queue.Cancel(later); !ok {
+		t.Error("pending delayed payload should be cancelled")
+	}
+	if ok, _ := queue.Cancel(later); ok {
+		t.Error("cancelled payload should be gone")
+	}
+}
+
+func TestRedisQueueEncryption(t *testing.T) {
+	redis := newFakeRedis()
+	encryption := xorEncryption(42)
+	queue := NewRedisQueue(redis, "dingtalk:outbox")
+	queue.Encryption = encryption
+
+	if err := queue.Push(&message.PayLoad{MsgType: "text", Text: &message.Text{Content: "refund 42 failed"}}); nil != err {
+		t.Fatal(err)
+	}
+	if raw := redis.lists["dingtalk:outbox"][0]; strings.Contains(raw, "refund") {
+		t.Errorf("payload should be stored encrypted, got %s", raw)
+	}
+
+	item, err := queue.Claim()
+	if nil != err || "refund 42 failed" != item.Payload.Text.Content {
+		t.Fatalf("payload should be decrypted when claimed, got %+v %v", item, err)
+	}
+	_ = queue.Release(item)
+
+	queue.Encryption = nil
+	if _, err := queue.Claim(); ErrNoEncryption != err {
+		t.Errorf("missing encryption error should be catch! got %v", err)
+	}
+}
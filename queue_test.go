@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestQueuedWebHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dingtalk-queue")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	queue, err := OpenFileQueue(filepath.Join(dir, "outbox"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("bad-gateway", "ok", "missing-param", "ok")
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	queued := NewQueuedWebHook(webHook, queue)
+	var rejected []string
+	queued.OnError = func(item *QueuedPayload, err error) {
+		rejected = append(rejected, item.Payload.Text.Content)
+	}
+	for _, content := range []string{"first", "second", "third"} {
+		if err := queued.Send(&TextMessage{Content: content}); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	var httpErr *HTTPError
+	if err := queued.Drain(context.Background()); !errors.As(err, &httpErr) {
+		t.Fatalf("drain should stop while DingTalk is down, got %v", err)
+	}
+	if 3 != queue.Len() {
+		t.Errorf("undelivered payload should stay queued, got %d", queue.Len())
+	}
+
+	if err := queued.Drain(context.Background()); nil != err {
+		t.Fatal(err)
+	}
+	if 0 != queue.Len() || 1 != len(rejected) || "second" != rejected[0] {
+		t.Errorf("rejected payload should be reported and removed, got %d %v", queue.Len(), rejected)
+	}
+
+	var sent []string
+	for _, request := range server.Requests() {
+		payload, _ := request.Payload()
+		sent = append(sent, payload.Text.Content)
+	}
+	if 4 != len(sent) || "first" != sent[0] || "first" != sent[1] || "third" != sent[3] {
+		t.Errorf("queue should be delivered in order, got %v", sent)
+	}
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter `decide when the next request may go out`
+type Limiter interface {
+	//  Wait blocks until a request is allowed or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// LeakyBucket `spaces requests evenly, e.g. 20 per minute becomes one every 3 seconds`
+//
+// Callers are released in the order they called Wait, so bursts are smoothed out instead of rejected
+// and the robot never exceeds its limit inside any window.
+type LeakyBucket struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewLeakyBucket `allow rate requests per period, a rate below 1 counts as 1`
+func NewLeakyBucket(rate int, per time.Duration) *LeakyBucket {
+	if rate < 1 {
+		rate = 1
+	}
+
+	return &LeakyBucket{interval: per / time.Duration(rate)}
+}
+
+// Delay `how long a request made now would wait for its slot`
+func (b *LeakyBucket) Delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if delay := time.Until(b.next); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// Wait `implement Limiter`
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	slot := b.next
+	b.next = slot.Add(b.interval)
+	b.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		//  hand the slot back if nobody queued behind us
+		b.mu.Lock()
+		if b.next.Equal(slot.Add(b.interval)) {
+			b.next = slot
+		}
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TokenBucket `lets short bursts through at once and refills steadily`
+//
+// Unlike LeakyBucket the first burst requests do not wait at all, which suits robots that are
+// quiet most of the time and then report a handful of events together.
+type TokenBucket struct {
+	burst    float64
+	interval time.Duration
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket `allow burst requests at once, refilled at rate requests per period`
+//
+// Any window of length period sees at most burst+rate requests, keep their sum within
+// DingTalk's HardLimit to never be throttled. A rate or burst below 1 counts as 1.
+func NewTokenBucket(rate int, per time.Duration, burst int) *TokenBucket {
+	if rate < 1 {
+		rate = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{burst: float64(burst), interval: per / time.Duration(rate), tokens: float64(burst)}
+}
+
+// DingTalkTokenBucket `bursts of 5, refilled so no minute ever sees more than HardLimit requests`
+func DingTalkTokenBucket() *TokenBucket {
+	return NewTokenBucket(HardLimit-5, HardLimitPeriod, 5)
+}
+
+// WithRateLimit `pace requests with DingTalkTokenBucket`
+func WithRateLimit() Option {
+	return func(w *WebHook) {
+		w.Limiter = DingTalkTokenBucket()
+	}
+}
+
+// refill add the tokens earned since the last call, caller holds mu
+func (b *TokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// Delay `how long a request made now would wait for its token`
+func (b *TokenBucket) Delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.interval))
+}
+
+// Wait `implement Limiter`
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refill(time.Now())
+	//  take the token now, possibly going negative, so waiters are served in order
+	b.tokens--
+	delay := time.Duration(-b.tokens * float64(b.interval))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLeakyBucketSpacing(t *testing.T) {
+	bucket := NewLeakyBucket(20, time.Second)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := bucket.Wait(context.Background()); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	//  first request is free, the other four wait 50ms each
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("requests should be spaced out, took %s", elapsed)
+	}
+}
+
+func TestLeakyBucketCancel(t *testing.T) {
+	bucket := NewLeakyBucket(1, time.Hour)
+	if err := bucket.Wait(context.Background()); nil != err {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.Wait(ctx); context.DeadlineExceeded != err {
+		t.Errorf("wait should give up with the context, got %v", err)
+	}
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	bucket := NewTokenBucket(20, time.Second, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := bucket.Wait(context.Background()); nil != err {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst should not wait, took %s", elapsed)
+	}
+	if 0 == bucket.Delay() {
+		t.Error("empty bucket should report a delay")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := bucket.Wait(context.Background()); nil != err {
+			t.Fatal(err)
+		}
+	}
+	//  the two requests after the burst wait for a 50ms refill each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("requests after the burst should be paced, took %s", elapsed)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	bucket := NewTokenBucket(1, time.Hour, 1)
+	if err := bucket.Wait(context.Background()); nil != err {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.Wait(ctx); context.DeadlineExceeded != err {
+		t.Errorf("wait should give up with the context, got %v", err)
+	}
+	if bucket.tokens < -0.01 {
+		t.Errorf("token should be handed back, got %f", bucket.tokens)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	webHook := NewWebHook("token", WithRateLimit())
+	bucket, ok := webHook.Limiter.(*TokenBucket)
+	if !ok || 5 != bucket.burst || HardLimitPeriod/(HardLimit-5) != bucket.interval {
+		t.Errorf("DingTalk token bucket should be installed, got %+v", webHook.Limiter)
+	}
+}
+
+func TestBucketZeroRate(t *testing.T) {
+	if leaky := NewLeakyBucket(0, time.Minute); time.Minute != leaky.interval {
+		t.Errorf("zero rate should count as one request per period, got %v", leaky.interval)
+	}
+	if token := NewTokenBucket(-1, time.Minute, 0); time.Minute != token.interval || 1 != token.burst {
+		t.Errorf("negative rate should count as one request per period, got %+v", token)
+	}
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// SendPayload `send a hand built payload through the full pipeline`
+func (w *WebHook) SendPayload(payload *PayLoad) error {
+	return w.sendPayloadContext(context.Background(), payload)
+}
+
+// SendPayloadContext `SendPayload bounded by ctx`
+func (w *WebHook) SendPayloadContext(ctx context.Context, payload *PayLoad) error {
+	return w.sendPayloadContext(ctx, payload)
+}
+
+// SendRaw `send a json body as is, e.g. a msgtype this package does not model yet`
+//
+// Token handling, signing, rate limiting, retries, metrics and middleware still apply, the
+// middleware sees a PayLoad with only MsgType and Raw set. Transform rules, silences, history and
+// the other decorations do not since they need the sections of a PayLoad.
+func (w *WebHook) SendRaw(body []byte) error {
+	return w.SendRawContext(context.Background(), body)
+}
+
+// SendRawContext `SendRaw bounded by ctx`
+func (w *WebHook) SendRawContext(ctx context.Context, body []byte) error {
+	var head struct {
+		MsgType string `json:"msgtype"`
+	}
+	if err := json.Unmarshal(body, &head); nil != err {
+		return errors.New("raw payload is not a json object: " + err.Error())
+	}
+	if "" == head.MsgType {
+		return errors.New("raw payload has no msgtype")
+	}
+
+	_, err := w.sender().Send(ctx, &PayLoad{MsgType: head.MsgType, Raw: body})
+	return err
+}
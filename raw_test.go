@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendRaw(t *testing.T) {
+	var query string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		body, _ = ioutil.ReadAll(r.Body)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Secret = "SECabc"
+
+	raw := []byte(`{"msgtype":"voice","voice":{"mediaId":"42"}}`)
+	if err := webHook.SendRaw(raw); nil != err {
+		t.Fatal(err)
+	}
+	if string(raw) != string(body) {
+		t.Errorf("raw body should be sent verbatim, got %s", body)
+	}
+	for _, param := range []string{"access_token=token", "sign=", "timestamp="} {
+		if !containsFold(query, param) {
+			t.Errorf("query should contain %s, got %s", param, query)
+		}
+	}
+
+	if err := webHook.SendRaw([]byte(`not json`)); nil == err {
+		t.Error("invalid json error should be catch!")
+	}
+	if err := webHook.SendRaw([]byte(`{"text":{}}`)); nil == err {
+		t.Error("missing msgtype error should be catch!")
+	}
+
+	payload := &PayLoad{MsgType: "text", Text: &Text{Content: "hello"}}
+	if err := webHook.SendPayload(payload); nil != err {
+		t.Fatal(err)
+	}
+}
+
+func TestSendRawMiddleware(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	var seen []string
+	webHook := NewWebHook("token", WithMiddleware(func(next Sender) Sender {
+		return SenderFunc(func(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+			seen = append(seen, payload.MsgType+" "+string(payload.Raw))
+			return next.Send(ctx, payload)
+		})
+	}))
+	webHook.APIURL = server.URL
+
+	raw := `{"msgtype":"voice","voice":{"mediaId":"42"}}`
+	if err := webHook.SendRaw([]byte(raw)); nil != err {
+		t.Fatal(err)
+	}
+	if 1 != len(seen) || "voice "+raw != seen[0] {
+		t.Errorf("middleware should see the raw send, got %q", seen)
+	}
+	if 1 != len(server.Requests()) || raw != string(server.Requests()[0].Body) {
+		t.Error("raw body should be sent verbatim through the middleware")
+	}
+}
+
+func TestSendPayloadTwice(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithRules(Rule{Name: "env", Rewrite: func(content string) string {
+		return "[prod] " + content
+	}}))
+	webHook.APIURL = server.URL
+	webHook.Keywords = []string{"alert"}
+	webHook.Sender = SenderIdentity{Service: "billing", Team: "payments"}
+	webHook.SenderFooter = true
+	webHook.IdempotencyFooter = true
+
+	payload := &PayLoad{MsgType: "text", Text: &Text{Content: "disk full"}}
+	if err := webHook.SendPayload(payload); nil != err {
+		t.Fatal(err)
+	}
+	if _, err := webHook.SendWithResult(payload); nil != err {
+		t.Fatal(err)
+	}
+
+	if "disk full" != payload.Text.Content {
+		t.Errorf("caller payload should not be decorated, got %q", payload.Text.Content)
+	}
+	requests := server.Requests()
+	first, _ := requests[0].Payload()
+	second, _ := requests[1].Payload()
+	if first.Text.Content != second.Text.Content {
+		t.Errorf("same payload should be sent with the same body, got %q and %q", first.Text.Content, second.Text.Content)
+	}
+}
@@ -0,0 +1,130 @@
+// Package receiver handles the callbacks DingTalk sends to outgoing robots.
+//
+// DingTalk posts every message mentioning the robot to its callback url, signed with the robot
+// AppSecret the same way webhook requests are. A reply written to the response is posted back
+// into the conversation.
+package receiver
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+	"github.com/lddsb/dingtalk-webhook/sign"
+)
+
+// MaxSkew `how far the timestamp header may be from the local clock, as DingTalk documents`
+const MaxSkew = time.Hour
+
+// ErrBadSignature `the sign header does not match the timestamp and secret`
+var ErrBadSignature = errors.New("dingtalk callback signature mismatch")
+
+// ErrStale `the timestamp header is missing or further than MaxSkew from now`
+var ErrStale = errors.New("dingtalk callback timestamp out of range")
+
+// User `a member mentioned in a callback`
+type User struct {
+	DingtalkID string `json:"dingtalkId"`
+	StaffID    string `json:"staffId,omitempty"`
+}
+
+// Message `a message DingTalk posts to an outgoing robot`
+type Message struct {
+	MsgID             string `json:"msgId"`
+	MsgType           string `json:"msgtype"`
+	ConversationID    string `json:"conversationId"`
+	ConversationType  string `json:"conversationType"` //  "1" single chat, "2" group
+	ConversationTitle string `json:"conversationTitle"`
+	SenderID          string `json:"senderId"`
+	SenderStaffID     string `json:"senderStaffId"`
+	SenderNick        string `json:"senderNick"`
+	IsAdmin           bool   `json:"isAdmin"`
+	ChatbotUserID     string `json:"chatbotUserId"`
+	AtUsers           []User `json:"atUsers"`
+	IsInAtList        bool   `json:"isInAtList"`
+	//  unix milliseconds
+	CreateAt int64 `json:"createAt"`
+	//  robot url answering this conversation until SessionWebhookExpiredTime, in unix milliseconds
+	SessionWebhook            string `json:"sessionWebhook"`
+	SessionWebhookExpiredTime int64  `json:"sessionWebhookExpiredTime"`
+	Text                      struct {
+		Content string `json:"content"`
+	} `json:"text"`
+}
+
+// Verify `check the timestamp and sign headers of a callback against secret`
+func Verify(secret, timestamp, signature string, now time.Time) error {
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if nil != err {
+		return ErrStale
+	}
+	skew := now.Sub(time.Unix(0, millis*int64(time.Millisecond)))
+	if skew > MaxSkew || skew < -MaxSkew {
+		return ErrStale
+	}
+
+	if !hmac.Equal([]byte(sign.Sign(secret, millis)), []byte(signature)) {
+		return ErrBadSignature
+	}
+
+	return nil
+}
+
+// HandlerFunc `answer a callback, a nil message sends no reply`
+type HandlerFunc func(r *http.Request, msg *Message) (message.Message, error)
+
+// Handler `http.Handler verifying callbacks signed with Secret before passing them to Handle`
+type Handler struct {
+	Secret string
+	Handle HandlerFunc
+}
+
+// NewHandler `handler for callbacks of the robot with secret`
+func NewHandler(secret string, handle HandlerFunc) *Handler {
+	return &Handler{Secret: secret, Handle: handle}
+}
+
+// ServeHTTP `implement http.Handler`
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if http.MethodPost != r.Method {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := Verify(h.Secret, r.Header.Get("timestamp"), r.Header.Get("sign"), time.Now()); nil != err {
+		http.Error(rw, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var msg Message
+	if err := json.NewDecoder(r.Body).Decode(&msg); nil != err {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reply, err := h.Handle(r, &msg)
+	if nil != err {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if nil == reply {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	payload, err := reply.Payload()
+	if nil != err {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	bs, err := json.Marshal(payload)
+	if nil != err {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(bs)
+}
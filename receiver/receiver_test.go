@@ -0,0 +1,78 @@
+package receiver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+	"github.com/lddsb/dingtalk-webhook/sign"
+)
+
+func TestVerify(t *testing.T) {
+	vector := sign.ReferenceVectors[0]
+	now := time.Unix(0, vector.Timestamp*int64(time.Millisecond)).Add(time.Minute)
+	timestamp := strconv.FormatInt(vector.Timestamp, 10)
+
+	if err := Verify(vector.Secret, timestamp, vector.Sign, now); nil != err {
+		t.Errorf("reference signature should verify, got %v", err)
+	}
+	if err := Verify("SECwrong", timestamp, vector.Sign, now); ErrBadSignature != err {
+		t.Errorf("wrong secret error should be catch! got %v", err)
+	}
+	if err := Verify(vector.Secret, timestamp, vector.Sign, now.Add(2*time.Hour)); ErrStale != err {
+		t.Errorf("stale timestamp error should be catch! got %v", err)
+	}
+	if err := Verify(vector.Secret, "", vector.Sign, now); ErrStale != err {
+		t.Errorf("missing timestamp error should be catch! got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler("SECret", func(r *http.Request, msg *Message) (message.Message, error) {
+		if "" == strings.TrimSpace(msg.Text.Content) {
+			return nil, nil
+		}
+		return &message.TextMessage{Content: "echo:" + strings.TrimSpace(msg.Text.Content)}, nil
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	post := func(body string, timestamp int64, signature string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		req.Header.Set("timestamp", strconv.FormatInt(timestamp, 10))
+		req.Header.Set("sign", signature)
+		resp, err := http.DefaultClient.Do(req)
+		if nil != err {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	resp := post(`{"msgtype":"text","senderStaffId":"manager4711","text":{"content":" ping"}}`, now, sign.Sign("SECret", now))
+	defer resp.Body.Close()
+	var payload message.PayLoad
+	if err := json.NewDecoder(resp.Body).Decode(&payload); nil != err {
+		t.Fatal(err)
+	}
+	if "echo:ping" != payload.Text.Content {
+		t.Errorf("reply should be written as a payload, got %+v", payload)
+	}
+
+	resp = post(`{"msgtype":"text","text":{"content":" "}}`, now, sign.Sign("SECret", now))
+	resp.Body.Close()
+	if http.StatusOK != resp.StatusCode {
+		t.Errorf("empty reply should still be accepted, got %d", resp.StatusCode)
+	}
+
+	resp = post(`{}`, now, sign.Sign("SECother", now))
+	resp.Body.Close()
+	if http.StatusUnauthorized != resp.StatusCode {
+		t.Errorf("forged callback should be refused, got %d", resp.StatusCode)
+	}
+}
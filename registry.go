@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// UnknownRobotError `no robot is registered under Name`
+type UnknownRobotError struct {
+	Name string
+}
+
+// Error `implement error interface`
+func (e *UnknownRobotError) Error() string {
+	return "unknown robot: " + e.Name
+}
+
+// Registry `robots by name, so tokens and secrets are configured in one place`
+type Registry struct {
+	mu     sync.RWMutex
+	robots map[string]*WebHook
+}
+
+// NewRegistry `empty registry`
+func NewRegistry() *Registry {
+	return &Registry{robots: make(map[string]*WebHook)}
+}
+
+// Register `store w under name, replacing any previous one`
+//
+// A robot without a Name takes the registry name, so history, metrics and failures show it.
+func (r *Registry) Register(name string, w *WebHook) {
+	if "" == w.Name {
+		w.Name = name
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.robots[name] = w
+}
+
+// Remove `forget the robot registered under name, false when there is none`
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.robots[name]
+	delete(r.robots, name)
+	return ok
+}
+
+// Get `the robot registered under name`
+func (r *Registry) Get(name string) (*WebHook, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.robots[name]
+	return w, ok
+}
+
+// Names `sorted names of all robots`
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.robots))
+	for name := range r.robots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Robots `copy of the registry, e.g. for a Broadcast`
+func (r *Registry) Robots() map[string]*WebHook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	robots := make(map[string]*WebHook, len(r.robots))
+	for name, w := range r.robots {
+		robots[name] = w
+	}
+
+	return robots
+}
+
+// Profiles `the RobotProfile of every robot that has one`
+func (r *Registry) Profiles() map[string]RobotProfile {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profiles := make(map[string]RobotProfile)
+	for name, w := range r.robots {
+		if !w.Profile.IsZero() {
+			profiles[name] = w.Profile
+		}
+	}
+
+	return profiles
+}
+
+// Send `send msg through the robot registered under name`
+func (r *Registry) Send(name string, msg Message) error {
+	return r.SendContext(context.Background(), name, msg)
+}
+
+// SendContext `Send bounded by ctx`
+func (r *Registry) SendContext(ctx context.Context, name string, msg Message) error {
+	w, ok := r.Get(name)
+	if !ok {
+		return &UnknownRobotError{Name: name}
+	}
+
+	return w.SendContext(ctx, msg)
+}
+
+// SelfTest `SelfTest every registered robot, reporting to admin`
+func (r *Registry) SelfTest(ctx context.Context, admin *WebHook) (*SelfTestReport, error) {
+	return SelfTest(ctx, r.Robots(), admin)
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRegistry(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	registry := NewRegistry()
+	for _, name := range []string{"ops", "payments-alerts", "ci"} {
+		webHook := NewWebHook("token-" + name)
+		webHook.APIURL = server.URL
+		registry.Register(name, webHook)
+	}
+	ops, _ := registry.Get("ops")
+	ops.Profile = RobotProfile{Group: "SRE", Owner: "oncall"}
+
+	if err := registry.Send("payments-alerts", &TextMessage{Content: "refund queue stuck"}); nil != err {
+		t.Fatal(err)
+	}
+	if request := server.Requests()[0]; "token-payments-alerts" != request.Query.Get("access_token") {
+		t.Errorf("message should go to the named robot, got %s", request.Query)
+	}
+	if "ci" != registry.Robots()["ci"].channel() {
+		t.Error("registry name should become the channel name")
+	}
+
+	var unknown *UnknownRobotError
+	if err := registry.Send("billing", &TextMessage{Content: "hello"}); !errors.As(err, &unknown) || "billing" != unknown.Name {
+		t.Errorf("unknown robot error should be catch! got %v", err)
+	}
+
+	if !registry.Remove("ci") || registry.Remove("ci") {
+		t.Error("robot should be removed once")
+	}
+	if names := registry.Names(); 2 != len(names) || "ops" != names[0] {
+		t.Errorf("names should be sorted, got %v", names)
+	}
+	if profiles := registry.Profiles(); 1 != len(profiles) || "SRE" != profiles["ops"].Group {
+		t.Errorf("only robots with a profile should be listed, got %v", profiles)
+	}
+
+	report, err := registry.SelfTest(context.Background(), nil)
+	if nil != err || 2 != len(report.Results) {
+		t.Errorf("every robot should be self-tested, got %v %+v", err, report)
+	}
+}
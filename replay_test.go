@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestReplayRecordedResponses(t *testing.T) {
+	checks := map[string]func(error) bool{
+		"ok":                      func(err error) bool { return nil == err },
+		"ok-extra-fields":         func(err error) bool { return nil == err },
+		"send-too-fast":           func(err error) bool { return errors.Is(err, ErrTooFast) },
+		"token-not-exist":         func(err error) bool { return errors.Is(err, ErrInvalidToken) },
+		"sign-not-match":          func(err error) bool { return errors.Is(err, ErrSignMismatch) },
+		"keywords-not-in-content": func(err error) bool { return errors.Is(err, ErrKeywordMismatch) },
+		"ip-not-in-whitelist":     func(err error) bool { return errors.Is(err, ErrIPNotAllowed) },
+		"missing-param": func(err error) bool {
+			var apiErr *APIError
+			return errors.As(err, &apiErr) && 40035 == apiErr.Code
+		},
+		"bad-gateway": func(err error) bool {
+			var httpErr *HTTPError
+			return errors.As(err, &httpErr) && 502 == httpErr.StatusCode
+		},
+	}
+
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	for _, name := range webhooktest.FixtureNames() {
+		check, ok := checks[name]
+		if !ok {
+			t.Errorf("fixture %s has no expectation", name)
+			continue
+		}
+
+		if err := server.ReplyFixture(name); nil != err {
+			t.Fatal(err)
+		}
+		if err := webHook.SendTextMsg("replay", false); !check(err) {
+			t.Errorf("fixture %s: unexpected error %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SendResult `everything known about a request that reached the api`
+type SendResult struct {
+	Response
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+	//  set instead of the http fields when WebHook.DryRun skipped the request
+	DryRun *DryRunRequest
+	//  set when a fallback delivered the message, the http fields are the fallback's
+	Downgrade *Downgrade
+	//  results of every part of a message sent in several, the fields above are the last one's, Duration the total
+	Parts []*SendResult
+
+	//  signing timestamp, 0 without Secret
+	timestamp int64
+}
+
+// SendWithResult `send any message and return the api result alongside the error`
+func (w *WebHook) SendWithResult(msg Message) (*SendResult, error) {
+	return w.SendWithResultContext(context.Background(), msg)
+}
+
+// SendWithResultContext `SendWithResult bounded by ctx`
+//
+// The result is nil when the api was never reached (invalid message, silenced, dropped,
+// duplicate, network failure), otherwise it is returned even when err is not nil.
+func (w *WebHook) SendWithResultContext(ctx context.Context, msg Message) (*SendResult, error) {
+	payload, err := msg.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	payload = clonePayload(payload)
+	if !w.applyRules(ctx, payload) {
+		return nil, ErrDropped
+	}
+
+	var result *SendResult
+	err = w.deduplicate(ctx, func() (bool, error) {
+		var delivered int
+		result, delivered, err = w.sendParts(ctx, payload)
+		return 0 != delivered, err
+	})
+
+	return result, err
+}
+
+// combineResults one result for the parts of a message, nil when none reached the api
+func combineResults(results []*SendResult) *SendResult {
+	switch len(results) {
+	case 0:
+		return nil
+	case 1:
+		return results[0]
+	}
+
+	combined := *results[len(results)-1]
+	combined.Parts = results
+	combined.Duration = 0
+	for _, result := range results {
+		combined.Duration += result.Duration
+	}
+
+	return &combined
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendWithResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-Request-Id", "abc")
+		if "bad" == r.URL.Query().Get("access_token") {
+			rw.WriteHeader(http.StatusBadGateway)
+			_, _ = rw.Write([]byte("upstream down"))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"errcode":130101,"errmsg":"send too fast"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	result, err := webHook.SendWithResult(&TextMessage{Content: "hello"})
+	if nil == err || nil == result {
+		t.Fatalf("api error and result expected, got %v %v", result, err)
+	}
+	if 130101 != result.ErrorCode || "send too fast" != result.Response.ErrorMessage || 200 != result.StatusCode {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if "abc" != result.Header.Get("X-Request-Id") || 0 == len(result.Body) || 0 == result.Duration {
+		t.Errorf("headers, body and duration should be kept: %+v", result)
+	}
+
+	webHook.AccessToken = "bad"
+	result, err = webHook.SendWithResult(&TextMessage{Content: "hello"})
+	if nil == err || nil == result || http.StatusBadGateway != result.StatusCode || "upstream down" != string(result.Body) {
+		t.Errorf("non 200 response should be returned, got %+v %v", result, err)
+	}
+
+	result, err = webHook.SendWithResult(&ActionCardMessage{})
+	if nil == err || nil != result {
+		t.Error("invalid message should not produce a result")
+	}
+}
+
+func TestSendWithResultParts(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.AutoSplit = true
+
+	result, err := NewBroadcast(map[string]*WebHook{"ops": webHook}).Send(&TextMessage{Content: strings.Repeat("x", 2*MaxContentLength)})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if 3 != len(server.Requests()) || 3 != len(result["ops"].Parts) {
+		t.Errorf("oversized message should be sent in parts, got %d requests %+v", len(server.Requests()), result["ops"])
+	}
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy `how failed requests are retried, delays double from BaseDelay up to MaxDelay`
+type RetryPolicy struct {
+	//  attempts including the first one, 1 or less never retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	//  fraction of each delay that is randomized, 0.2 waits between 80% and 100% of it
+	Jitter float64
+	//  errors worth another attempt, nil uses IsTransient
+	RetryOn func(err error) bool
+}
+
+// DefaultRetryPolicy `3 attempts, 500ms doubling up to 10s, 20% jitter, transient errors only`
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second, Jitter: 0.2}
+}
+
+// WithRetry `retry failed requests according to policy`
+func WithRetry(policy *RetryPolicy) Option {
+	return func(w *WebHook) {
+		w.Retry = policy
+	}
+}
+
+// IsTransient `network errors and 429 or 5xx responses, anything else fails the same way again`
+func IsTransient(err error) bool {
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return 429 == httpErr.StatusCode || httpErr.StatusCode >= 500
+	}
+
+	return false
+}
+
+// Delay `backoff after the given failed attempt, starting at 1`
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (0 == p.MaxDelay || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if 0 != p.MaxDelay && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+
+	return delay
+}
+
+// retry whether err after attempt is worth another one
+func (p *RetryPolicy) retry(attempt int, err error) bool {
+	if nil == p || attempt >= p.MaxAttempts {
+		return false
+	}
+	if nil != p.RetryOn {
+		return p.RetryOn(err)
+	}
+
+	return IsTransient(err)
+}
+
+// deliver post until it succeeds or Retry gives up, errs holds one error per failed attempt and is nil on success
+func (w *WebHook) deliver(ctx context.Context, post func(context.Context) (*SendResult, error)) (*SendResult, []error) {
+	var errs []error
+	tooFast := 0
+	for attempt := 1; ; attempt++ {
+		if nil != w.Breaker {
+			if err := w.Breaker.allow(); nil != err {
+				return nil, append(errs, err)
+			}
+		}
+		if err := w.wait(ctx); nil != err {
+			if nil != w.Breaker {
+				w.Breaker.cancel()
+			}
+			return nil, append(errs, err)
+		}
+
+		result, err := post(ctx)
+		switch {
+		case nil == w.Breaker:
+		case nil != err && nil != ctx.Err():
+			//  the caller gave up, that says nothing about the endpoint
+			w.Breaker.cancel()
+		default:
+			w.Breaker.record(err)
+		}
+		if nil == err {
+			return result, nil
+		}
+		errs = append(errs, err)
+
+		switch {
+		case w.coolDown(err, tooFast):
+			//  the next waitCoolDown does the waiting
+			w.infof("dingtalk %s: sending too fast, holding sends for %s", w.channel(), w.TooFastCooldown)
+			tooFast++
+			continue
+		case !w.Retry.retry(attempt, err):
+			return result, errs
+		}
+
+		delay := w.Retry.Delay(attempt)
+		w.infof("dingtalk %s: attempt %d failed, retrying in %s: %v", w.channel(), attempt, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, errs
+		case <-timer.C:
+		}
+	}
+}
+
+// limiterLogThreshold shortest limiter wait worth a debug line
+const limiterLogThreshold = 100 * time.Millisecond
+
+// wait hold the request for a running cool-down and the limiter
+func (w *WebHook) wait(ctx context.Context) error {
+	if err := w.waitCoolDown(ctx); nil != err {
+		return err
+	}
+	limiter := w.limiter()
+	if nil == limiter {
+		return nil
+	}
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	if waited := time.Since(start); waited >= limiterLogThreshold {
+		w.debugf("dingtalk %s: rate limiter held the request for %s", w.channel(), waited)
+	}
+	return err
+}
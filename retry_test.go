@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRetry(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("bad-gateway", "bad-gateway")
+
+	var failure Failure
+	webHook := NewWebHook("token", WithRetry(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	webHook.APIURL = server.URL
+	webHook.OnPermanentFailure = func(f Failure) {
+		failure = f
+	}
+
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatalf("third attempt should succeed, got %v", err)
+	}
+	if 3 != len(server.Requests()) {
+		t.Errorf("3 attempts expected, got %d", len(server.Requests()))
+	}
+
+	_ = server.ReplyFixture("bad-gateway", "bad-gateway", "bad-gateway")
+	err := webHook.SendTextMsg("hello", false)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || 3 != failure.Attempts || 3 != len(failure.Errors) {
+		t.Errorf("every attempt should be reported, got %v %+v", err, failure)
+	}
+
+	_ = server.ReplyFixture("token-not-exist")
+	before := len(server.Requests())
+	if err := webHook.SendTextMsg("hello", false); !errors.Is(err, ErrInvalidToken) || before+1 != len(server.Requests()) {
+		t.Errorf("api errors should not be retried, got %v", err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		if got := policy.Delay(attempt); want != got {
+			t.Errorf("attempt %d: want %s, got %s", attempt, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if delay := policy.Delay(1); delay < 50*time.Millisecond || delay > 100*time.Millisecond {
+			t.Fatalf("jittered delay out of range: %s", delay)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := map[error]bool{
+		&RequestError{Err: errors.New("connection reset")}: true,
+		&HTTPError{StatusCode: 503}:                        true,
+		&HTTPError{StatusCode: 429}:                        true,
+		&HTTPError{StatusCode: 404}:                        false,
+		&APIError{Code: 310000}:                            false,
+	}
+	for err, want := range cases {
+		if want != IsTransient(err) {
+			t.Errorf("IsTransient(%v) should be %v", err, want)
+		}
+	}
+}
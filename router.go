@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnrouted `returned by Notify when no route and no default matches the event`
+var ErrUnrouted = errors.New("no robot matches the event")
+
+// ErrNoRegistry `returned by Notify on a Router not built by NewRouter, LoadRouter or LoadConfig`
+var ErrNoRegistry = errors.New("router has no registry")
+
+// Event `something to notify about, routed to robots by its attributes`
+type Event struct {
+	Severity string
+	Team     string
+	Tags     []string
+	Title    string
+	//  markdown
+	Text string
+	//  sent instead of Title and Text when set
+	Message Message
+}
+
+// message what to send for the event
+func (e Event) message() Message {
+	if nil != e.Message {
+		return e.Message
+	}
+
+	title := e.Title
+	if "" != e.Severity {
+		title = "[" + e.Severity + "] " + title
+	}
+
+	return &MarkdownMessage{Title: title, Text: "#### " + title + "\n\n" + e.Text}
+}
+
+// attributes of an event, tags are joined by commas
+func (e Event) attributes() Attributes {
+	attrs := Attributes{}
+	if "" != e.Severity {
+		attrs["severity"] = e.Severity
+	}
+	if "" != e.Team {
+		attrs["team"] = e.Team
+	}
+	if 0 != len(e.Tags) {
+		attrs["tags"] = strings.Join(e.Tags, ",")
+	}
+
+	return attrs
+}
+
+// Route `one routing rule, fields carry json and yaml tags`
+//
+// Every condition set must match, an empty one matches any event. Severities and Teams compare
+// case-insensitively, Tags match when the event has any of them.
+type Route struct {
+	Name       string   `json:"name" yaml:"name"`
+	Severities []string `json:"severities,omitempty" yaml:"severities,omitempty"`
+	Teams      []string `json:"teams,omitempty" yaml:"teams,omitempty"`
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	//  registry names of the robots to notify
+	Robots []string `json:"robots" yaml:"robots"`
+	//  keep evaluating the routes after this one matched
+	Continue bool `json:"continue,omitempty" yaml:"continue,omitempty"`
+}
+
+// Match `the event satisfies every condition of the route`
+func (r Route) Match(event Event) bool {
+	return (0 == len(r.Severities) || inFold(event.Severity, r.Severities)) &&
+		(0 == len(r.Teams) || inFold(event.Team, r.Teams)) &&
+		(0 == len(r.Tags) || anyIn(event.Tags, r.Tags))
+}
+
+// Router `deliver events to the robots of a Registry chosen by ordered routes`
+//
+// Routes are evaluated in order and the first match wins unless it sets Continue. Events no
+// route matches go to Default.
+type Router struct {
+	Routes []Route `json:"routes" yaml:"routes"`
+	//  robots for events no route matches
+	Default []string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	registry *Registry
+}
+
+// NewRouter `router over the robots of registry`
+func NewRouter(registry *Registry, routes ...Route) *Router {
+	return &Router{Routes: routes, registry: registry}
+}
+
+// LoadRouter `decode routes, unmarshal defaults to json.Unmarshal, pass yaml.Unmarshal for YAML`
+func LoadRouter(registry *Registry, data []byte, unmarshal func([]byte, interface{}) error) (*Router, error) {
+	if nil == unmarshal {
+		unmarshal = json.Unmarshal
+	}
+
+	router := NewRouter(registry)
+	if err := unmarshal(data, router); nil != err {
+		return nil, err
+	}
+	if err := router.validate(); nil != err {
+		return nil, err
+	}
+
+	return router, nil
+}
+
+// validate every route names at least one robot
+func (r *Router) validate() error {
+	for _, route := range r.Routes {
+		if 0 == len(route.Robots) {
+			return fmt.Errorf("route %q: no robots", route.Name)
+		}
+	}
+
+	return nil
+}
+
+// Match `names of the robots the event goes to, in route order without duplicates`
+func (r *Router) Match(event Event) []string {
+	var robots []string
+	matched := false
+	for _, route := range r.Routes {
+		if !route.Match(event) {
+			continue
+		}
+		matched = true
+		robots = appendMissing(robots, route.Robots...)
+		if !route.Continue {
+			break
+		}
+	}
+	if !matched {
+		robots = appendMissing(robots, r.Default...)
+	}
+
+	return robots
+}
+
+// Notify `send the event to every robot it is routed to`
+func (r *Router) Notify(event Event) error {
+	return r.NotifyContext(context.Background(), event)
+}
+
+// NotifyContext `Notify bounded by ctx`
+//
+// The error is a *MultiError naming the robots that failed or are not registered, ErrUnrouted
+// when the event matches nothing or ErrNoRegistry when the router has no robots to look up.
+func (r *Router) NotifyContext(ctx context.Context, event Event) error {
+	if nil == r.registry {
+		return ErrNoRegistry
+	}
+	names := r.Match(event)
+	if 0 == len(names) {
+		return ErrUnrouted
+	}
+	ctx = WithAttributes(ctx, event.attributes())
+
+	failures := &MultiError{}
+	targets := make(map[string]*WebHook, len(names))
+	for _, name := range names {
+		if w, ok := r.registry.Get(name); ok {
+			targets[name] = w
+		} else {
+			failures.add(name, &UnknownRobotError{Name: name})
+		}
+	}
+
+	_, err := NewBroadcast(targets).SendContext(ctx, event.message())
+	var multi *MultiError
+	switch {
+	case errors.As(err, &multi):
+		failures.Errors = append(failures.Errors, multi.Errors...)
+		failures.Total += multi.Total
+	case nil != err:
+		return err
+	default:
+		failures.Total += len(targets)
+	}
+
+	return failures.Err()
+}
+
+// inFold value is in list, ignoring case
+func inFold(value string, list []string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anyIn any of values is in list
+func anyIn(values, list []string) bool {
+	for _, value := range values {
+		if inStrings(value, list) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRouter(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	registry := NewRegistry()
+	for _, name := range []string{"oncall", "payments", "ops"} {
+		webHook := NewWebHook("token-" + name)
+		webHook.APIURL = server.URL
+		registry.Register(name, webHook)
+	}
+
+	router, err := LoadRouter(registry, []byte(`{
+		"routes": [
+			{"name": "page", "severities": ["critical"], "robots": ["oncall"], "continue": true},
+			{"name": "payments", "teams": ["payments"], "robots": ["payments"]},
+			{"name": "db", "tags": ["mysql", "redis"], "robots": ["ops", "billing"]}
+		],
+		"default": ["ops"]
+	}`), nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	if robots := router.Match(Event{Severity: "CRITICAL", Team: "payments"}); 2 != len(robots) || "payments" != robots[1] {
+		t.Errorf("continue should keep routing, got %v", robots)
+	}
+	if robots := router.Match(Event{Team: "payments", Tags: []string{"mysql"}}); 1 != len(robots) {
+		t.Errorf("first match should win, got %v", robots)
+	}
+	if robots := router.Match(Event{Team: "search"}); 1 != len(robots) || "ops" != robots[0] {
+		t.Errorf("unmatched event should go to default, got %v", robots)
+	}
+
+	if err := router.Notify(Event{Severity: "critical", Team: "payments", Title: "refunds stuck", Text: "queue depth 1200"}); nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if 2 != len(requests) {
+		t.Fatalf("event should reach two robots, got %d", len(requests))
+	}
+	payload, _ := requests[0].Payload()
+	if "[critical] refunds stuck" != payload.Markdown.Title {
+		t.Errorf("severity should prefix the title, got %q", payload.Markdown.Title)
+	}
+
+	var multi *MultiError
+	var unknown *UnknownRobotError
+	err = router.Notify(Event{Tags: []string{"redis"}, Title: "evictions"})
+	if !errors.As(err, &multi) || 2 != multi.Total || !errors.As(err, &unknown) || "billing" != unknown.Name {
+		t.Errorf("unknown robot error should be catch! got %v", err)
+	}
+
+	if err := NewRouter(registry).Notify(Event{Title: "lost"}); ErrUnrouted != err {
+		t.Errorf("unrouted error should be catch! got %v", err)
+	}
+	if err := (&Router{Default: []string{"ops"}}).Notify(Event{Title: "lost"}); ErrNoRegistry != err {
+		t.Errorf("missing registry error should be catch! got %v", err)
+	}
+	if _, err := LoadRouter(registry, []byte(`{"routes": [{"name": "empty"}]}`), nil); nil == err {
+		t.Error("route without robots error should be catch!")
+	}
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// Attributes `free-form labels of a send, e.g. severity or env, which Rules match on`
+type Attributes map[string]string
+
+type attributesKey struct{}
+
+// WithAttributes `attach attributes to the messages sent with ctx, merged over those already attached`
+func WithAttributes(ctx context.Context, attrs Attributes) context.Context {
+	merged := make(Attributes)
+	for key, value := range AttributesFrom(ctx) {
+		merged[key] = value
+	}
+	for key, value := range attrs {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// AttributesFrom `attributes attached by WithAttributes, nil when there are none`
+func AttributesFrom(ctx context.Context) Attributes {
+	attrs, _ := ctx.Value(attributesKey{}).(Attributes)
+	return attrs
+}
+
+// Condition `predicate of a Rule over the payload as built and the attributes of its send`
+type Condition func(payload *PayLoad, attrs Attributes) bool
+
+// AttributeIn `the attribute is one of values, ignoring case`
+func AttributeIn(key string, values ...string) Condition {
+	return func(_ *PayLoad, attrs Attributes) bool {
+		value, ok := attrs[key]
+		return ok && inFold(value, values)
+	}
+}
+
+// MsgTypeIn `the payload is one of msgTypes`
+func MsgTypeIn(msgTypes ...string) Condition {
+	return func(payload *PayLoad, _ Attributes) bool {
+		return inStrings(payload.MsgType, msgTypes)
+	}
+}
+
+// ContentMatches `the message body matches re`
+func ContentMatches(re *regexp.Regexp) Condition {
+	return func(payload *PayLoad, _ Attributes) bool {
+		return re.MatchString(payloadContent(payload))
+	}
+}
+
+// AllOf `every condition holds`
+func AllOf(conditions ...Condition) Condition {
+	return func(payload *PayLoad, attrs Attributes) bool {
+		for _, condition := range conditions {
+			if !condition(payload, attrs) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf `at least one condition holds`
+func AnyOf(conditions ...Condition) Condition {
+	return func(payload *PayLoad, attrs Attributes) bool {
+		for _, condition := range conditions {
+			if condition(payload, attrs) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not `the condition does not hold`
+func Not(condition Condition) Condition {
+	return func(payload *PayLoad, attrs Attributes) bool {
+		return !condition(payload, attrs)
+	}
+}
+
+// Rule `drop, rewrite or add mentions to the messages matching When`
+type Rule struct {
+	Name string
+	//  nil matches every message
+	When Condition
+	//  discard the message, Send returns ErrDropped, ignored by Emergency robots
+	Drop bool
+	//  applied to the message body
+	Rewrite func(content string) string
+	//  added to text and markdown messages, ignored by the other msgtypes
+	Mention []AtOption
+}
+
+// WithRules `evaluate rules in order on every message, before keywords and the other decorations`
+func WithRules(rules ...Rule) Option {
+	return func(w *WebHook) {
+		w.Rules = append(w.Rules, rules...)
+	}
+}
+
+// applyRules run the rules over payload, false means it must be dropped
+func (w *WebHook) applyRules(ctx context.Context, payload *PayLoad) bool {
+	if 0 == len(w.Rules) {
+		return true
+	}
+
+	attrs := AttributesFrom(ctx)
+	for _, rule := range w.Rules {
+		if nil != rule.When && !rule.When(payload, attrs) {
+			continue
+		}
+		if rule.Drop && !w.Emergency {
+			w.debugf("dingtalk %s: message dropped by rule %q", w.channel(), rule.Name)
+			return false
+		}
+		if nil != rule.Rewrite {
+			rewriteContent(payload, rule.Rewrite)
+		}
+		if 0 != len(rule.Mention) {
+			addMentions(payload, rule.Mention)
+		}
+	}
+
+	return true
+}
+
+// addMentions mention more members in a text or markdown payload
+func addMentions(payload *PayLoad, options []AtOption) {
+	schema, _ := SchemaFor(payload.MsgType)
+	if !schema.AtSupport {
+		return
+	}
+
+	if nil == payload.At {
+		payload.At = &At{}
+	}
+	for _, option := range options {
+		option(payload.At)
+	}
+	rewriteContent(payload, func(content string) string {
+		return message.AppendMentions(content, payload.At)
+	})
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestRules(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithRules(
+		Rule{Name: "no debug in prod", When: AllOf(AttributeIn("env", "prod"), AttributeIn("severity", "debug")), Drop: true},
+		Rule{Name: "shout", When: AttributeIn("severity", "critical"), Rewrite: strings.ToUpper},
+		Rule{Name: "dba", When: ContentMatches(regexp.MustCompile(`(?i)mysql`)), Mention: []AtOption{AtMobiles("13800000000")}},
+	))
+	webHook.APIURL = server.URL
+
+	ctx := WithAttributes(context.Background(), Attributes{"env": "prod"})
+	if err := webHook.SendContext(WithAttributes(ctx, Attributes{"severity": "DEBUG"}), &TextMessage{Content: "cache miss"}); ErrDropped != err {
+		t.Errorf("dropped error should be catch! got %v", err)
+	}
+	if 0 != len(server.Requests()) {
+		t.Fatal("dropped message should not be sent")
+	}
+
+	if _, err := webHook.SendWithResultContext(WithAttributes(ctx, Attributes{"severity": "critical"}), &TextMessage{Content: "mysql is down"}); nil != err {
+		t.Fatal(err)
+	}
+	payload, _ := server.Requests()[0].Payload()
+	if !strings.HasPrefix(payload.Text.Content, "MYSQL IS DOWN") || !strings.HasSuffix(payload.Text.Content, "@13800000000") {
+		t.Errorf("content should be rewritten and mention the dba, got %q", payload.Text.Content)
+	}
+	if 1 != len(payload.At.AtMobiles) {
+		t.Errorf("dba should be mentioned, got %+v", payload.At)
+	}
+
+	if err := webHook.Send(&LinkMessage{Title: "mysql", Text: "mysql slow log", MessageURL: "https://example.com"}); nil != err {
+		t.Fatal(err)
+	}
+	payload, _ = server.Requests()[1].Payload()
+	if 0 != len(payload.At.AtMobiles) {
+		t.Error("mentions should not be added to link messages")
+	}
+}
+
+func TestRulesMentionBoundary(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token", WithRules(Rule{Name: "lead", Mention: []AtOption{AtUserIDs("u1")}}))
+	webHook.APIURL = server.URL
+	if err := webHook.Send(&MarkdownMessage{Title: "t", Text: "assigned to @u12", AtUserIDs: []string{"u12"}}); nil != err {
+		t.Fatal(err)
+	}
+
+	payload, _ := server.Requests()[0].Payload()
+	if "assigned to @u12\n\n@u1" != payload.Markdown.Text {
+		t.Errorf("@u1 should be added although @u12 is there, got %q", payload.Markdown.Text)
+	}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeText `make text safe for DingTalk: invalid utf-8 becomes U+FFFD, control characters other than tab and newline are dropped`
+//
+// Carriage returns are turned into newlines, so output piped from Windows hosts or progress bars keeps its line breaks.
+func SanitizeText(s string) string {
+	if isClean(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case utf8.RuneError == r && 1 == size:
+			b.WriteRune(utf8.RuneError)
+		case '\r' == r:
+			if i < len(s) && '\n' == s[i] {
+				continue
+			}
+			b.WriteByte('\n')
+		case '\n' == r || '\t' == r:
+			b.WriteRune(r)
+		case unicode.IsControl(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// isClean no rewrite needed, the common case
+func isClean(s string) bool {
+	for _, r := range s {
+		if utf8.RuneError == r || ('\n' != r && '\t' != r && unicode.IsControl(r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sanitizePayload run SanitizeText over every human readable field, urls are left alone
+func sanitizePayload(payload *PayLoad) {
+	if nil != payload.Text {
+		payload.Text.Content = SanitizeText(payload.Text.Content)
+	}
+	if nil != payload.Link {
+		payload.Link.Title = SanitizeText(payload.Link.Title)
+		payload.Link.Text = SanitizeText(payload.Link.Text)
+	}
+	if nil != payload.Markdown {
+		payload.Markdown.Title = SanitizeText(payload.Markdown.Title)
+		payload.Markdown.Text = SanitizeText(payload.Markdown.Text)
+	}
+	if nil != payload.ActionCard {
+		payload.ActionCard.Title = SanitizeText(payload.ActionCard.Title)
+		payload.ActionCard.Text = SanitizeText(payload.ActionCard.Text)
+		payload.ActionCard.SingleTitle = SanitizeText(payload.ActionCard.SingleTitle)
+		for i := range payload.ActionCard.Buttons {
+			payload.ActionCard.Buttons[i].Title = SanitizeText(payload.ActionCard.Buttons[i].Title)
+		}
+	}
+	if nil != payload.FeedCard {
+		for i := range payload.FeedCard.Links {
+			payload.FeedCard.Links[i].Title = SanitizeText(payload.FeedCard.Links[i].Title)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSanitizeText(t *testing.T) {
+	cases := map[string]string{
+		"plain\ttext\n":       "plain\ttext\n",
+		"line\r\nbreak\rhere": "line\nbreak\nhere",
+		"bell\a esc\x1b[31m":  "bell esc[31m",
+		"nul\x00 del\x7f":     "nul del",
+		"bad \xff\xfe utf8":   "bad \ufffd\ufffd utf8",
+		"c1 \u0085control":    "c1 control",
+		"中文\u00a0nbsp kept":   "中文\u00a0nbsp kept",
+	}
+	for in, want := range cases {
+		if got := SanitizeText(in); want != got {
+			t.Errorf("SanitizeText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSendSanitizes(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	if err := webHook.SendMarkdownMsg("build\x1b[0m", "log:\r\n\x00\xffdone", false); nil != err {
+		t.Fatal(err)
+	}
+	payload, _ := server.Requests()[0].Payload()
+	if "build[0m" != payload.Markdown.Title || "log:\n\ufffddone" != payload.Markdown.Text {
+		t.Errorf("payload should be sanitized, got %q %q", payload.Markdown.Title, payload.Markdown.Text)
+	}
+}
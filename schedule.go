@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrScheduleCancelled `reported by a ScheduledSend stopped before it was sent`
+var ErrScheduleCancelled = errors.New("scheduled send cancelled")
+
+// ScheduledSend `handle of a message scheduled with SendAt or SendAfter`
+type ScheduledSend struct {
+	At time.Time
+
+	timer *time.Timer
+	done  chan struct{}
+	once  sync.Once
+	err   error
+}
+
+// SendAt `send msg at t from a background timer, t in the past sends right away`
+//
+// The payload is built and checked immediately, the schedule only lives in this process. Use
+// QueuedWebHook.SendAt for schedules that survive a restart.
+func (w *WebHook) SendAt(t time.Time, msg Message) (*ScheduledSend, error) {
+	payload, err := msg.Payload()
+	if nil != err {
+		return nil, err
+	}
+
+	s := &ScheduledSend{At: t, done: make(chan struct{})}
+	s.timer = time.AfterFunc(time.Until(t), func() {
+		s.finish(w.sendPayloadContext(context.Background(), payload))
+	})
+
+	return s, nil
+}
+
+// SendAfter `send msg once d has passed`
+func (w *WebHook) SendAfter(d time.Duration, msg Message) (*ScheduledSend, error) {
+	return w.SendAt(time.Now().Add(d), msg)
+}
+
+// Cancel `stop the send, false when it already happened or was cancelled`
+func (s *ScheduledSend) Cancel() bool {
+	if !s.timer.Stop() {
+		return false
+	}
+
+	s.finish(ErrScheduleCancelled)
+	return true
+}
+
+// Done `closed once the message was sent, failed or cancelled`
+func (s *ScheduledSend) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err `outcome of the send, nil until Done is closed`
+func (s *ScheduledSend) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+func (s *ScheduledSend) finish(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendAfter(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	reminder, err := webHook.SendAfter(10*time.Millisecond, &TextMessage{Content: "stand-up in 5 minutes"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	cancelled, _ := webHook.SendAt(time.Now().Add(time.Hour), &TextMessage{Content: "maintenance window"})
+	if nil != reminder.Err() || 0 != len(server.Requests()) {
+		t.Fatal("message should not be sent before its time")
+	}
+
+	<-reminder.Done()
+	if nil != reminder.Err() || 1 != len(server.Requests()) {
+		t.Errorf("scheduled message should be sent, got %v", reminder.Err())
+	}
+	if reminder.Cancel() {
+		t.Error("sent message should not be cancellable")
+	}
+
+	if !cancelled.Cancel() || ErrScheduleCancelled != cancelled.Err() {
+		t.Errorf("pending message should be cancelled, got %v", cancelled.Err())
+	}
+
+	if _, err := webHook.SendAfter(time.Second, &LinkMessage{At: []AtOption{AtAll()}}); nil == err {
+		t.Error("invalid message error should be catch!")
+	}
+}
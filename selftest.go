@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// selfTestBody a payload DingTalk refuses after checking token, signature and ip, so nothing is posted
+var selfTestBody = []byte(`{"msgtype":"selftest"}`)
+
+// SelfTestResult `outcome of checking one robot`
+type SelfTestResult struct {
+	Name    string
+	Err     error
+	Latency time.Duration
+}
+
+// Healthy `token, signature and ip whitelist were accepted`
+func (r SelfTestResult) Healthy() bool {
+	return nil == r.Err
+}
+
+// SelfTestReport `outcome of SelfTest, sorted by robot name`
+type SelfTestReport struct {
+	Results []SelfTestResult
+	Time    time.Time
+}
+
+// Err `a *MultiError naming the unhealthy robots, nil when all are healthy`
+func (r *SelfTestReport) Err() error {
+	multi := &MultiError{}
+	for _, result := range r.Results {
+		multi.add(result.Name, result.Err)
+	}
+
+	return multi.Err()
+}
+
+// Markdown `summary for an admin channel`
+func (r *SelfTestReport) Markdown() *MarkdownMessage {
+	healthy := 0
+	var lines []string
+	for _, result := range r.Results {
+		if result.Healthy() {
+			healthy++
+			lines = append(lines, fmt.Sprintf("- ✅ %s (%s)", result.Name, result.Latency.Round(time.Millisecond)))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- ❌ %s: %v", result.Name, result.Err))
+	}
+
+	title := fmt.Sprintf("robot self-test: %d/%d healthy", healthy, len(r.Results))
+	body := NewMarkdownBuilder().H4(title).Text(strings.Join(lines, "\n")).Text("time: " + FormatTime(r.Time))
+	return body.Build()
+}
+
+// SelfTest `check every robot can be reached with its token, signature and ip, and report to admin`
+//
+// Robots are probed with a payload DingTalk rejects only after its security checks, so no group
+// receives a message. admin may be nil to skip the summary, the error lists the unhealthy robots.
+func SelfTest(ctx context.Context, robots map[string]*WebHook, admin *WebHook) (*SelfTestReport, error) {
+	names := make([]string, 0, len(robots))
+	for name := range robots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := &SelfTestReport{Time: time.Now()}
+	for _, name := range names {
+		start := time.Now()
+		err := robots[name].selfTest(ctx)
+		report.Results = append(report.Results, SelfTestResult{Name: name, Err: err, Latency: time.Since(start)})
+	}
+
+	if nil != admin {
+		if err := admin.SendContext(ctx, report.Markdown()); nil != err {
+			return report, err
+		}
+	}
+	return report, report.Err()
+}
+
+// selfTest probe the robot, api errors other than security failures mean it got past them
+func (w *WebHook) selfTest(ctx context.Context) error {
+	_, err := w.checkResult(w.postBody(ctx, selfTestBody))
+
+	var apiErr *APIError
+	switch {
+	case nil == err:
+		return nil
+	case errors.Is(err, ErrKeywordMismatch):
+		//  keywords are checked last, the robot itself is fine
+		return nil
+	case errors.As(err, &apiErr) && 300001 != apiErr.Code && 310000 != apiErr.Code:
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSelfTest(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	admin := webhooktest.NewServer()
+	defer admin.Close()
+
+	robot := func(token, secret string) *WebHook {
+		w := NewWebHook(token)
+		w.APIURL = server.URL
+		w.Secret = secret
+		return w
+	}
+	robots := map[string]*WebHook{
+		"alerts":  robot("a", "secret"),
+		"deploys": robot("b", "wrong"),
+		"empty":   robot("", ""),
+	}
+	server.Secure(webhooktest.Security{Secret: "secret", Keywords: []string{"[ops]"}})
+	adminHook := NewWebHook("admin")
+	adminHook.APIURL = admin.URL
+
+	report, err := SelfTest(context.Background(), robots, adminHook)
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("multi error should be catch! got %v", err)
+	}
+	if failed := multi.Failed(); 2 != len(failed) || "deploys" != failed[0] || "empty" != failed[1] {
+		t.Errorf("deploys and empty should fail, got %v", failed)
+	}
+	if !errors.Is(err, ErrSignMismatch) || !report.Results[0].Healthy() {
+		t.Errorf("alerts should pass the keyword robot, got %+v", report.Results)
+	}
+	if 2 != len(server.Requests()) {
+		t.Errorf("robots without token should not be probed, got %d requests", len(server.Requests()))
+	}
+
+	sent := admin.Requests()
+	if 1 != len(sent) {
+		t.Fatalf("one summary expected, got %d", len(sent))
+	}
+	payload, _ := sent[0].Payload()
+	if "robot self-test: 1/3 healthy" != payload.Markdown.Title || !strings.Contains(payload.Markdown.Text, "❌ deploys") {
+		t.Errorf("unexpected summary %+v", payload.Markdown)
+	}
+}
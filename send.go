@@ -0,0 +1,18 @@
+package webhook
+
+import "context"
+
+// Send `send any message`
+func (w *WebHook) Send(msg Message) error {
+	return w.SendContext(context.Background(), msg)
+}
+
+// SendContext `send any message, bounded by ctx`
+func (w *WebHook) SendContext(ctx context.Context, msg Message) error {
+	payload, err := msg.Payload()
+	if nil != err {
+		return err
+	}
+
+	return w.sendPayloadContext(ctx, payload)
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import "github.com/lddsb/dingtalk-webhook/sign"
+
+// SignVector `a known timestamp/secret/sign triple, see package sign`
+type SignVector = sign.Vector
+
+// ReferenceVectors `signatures every DingTalk signing implementation must reproduce`
+var ReferenceVectors = sign.ReferenceVectors
+
+// Sign `DingTalk robot signature of a millisecond timestamp, before url encoding`
+func Sign(secret string, timestamp int64) string {
+	return sign.Sign(secret, timestamp)
+}
+
+// VerifySigner `check a signing function against ReferenceVectors`
+func VerifySigner(fn func(secret string, timestamp int64) string) error {
+	return sign.VerifySigner(fn)
+}
+
+// VerifyReferenceVectors `check this library's signing, including query encoding`
+func VerifyReferenceVectors() error {
+	return sign.VerifyReferenceVectors()
+}
@@ -0,0 +1,85 @@
+// Package sign implements the DingTalk robot request signature.
+//
+// It has no dependency on the rest of the library, so proxies and receivers can verify
+// signatures without importing the message and transport code.
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Sign `DingTalk robot signature of a millisecond timestamp, before url encoding`
+func Sign(secret string, timestamp int64) string {
+	message := strconv.FormatInt(timestamp, 10) + "\n" + secret
+
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(message))
+
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Vector `a known timestamp/secret/sign triple`
+type Vector struct {
+	Timestamp int64
+	Secret    string
+	//  base64 signature as returned by Sign
+	Sign string
+	//  sign as it appears in the request query string
+	Query string
+}
+
+// ReferenceVectors `signatures every DingTalk signing implementation must reproduce`
+//
+// They were computed independently of this package with a stock HMAC-SHA256, use them to check
+// proxies or implementations in other languages.
+var ReferenceVectors = []Vector{
+	{
+		Timestamp: 1577836800000,
+		Secret:    "SEC000000000000000000000000000000000000000000000000000000000000000",
+		Sign:      "Hn4QXeBUPUzS5COoMMFE5Tcr7VtbYqokWBziOgXyEe4=",
+		Query:     "Hn4QXeBUPUzS5COoMMFE5Tcr7VtbYqokWBziOgXyEe4%3D",
+	},
+	{
+		Timestamp: 1700000000123,
+		Secret:    "SECa1b2c3d4e5f6",
+		Sign:      "8qyxAWyAXc7Bpg17qcXGE4eyXykQN+pvv3WCK8lkKsU=",
+		Query:     "8qyxAWyAXc7Bpg17qcXGE4eyXykQN%2Bpvv3WCK8lkKsU%3D",
+	},
+	{
+		Timestamp: 1609459200000,
+		Secret:    "密钥-unicode",
+		Sign:      "RKGTl0zxY6K5ybdAaWw8wr0DLTa7m50kH/Jxj7ThkQo=",
+		Query:     "RKGTl0zxY6K5ybdAaWw8wr0DLTa7m50kH%2FJxj7ThkQo%3D",
+	},
+}
+
+// VerifySigner `check a signing function against ReferenceVectors`
+func VerifySigner(sign func(secret string, timestamp int64) string) error {
+	for _, vector := range ReferenceVectors {
+		if got := sign(vector.Secret, vector.Timestamp); got != vector.Sign {
+			return fmt.Errorf("sign mismatch for timestamp %d: want %s, got %s", vector.Timestamp, vector.Sign, got)
+		}
+	}
+
+	return nil
+}
+
+// VerifyReferenceVectors `check this package's signing, including query encoding`
+func VerifyReferenceVectors() error {
+	if err := VerifySigner(Sign); nil != err {
+		return err
+	}
+
+	for _, vector := range ReferenceVectors {
+		if got := url.QueryEscape(Sign(vector.Secret, vector.Timestamp)); got != vector.Query {
+			return fmt.Errorf("sign encoding mismatch for timestamp %d: want %s, got %s", vector.Timestamp, vector.Query, got)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package sign
+
+import "testing"
+
+func TestVerifyReferenceVectors(t *testing.T) {
+	if err := VerifyReferenceVectors(); nil != err {
+		t.Fatal(err)
+	}
+
+	broken := func(secret string, timestamp int64) string { return Sign(secret+" ", timestamp) }
+	if err := VerifySigner(broken); nil == err {
+		t.Error("wrong signer should be reported")
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifyReferenceVectors(t *testing.T) {
+	if err := VerifyReferenceVectors(); nil != err {
+		t.Fatal(err)
+	}
+
+	broken := func(secret string, timestamp int64) string { return Sign(secret+" ", timestamp) }
+	if err := VerifySigner(broken); nil == err {
+		t.Error("wrong signer should be reported")
+	}
+}
+
+func TestSignedURLMatchesVectors(t *testing.T) {
+	vector := ReferenceVectors[1]
+	signed := addParamsToURL(map[string]string{"timestamp": "1700000000123", "sign": vector.Sign}, "https://oapi.dingtalk.com/robot/send?access_token=x")
+
+	u, _ := url.Parse(signed)
+	if vector.Sign != u.Query().Get("sign") {
+		t.Errorf("sign should survive url encoding, got %s", u.RawQuery)
+	}
+	if !strings.Contains(u.RawQuery, "sign="+vector.Query) {
+		t.Errorf("sign should be encoded as %s, got %s", vector.Query, u.RawQuery)
+	}
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// ErrSilenced `returned instead of sending when a message matches an active silence`
+var ErrSilenced = errors.New("message silenced")
+
+// Matcher `decide whether a payload is covered by a silence`
+type Matcher interface {
+	Match(payload *PayLoad) bool
+}
+
+// MatcherFunc `adapt a plain function to Matcher`
+type MatcherFunc func(payload *PayLoad) bool
+
+// Match `implement Matcher`
+func (f MatcherFunc) Match(payload *PayLoad) bool {
+	return f(payload)
+}
+
+// RegexpMatcher `match payloads whose content matches a regexp`
+type RegexpMatcher struct {
+	*regexp.Regexp
+}
+
+// Match `implement Matcher`
+func (m RegexpMatcher) Match(payload *PayLoad) bool {
+	return m.MatchString(payloadContent(payload))
+}
+
+// MatchContent `compile a content matcher, e.g. MatchContent("disk usage on db-\\d+")`
+func MatchContent(expr string) (Matcher, error) {
+	re, err := regexp.Compile(expr)
+	if nil != err {
+		return nil, err
+	}
+
+	return RegexpMatcher{re}, nil
+}
+
+// Silence `a temporary mute of matching messages`
+type Silence struct {
+	ID       int
+	Matcher  Matcher
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// Silence `mute messages matched by matcher for the given duration`
+func (w *WebHook) Silence(matcher Matcher, duration time.Duration) Silence {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.nextSilence++
+	now := time.Now()
+	silence := Silence{ID: w.nextSilence, Matcher: matcher, StartsAt: now, EndsAt: now.Add(duration)}
+	w.silences = append(w.silences, silence)
+
+	return silence
+}
+
+// Unsilence `lift a silence before it expires, reports whether it was active`
+func (w *WebHook) Unsilence(id int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, silence := range w.silences {
+		if silence.ID == id {
+			w.silences = append(w.silences[:i], w.silences[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// ActiveSilences `silences which have not expired yet`
+func (w *WebHook) ActiveSilences() []Silence {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pruneSilences(time.Now())
+	return append([]Silence(nil), w.silences...)
+}
+
+// silenced report whether payload is muted right now
+func (w *WebHook) silenced(payload *PayLoad) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pruneSilences(time.Now())
+	for _, silence := range w.silences {
+		if silence.Matcher.Match(payload) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pruneSilences drop expired silences, caller holds the lock
+func (w *WebHook) pruneSilences(now time.Time) {
+	active := w.silences[:0]
+	for _, silence := range w.silences {
+		if now.Before(silence.EndsAt) {
+			active = append(active, silence)
+		}
+	}
+	w.silences = active
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSilence(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	matcher, err := MatchContent(`disk usage on db-\d+`)
+	if nil != err {
+		t.Fatal(err)
+	}
+	silence := webHook.Silence(matcher, time.Hour)
+	webHook.Silence(MatcherFunc(func(*PayLoad) bool { return true }), -time.Second)
+
+	if active := webHook.ActiveSilences(); 1 != len(active) || silence.ID != active[0].ID {
+		t.Fatalf("only the unexpired silence should be active, got %v", active)
+	}
+
+	if err := webHook.SendTextMsg("disk usage on db-3 is 95%", false); ErrSilenced != err {
+		t.Errorf("matching message should be silenced, got %v", err)
+	}
+	if err := webHook.SendTextMsg("cpu usage on db-3 is 95%", false); nil != err {
+		t.Errorf("other messages should be sent, got %v", err)
+	}
+
+	if !webHook.Unsilence(silence.ID) || webHook.Unsilence(silence.ID) {
+		t.Error("silence should be lifted exactly once")
+	}
+	if err := webHook.SendTextMsg("disk usage on db-3 is 95%", false); nil != err {
+		t.Errorf("message should be sent after unsilence, got %v", err)
+	}
+	if 2 != hits {
+		t.Errorf("2 requests should reach the api, got %d", hits)
+	}
+}
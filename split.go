@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxContentLength `longest text or markdown body DingTalk accepts, in characters`
+const MaxContentLength = 20000
+
+// checkSize reject bodies DingTalk would refuse with an opaque error
+func checkSize(payload *PayLoad) error {
+	field, body := "", ""
+	switch {
+	case "text" == payload.MsgType && nil != payload.Text:
+		field, body = "text.content", payload.Text.Content
+	case "markdown" == payload.MsgType && nil != payload.Markdown:
+		field, body = "markdown.text", payload.Markdown.Text
+	case "actionCard" == payload.MsgType && nil != payload.ActionCard:
+		field, body = "actionCard.text", payload.ActionCard.Text
+	default:
+		return nil
+	}
+
+	if n := utf8.RuneCountInString(body); n > MaxContentLength {
+		return &ValidationError{MsgType: payload.MsgType, Violations: []string{
+			fmt.Sprintf("%s: longer than %d characters (%d), set AutoSplit to send it in parts", field, MaxContentLength, n),
+		}}
+	}
+
+	return nil
+}
+
+// splitPayload oversized text and markdown payloads as numbered parts, others as is
+//
+// Mentions go with the last part, which is where markdown mentions are rendered.
+func (w *WebHook) splitPayload(payload *PayLoad) []*PayLoad {
+	var body string
+	switch {
+	case "text" == payload.MsgType && nil != payload.Text:
+		body = payload.Text.Content
+	case "markdown" == payload.MsgType && nil != payload.Markdown:
+		body = payload.Markdown.Text
+	default:
+		return []*PayLoad{payload}
+	}
+	//  the keyword and footers are added to every part later on
+	limit := MaxContentLength - w.decorationLength(payload)
+	if utf8.RuneCountInString(body) <= limit {
+		return []*PayLoad{payload}
+	}
+
+	//  leave room for the part label
+	chunks := SplitContent(body, limit-32)
+	parts := make([]*PayLoad, len(chunks))
+	for i, chunk := range chunks {
+		label := fmt.Sprintf("(%d/%d)", i+1, len(chunks))
+		part := &PayLoad{MsgType: payload.MsgType}
+		if "text" == payload.MsgType {
+			part.Text = &Text{Content: label + " " + chunk}
+		} else {
+			part.Markdown = &Markdown{Title: payload.Markdown.Title + " " + label, Text: "**" + label + "**\n\n" + chunk}
+		}
+		if i == len(chunks)-1 {
+			part.At = payload.At
+		}
+		parts[i] = part
+	}
+
+	return parts
+}
+
+// decorationLength characters prepare adds to the body of payload, measured on an empty copy
+func (w *WebHook) decorationLength(payload *PayLoad) int {
+	probe := clonePayload(payload)
+	rewriteContent(probe, func(string) string {
+		return ""
+	})
+	if nil != w.Transform {
+		w.Transform.Apply(probe)
+	}
+	w.decorate(probe, strings.Repeat("0", 12))
+
+	length := 0
+	rewriteContent(probe, func(content string) string {
+		length = utf8.RuneCountInString(content)
+		return content
+	})
+
+	return length
+}
+
+// SplitContent `cut content into chunks of at most limit characters`
+//
+// Chunks end at a paragraph break when possible, then at a line break, and only mid-line for
+// lines longer than limit.
+func SplitContent(content string, limit int) []string {
+	if limit < 1 {
+		limit = 1
+	}
+
+	var chunks []string
+	for utf8.RuneCountInString(content) > limit {
+		window := content[:runeOffset(content, limit)]
+		end, next := len(window), len(window)
+		if i := strings.LastIndex(window, "\n\n"); 0 < i {
+			end, next = i, i+2
+		} else if i := strings.LastIndex(window, "\n"); 0 < i {
+			end, next = i, i+1
+		}
+
+		chunks = append(chunks, content[:end])
+		content = content[next:]
+	}
+
+	return append(chunks, content)
+}
+
+// runeOffset byte offset of the n-th rune of s
+func runeOffset(s string, n int) int {
+	for offset := range s {
+		if 0 == n {
+			return offset
+		}
+		n--
+	}
+
+	return len(s)
+}
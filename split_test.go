@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSplitContent(t *testing.T) {
+	chunks := SplitContent("para one\n\npara two\nline three", 20)
+	if 2 != len(chunks) || "para one" != chunks[0] || "para two\nline three" != chunks[1] {
+		t.Errorf("chunks should end at paragraph breaks, got %q", chunks)
+	}
+
+	chunks = SplitContent("line one\nline two", 12)
+	if 2 != len(chunks) || "line one" != chunks[0] || "line two" != chunks[1] {
+		t.Errorf("chunks should end at line breaks, got %q", chunks)
+	}
+
+	chunks = SplitContent(strings.Repeat("告警", 5), 4)
+	if 3 != len(chunks) || "告警告警" != chunks[0] || "告警" != chunks[2] {
+		t.Errorf("long lines should be cut at rune boundaries, got %q", chunks)
+	}
+}
+
+func TestAutoSplit(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+
+	line := strings.Repeat("x", 99) + "\n"
+	long := strings.Repeat(line, 450)
+	var validation *ValidationError
+	if err := webHook.SendMarkdownMsg("report", long, false, "13800138000"); !errors.As(err, &validation) {
+		t.Fatalf("oversized body error should be catch!, got %v", err)
+	}
+	if 0 != len(server.Requests()) {
+		t.Fatal("oversized body should not be sent")
+	}
+
+	webHook.AutoSplit = true
+	if err := webHook.SendMarkdownMsg("report", long, false, "13800138000"); nil != err {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if 3 != len(requests) {
+		t.Fatalf("3 parts expected, got %d", len(requests))
+	}
+
+	var total int
+	for i, request := range requests {
+		payload, _ := request.Payload()
+		if n := utf8.RuneCountInString(payload.Markdown.Text); n > MaxContentLength {
+			t.Errorf("part %d is too long: %d", i, n)
+		}
+		total += strings.Count(payload.Markdown.Text, line[:99])
+		if 2 == i {
+			webhooktest.AssertAtMobiles(t, request, "13800138000")
+		} else {
+			webhooktest.AssertAtMobiles(t, request)
+		}
+	}
+	first, _ := requests[0].Payload()
+	if "report (1/3)" != first.Markdown.Title || !strings.HasPrefix(first.Markdown.Text, "**(1/3)**\n\n") {
+		t.Errorf("parts should be numbered, got %q", first.Markdown.Title)
+	}
+	if 450 != total {
+		t.Errorf("every line should be sent once, got %d", total)
+	}
+}
+
+func TestAutoSplitDecorated(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.AutoSplit = true
+	webHook.Keywords = []string{"alert"}
+	webHook.Sender = SenderIdentity{Service: "billing", Team: "payments"}
+	webHook.SenderFooter = true
+	webHook.IdempotencyFooter = true
+
+	long := strings.Repeat("x", 39981)
+	if err := webHook.SendText(long); nil != err {
+		t.Fatal(err)
+	}
+	total := 0
+	for i, request := range server.Requests() {
+		payload, _ := request.Payload()
+		if n := utf8.RuneCountInString(payload.Text.Content); n > MaxContentLength {
+			t.Errorf("decorated part %d is too long: %d", i, n)
+		}
+		total += strings.Count(payload.Text.Content, "x")
+	}
+	if len(long) != total {
+		t.Errorf("every character should be sent once, got %d", total)
+	}
+
+	sent := len(server.Requests())
+	if err := webHook.SendText(strings.Repeat("x", MaxContentLength-10)); nil != err {
+		t.Fatal(err)
+	}
+	if sent+2 != len(server.Requests()) {
+		t.Errorf("body fitting only without decorations should be split, got %d requests", len(server.Requests()))
+	}
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ContextExtractor `pull a value out of a context for use in templates`
+type ContextExtractor func(ctx context.Context) interface{}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string]ContextExtractor{
+		"deadline_remaining": deadlineRemaining,
+	}
+)
+
+// RegisterContextExtractor `make a context value available in templates as {{ ctx "name" }}`
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors[name] = extractor
+}
+
+// ContextValue `extractor reading ctx.Value(key), for values stored with context.WithValue`
+func ContextValue(key interface{}) ContextExtractor {
+	return func(ctx context.Context) interface{} {
+		return ctx.Value(key)
+	}
+}
+
+// ContextFuncs `template functions bound to ctx, plus formatTime and relativeTime`
+func ContextFuncs(ctx context.Context) template.FuncMap {
+	return template.FuncMap{
+		"ctx": func(name string) (interface{}, error) {
+			extractorsMu.RLock()
+			extractor, ok := extractors[name]
+			extractorsMu.RUnlock()
+			if !ok {
+				return nil, fmt.Errorf("context extractor %q is not registered", name)
+			}
+
+			return extractor(ctx), nil
+		},
+		"formatTime":   FormatTime,
+		"relativeTime": FormatRelative,
+	}
+}
+
+// NewTemplate `a template which understands the ctx function`
+func NewTemplate(name string) *template.Template {
+	return template.New(name).Funcs(ContextFuncs(context.Background()))
+}
+
+// ExecuteTemplate `render a template created by NewTemplate with values taken from ctx`
+func ExecuteTemplate(ctx context.Context, tmpl *template.Template, data interface{}) (string, error) {
+	//  clone so concurrent renders do not share bound functions
+	bound, err := tmpl.Clone()
+	if nil != err {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := bound.Funcs(ContextFuncs(ctx)).Execute(&buf, data); nil != err {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// RenderTemplate `parse and render text in one go`
+func RenderTemplate(ctx context.Context, text string, data interface{}) (string, error) {
+	tmpl, err := NewTemplate("inline").Parse(text)
+	if nil != err {
+		return "", err
+	}
+
+	return ExecuteTemplate(ctx, tmpl, data)
+}
+
+// time left before the context deadline, empty when there is none
+func deadlineRemaining(ctx context.Context) interface{} {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ""
+	}
+
+	return time.Until(deadline).Round(time.Second).String()
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+func TestRenderTemplateContext(t *testing.T) {
+	RegisterContextExtractor("request_id", ContextValue(requestIDKey{}))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	out, err := RenderTemplate(ctx, `{{.Service}} failed [{{ ctx "request_id" }}] {{ ctx "deadline_remaining" }}`, map[string]string{"Service": "billing"})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "billing failed [req-42] 1m0s") && !strings.HasPrefix(out, "billing failed [req-42] 59s") {
+		t.Errorf("unexpected render: %q", out)
+	}
+
+	if _, err := RenderTemplate(ctx, `{{ ctx "missing" }}`, nil); nil == err {
+		t.Error("unregistered extractor error should be catch!")
+	}
+}
+
+func TestExecuteTemplateConcurrent(t *testing.T) {
+	RegisterContextExtractor("request_id", ContextValue(requestIDKey{}))
+	tmpl := NewTemplate("alert").Option("missingkey=error")
+	tmpl, err := tmpl.Parse(`{{ ctx "request_id" }}`)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	done := make(chan string, 2)
+	for _, id := range []string{"a", "b"} {
+		go func(id string) {
+			out, _ := ExecuteTemplate(context.WithValue(context.Background(), requestIDKey{}, id), tmpl, nil)
+			done <- id + "=" + out
+		}(id)
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-done; got != "a=a" && got != "b=b" {
+			t.Errorf("context leaked between renders: %s", got)
+		}
+	}
+}
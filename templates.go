@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"text/template"
+)
+
+// MessageTemplate `a notification format rendered with text/template`
+//
+// Title, Text, SingleTitle, SingleURL and the button fields are templates executed with the data
+// passed to SendTemplate, the ctx function of NewTemplate is available in all of them.
+type MessageTemplate struct {
+	//  text, markdown or actionCard
+	MsgType        string
+	Title          string
+	Text           string
+	SingleTitle    string
+	SingleURL      string
+	Buttons        []Button
+	BtnOrientation bool
+	At             []AtOption
+}
+
+// compiledTemplate parsed fields of a MessageTemplate
+type compiledTemplate struct {
+	source  MessageTemplate
+	title   *template.Template
+	text    *template.Template
+	single  [2]*template.Template
+	buttons [][2]*template.Template
+}
+
+// TemplateRegistry `named message templates`
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]*compiledTemplate
+	//  directory each template was loaded from by LoadDir
+	dirs map[string]string
+}
+
+// NewTemplateRegistry `empty registry`
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{templates: make(map[string]*compiledTemplate)}
+}
+
+// DefaultTemplates `registry used by RegisterTemplate and by robots without Templates`
+var DefaultTemplates = NewTemplateRegistry()
+
+// RegisterTemplate `register tmpl in DefaultTemplates`
+func RegisterTemplate(name string, tmpl MessageTemplate) error {
+	return DefaultTemplates.Register(name, tmpl)
+}
+
+// Register `parse tmpl and store it under name, replacing any previous one`
+func (r *TemplateRegistry) Register(name string, tmpl MessageTemplate) error {
+	compiled, err := compileTemplate(name, tmpl)
+	if nil != err {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = compiled
+
+	return nil
+}
+
+// Names `registered template names`
+func (r *TemplateRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Render `render the template name into a message`
+func (r *TemplateRegistry) Render(ctx context.Context, name string, data interface{}) (Message, error) {
+	r.mu.RLock()
+	compiled, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("template %q is not registered", name)
+	}
+
+	return compiled.render(ctx, data)
+}
+
+func compileTemplate(name string, tmpl MessageTemplate) (*compiledTemplate, error) {
+	switch tmpl.MsgType {
+	case "text", "markdown", "actionCard":
+	default:
+		return nil, fmt.Errorf("template %s: msgtype %q can not be templated", name, tmpl.MsgType)
+	}
+
+	var err error
+	parse := func(field, text string) *template.Template {
+		if nil != err || "" == text {
+			return nil
+		}
+		var parsed *template.Template
+		if parsed, err = NewTemplate(name + "." + field).Parse(text); nil != err {
+			err = fmt.Errorf("template %s: %v", name, err)
+		}
+		return parsed
+	}
+
+	compiled := &compiledTemplate{
+		source: tmpl,
+		title:  parse("title", tmpl.Title),
+		text:   parse("text", tmpl.Text),
+		single: [2]*template.Template{parse("singleTitle", tmpl.SingleTitle), parse("singleURL", tmpl.SingleURL)},
+	}
+	for i, button := range tmpl.Buttons {
+		compiled.buttons = append(compiled.buttons, [2]*template.Template{
+			parse(fmt.Sprintf("buttons[%d].title", i), button.Title),
+			parse(fmt.Sprintf("buttons[%d].actionURL", i), button.ActionURL),
+		})
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return compiled, nil
+}
+
+func (c *compiledTemplate) render(ctx context.Context, data interface{}) (Message, error) {
+	var err error
+	execute := func(tmpl *template.Template) string {
+		if nil != err || nil == tmpl {
+			return ""
+		}
+		var out string
+		out, err = ExecuteTemplate(ctx, tmpl, data)
+		return out
+	}
+
+	title, text := execute(c.title), execute(c.text)
+	var msg Message
+	switch c.source.MsgType {
+	case "text":
+		msg = &TextMessage{Content: text, At: c.source.At}
+	case "markdown":
+		msg = &MarkdownMessage{Title: title, Text: text, At: c.source.At}
+	case "actionCard":
+		card := &ActionCardMessage{
+			Title:          title,
+			Text:           text,
+			SingleTitle:    execute(c.single[0]),
+			SingleURL:      execute(c.single[1]),
+			BtnOrientation: c.source.BtnOrientation,
+		}
+		for _, button := range c.buttons {
+			card.Buttons = append(card.Buttons, Button{Title: execute(button[0]), ActionURL: execute(button[1])})
+		}
+		msg = card
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// templates registry of this robot
+func (w *WebHook) templates() *TemplateRegistry {
+	if nil != w.Templates {
+		return w.Templates
+	}
+
+	return DefaultTemplates
+}
+
+// SendTemplate `render a registered template with data and send it`
+func (w *WebHook) SendTemplate(name string, data interface{}) error {
+	return w.SendTemplateContext(context.Background(), name, data)
+}
+
+// SendTemplateContext `render a registered template with data and send it, bounded by ctx`
+func (w *WebHook) SendTemplateContext(ctx context.Context, name string, data interface{}) error {
+	msg, err := w.templates().Render(ctx, name, data)
+	if nil != err {
+		return err
+	}
+
+	return w.SendContext(ctx, msg)
+}
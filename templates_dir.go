@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplateExt `extension of template files loaded by LoadDir`
+const TemplateExt = ".tmpl"
+
+// DefaultWatchInterval `how often Watch polls when its interval is not positive`
+const DefaultWatchInterval = 2 * time.Second
+
+// ParseTemplateFile `parse a template file made of a metadata header and the body template`
+//
+// The header sits between two "---" lines and holds "key: value" lines:
+//
+//	---
+//	msgtype: markdown
+//	title: {{.Service}} deployed
+//	at_user_ids: release-manager
+//	---
+//	#### {{.Service}} {{.Version}}
+//
+// Known keys are msgtype, title, at_all, at_mobiles, at_user_ids (comma separated),
+// single_title, single_url, btn_orientation and button, which takes "title | url" and may be
+// repeated. Files without a header are text templates.
+func ParseTemplateFile(data []byte) (MessageTemplate, error) {
+	tmpl := MessageTemplate{MsgType: "text"}
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	if !strings.HasPrefix(text, "---\n") {
+		tmpl.Text = text
+		return tmpl, nil
+	}
+
+	end := strings.Index(text[4:], "\n---\n")
+	if -1 == end {
+		return tmpl, fmt.Errorf("metadata header is not closed by ---")
+	}
+	header, body := text[4:4+end], text[4+end+5:]
+	tmpl.Text = body
+
+	for i, line := range strings.Split(header, "\n") {
+		if "" == strings.TrimSpace(line) || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if 2 != len(parts) {
+			return tmpl, fmt.Errorf("metadata line %d: want key: value, got %q", i+1, line)
+		}
+
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch key {
+		case "msgtype":
+			tmpl.MsgType = value
+		case "title":
+			tmpl.Title = value
+		case "single_title":
+			tmpl.SingleTitle = value
+		case "single_url":
+			tmpl.SingleURL = value
+		case "btn_orientation":
+			tmpl.BtnOrientation = "horizontal" == value || "1" == value
+		case "button":
+			button := strings.SplitN(value, "|", 2)
+			if 2 != len(button) {
+				return tmpl, fmt.Errorf("metadata line %d: button wants title | url", i+1)
+			}
+			tmpl.Buttons = append(tmpl.Buttons, Button{Title: strings.TrimSpace(button[0]), ActionURL: strings.TrimSpace(button[1])})
+		case "at_all":
+			atAll, err := strconv.ParseBool(value)
+			if nil != err {
+				return tmpl, fmt.Errorf("metadata line %d: %v", i+1, err)
+			}
+			if atAll {
+				tmpl.At = append(tmpl.At, AtAll())
+			}
+		case "at_mobiles":
+			tmpl.At = append(tmpl.At, AtMobiles(splitList(value)...))
+		case "at_user_ids":
+			tmpl.At = append(tmpl.At, AtUserIDs(splitList(value)...))
+		default:
+			return tmpl, fmt.Errorf("metadata line %d: unknown key %q", i+1, key)
+		}
+	}
+
+	return tmpl, nil
+}
+
+// splitList comma separated values without blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); "" != item {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// LoadDir `register every *.tmpl file of dir under its base name`
+//
+// Either every file is loaded or, on the first error, none is. Templates loaded from dir
+// before whose file is gone are unregistered.
+func (r *TemplateRegistry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+TemplateExt))
+	if nil != err {
+		return err
+	}
+
+	compiled := make(map[string]*compiledTemplate, len(files))
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if nil != err {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), TemplateExt)
+		tmpl, err := ParseTemplateFile(data)
+		if nil != err {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if compiled[name], err = compileTemplate(name, tmpl); nil != err {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if nil == r.dirs {
+		r.dirs = make(map[string]string)
+	}
+	for name, from := range r.dirs {
+		if _, ok := compiled[name]; !ok && from == dir {
+			delete(r.templates, name)
+			delete(r.dirs, name)
+		}
+	}
+	for name, tmpl := range compiled {
+		r.templates[name] = tmpl
+		r.dirs[name] = dir
+	}
+
+	return nil
+}
+
+// Watch `reload dir whenever one of its templates changes, until ctx is done`
+//
+// The directory is polled every interval, DefaultWatchInterval when it is not positive. A reload
+// failing to parse keeps the templates loaded before and is reported to onError, which may be nil.
+func (r *TemplateRegistry) Watch(ctx context.Context, dir string, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := templateDirStamp(dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stamp := templateDirStamp(dir)
+		if stamp == last {
+			continue
+		}
+		last = stamp
+		if err := r.LoadDir(dir); nil != err && nil != onError {
+			onError(err)
+		}
+	}
+}
+
+// templateDirStamp names, sizes and modification times of the template files
+func templateDirStamp(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+TemplateExt))
+	sort.Strings(files)
+
+	var stamp strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if nil != err {
+			continue
+		}
+		fmt.Fprintf(&stamp, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return stamp.String()
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseTemplateFile(t *testing.T) {
+	tmpl, err := ParseTemplateFile([]byte("---\r\nmsgtype: actionCard\r\ntitle: approve {{.Version}}\r\nbtn_orientation: horizontal\r\nbutton: Approve | https://ci/approve\r\nat_user_ids: a, b\r\n---\r\nbody"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "actionCard" != tmpl.MsgType || "approve {{.Version}}" != tmpl.Title || "body" != tmpl.Text || !tmpl.BtnOrientation {
+		t.Errorf("unexpected template %+v", tmpl)
+	}
+	if 1 != len(tmpl.Buttons) || "https://ci/approve" != tmpl.Buttons[0].ActionURL || 1 != len(tmpl.At) {
+		t.Errorf("unexpected buttons or mentions %+v", tmpl)
+	}
+
+	if tmpl, _ := ParseTemplateFile([]byte("plain {{.}}")); "text" != tmpl.MsgType || "plain {{.}}" != tmpl.Text {
+		t.Errorf("files without header should be text templates, got %+v", tmpl)
+	}
+	for _, bad := range []string{"---\nmsgtype: text\n", "---\ncolor: red\n---\n", "---\nat_all: maybe\n---\n", "---\nbutton: only a title\n---\n"} {
+		if _, err := ParseTemplateFile([]byte(bad)); nil == err {
+			t.Errorf("%q: metadata error should be catch!", bad)
+		}
+	}
+}
+
+func TestTemplateRegistryLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); nil != err {
+			t.Fatal(err)
+		}
+	}
+	write("deploy.tmpl", "---\nmsgtype: markdown\ntitle: {{.}} deployed\n---\n#### {{.}}")
+	write("ping.tmpl", "ping {{.}}")
+	write("notes.txt", "ignored")
+
+	templates := NewTemplateRegistry()
+	if err := templates.LoadDir(dir); nil != err {
+		t.Fatal(err)
+	}
+	names := templates.Names()
+	sort.Strings(names)
+	if 2 != len(names) || "deploy" != names[0] || "ping" != names[1] {
+		t.Fatalf("unexpected templates %v", names)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go templates.Watch(ctx, dir, 5*time.Millisecond, func(err error) { errs <- err })
+
+	//  a broken edit keeps the loaded templates
+	time.Sleep(20 * time.Millisecond)
+	write("ping.tmpl", "ping {{.")
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("reload error should be reported")
+	}
+	if _, err := templates.Render(ctx, "ping", "x"); nil != err {
+		t.Errorf("previous template should be kept, got %v", err)
+	}
+
+	write("ping.tmpl", "pong {{.}}")
+	os.Remove(filepath.Join(dir, "deploy.tmpl"))
+	deadline := time.Now().Add(time.Second)
+	for {
+		msg, err := templates.Render(ctx, "ping", "x")
+		if nil == err && "pong x" == msg.(*TextMessage).Content {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("template should be reloaded, got %v %v", msg, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := templates.Render(ctx, "deploy", "x"); nil == err {
+		t.Error("removed template should be unregistered")
+	}
+}
+
+func TestWatchZeroInterval(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	//  used to panic in time.NewTicker
+	NewTemplateRegistry().Watch(ctx, os.TempDir(), 0, nil)
+}
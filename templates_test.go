@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestSendTemplate(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	templates := NewTemplateRegistry()
+	err := templates.Register("deploy", MessageTemplate{
+		MsgType: "markdown",
+		Title:   "{{.Service}} deployed",
+		Text:    "#### {{.Service}} {{.Version}}\n\n{{range .Hosts}}- {{.}}\n{{end}}",
+		At:      []AtOption{AtUserIDs("release-manager")},
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+	err = templates.Register("approve", MessageTemplate{
+		MsgType: "actionCard",
+		Title:   "approve {{.Version}}",
+		Text:    "{{.Service}} {{.Version}} waits for approval",
+		Buttons: []Button{{Title: "Approve", ActionURL: "https://ci/{{.Version}}/approve"}},
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Templates = templates
+	data := map[string]interface{}{"Service": "billing", "Version": "v1.2.3", "Hosts": []string{"web-1", "web-2"}}
+	if err := webHook.SendTemplate("deploy", data); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.SendTemplate("approve", data); nil != err {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	webhooktest.AssertContainsMarkdownHeading(t, requests[0], "billing v1.2.3")
+	webhooktest.AssertAtUserIDs(t, requests[0], "release-manager")
+	payload, _ := requests[1].Payload()
+	if "billing v1.2.3 waits for approval" != payload.ActionCard.Text {
+		t.Errorf("unexpected card %+v", payload.ActionCard)
+	}
+
+	if err := webHook.SendTemplate("missing", data); nil == err {
+		t.Error("unknown template error should be catch!")
+	}
+	if err := templates.Register("broken", MessageTemplate{MsgType: "text", Text: "{{.Service"}); nil == err {
+		t.Error("template parse error should be catch!")
+	}
+	if err := templates.Register("feed", MessageTemplate{MsgType: "feedCard"}); nil == err {
+		t.Error("unsupported msgtype error should be catch!")
+	}
+	if err := webHook.SendTemplate("deploy", 42); nil == err {
+		t.Error("template execution error should be catch!")
+	}
+}
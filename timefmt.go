@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeFormat `how times are rendered in messages`
+type TimeFormat struct {
+	//  nil means time.Local
+	Location *time.Location
+	Layout   string
+	//  "zh" or "en", relative times only
+	Locale string
+	//  relative times older than this fall back to the absolute form
+	RelativeLimit time.Duration
+}
+
+// DefaultTimeFormat `format used by FormatTime, FormatRelative and the built-in messages`
+var DefaultTimeFormat = TimeFormat{
+	Layout:        "2006-01-02 15:04:05 MST",
+	Locale:        "zh",
+	RelativeLimit: 30 * 24 * time.Hour,
+}
+
+// FormatTime `absolute time in DefaultTimeFormat`
+func FormatTime(t time.Time) string {
+	return DefaultTimeFormat.Absolute(t)
+}
+
+// FormatRelative `time relative to now in DefaultTimeFormat, e.g. 3分钟前`
+func FormatRelative(t time.Time) string {
+	return DefaultTimeFormat.Relative(t, time.Now())
+}
+
+// Absolute `t in the configured location and layout`
+func (f TimeFormat) Absolute(t time.Time) string {
+	location := f.Location
+	if nil == location {
+		location = time.Local
+	}
+	layout := f.Layout
+	if "" == layout {
+		layout = DefaultTimeFormat.Layout
+	}
+
+	return t.In(location).Format(layout)
+}
+
+// Relative `t relative to now, like 3分钟前 or "in 2 hours"`
+func (f TimeFormat) Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	past := d >= 0
+	if !past {
+		d = -d
+	}
+	if 0 != f.RelativeLimit && d > f.RelativeLimit {
+		return f.Absolute(t)
+	}
+
+	var n int64
+	var unit string
+	switch {
+	case d < time.Minute:
+		if "en" == f.Locale {
+			return "just now"
+		}
+		return "刚刚"
+	case d < time.Hour:
+		n, unit = int64(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int64(d/time.Hour), "hour"
+	default:
+		n, unit = int64(d/(24*time.Hour)), "day"
+	}
+
+	if "en" == f.Locale {
+		if 1 != n {
+			unit += "s"
+		}
+		if past {
+			return fmt.Sprintf("%d %s ago", n, unit)
+		}
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+
+	zh := map[string]string{"minute": "分钟", "hour": "小时", "day": "天"}[unit]
+	if past {
+		return fmt.Sprintf("%d%s前", n, zh)
+	}
+	return fmt.Sprintf("%d%s后", n, zh)
+}
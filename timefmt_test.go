@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatRelative(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	zh := TimeFormat{Locale: "zh", RelativeLimit: 7 * 24 * time.Hour, Location: time.UTC, Layout: "2006-01-02"}
+	en := TimeFormat{Locale: "en"}
+
+	cases := []struct {
+		format TimeFormat
+		t      time.Time
+		want   string
+	}{
+		{zh, now.Add(-30 * time.Second), "刚刚"},
+		{zh, now.Add(-3 * time.Minute), "3分钟前"},
+		{zh, now.Add(-5 * time.Hour), "5小时前"},
+		{zh, now.Add(2 * 24 * time.Hour), "2天后"},
+		{zh, now.Add(-10 * 24 * time.Hour), "2024-01-21"},
+		{en, now.Add(-1 * time.Minute), "1 minute ago"},
+		{en, now.Add(-3 * time.Hour), "3 hours ago"},
+		{en, now.Add(90 * time.Minute), "in 1 hour"},
+		{en, now, "just now"},
+	}
+	for _, c := range cases {
+		if got := c.format.Relative(c.t, now); c.want != got {
+			t.Errorf("%s: want %q, got %q", now.Sub(c.t), c.want, got)
+		}
+	}
+}
+
+func TestTimeFormatAbsolute(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	at := time.Date(2024, 1, 31, 16, 0, 0, 0, time.UTC)
+
+	if got := (TimeFormat{Location: shanghai}).Absolute(at); "2024-02-01 00:00:00 CST" != got {
+		t.Errorf("time should be shown in the configured location, got %s", got)
+	}
+}
+
+func TestTimeTemplateFuncs(t *testing.T) {
+	out, err := RenderTemplate(context.Background(), `{{ relativeTime .At }}`, map[string]time.Time{"At": time.Now().Add(-2 * time.Hour)})
+	if nil != err {
+		t.Fatal(err)
+	}
+	if "2小时前" != out {
+		t.Errorf("unexpected relative time %q", out)
+	}
+}
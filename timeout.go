@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdaptiveTimeout `per-request timeout following the rolling p95 latency`
+//
+// With Adapt off the timeout stays at Max and only the latency is tracked.
+type AdaptiveTimeout struct {
+	Min time.Duration
+	Max time.Duration
+	//  timeout is P95 times Multiplier, bounded by Min and Max
+	Multiplier float64
+	Adapt      bool
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// adaptive timeout defaults
+const (
+	adaptiveWindow     = 100
+	adaptiveMinSamples = 20
+)
+
+// NewAdaptiveTimeout `adapting timeout of 3x p95 bounded by min and max`
+func NewAdaptiveTimeout(min, max time.Duration) *AdaptiveTimeout {
+	return &AdaptiveTimeout{Min: min, Max: max, Multiplier: 3, Adapt: true}
+}
+
+// Observe `record the latency of a completed request`
+func (a *AdaptiveTimeout) Observe(latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.samples) < adaptiveWindow {
+		a.samples = append(a.samples, latency)
+		return
+	}
+	a.samples[a.next] = latency
+	a.next = (a.next + 1) % adaptiveWindow
+}
+
+// P95 `95th percentile of the last requests, 0 until enough were observed`
+func (a *AdaptiveTimeout) P95() time.Duration {
+	a.mu.Lock()
+	if len(a.samples) < adaptiveMinSamples {
+		a.mu.Unlock()
+		return 0
+	}
+	sorted := append([]time.Duration(nil), a.samples...)
+	a.mu.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*95-1)/100]
+}
+
+// Timeout `timeout for the next request`
+func (a *AdaptiveTimeout) Timeout() time.Duration {
+	p95 := a.P95()
+	if !a.Adapt || 0 == p95 {
+		return a.Max
+	}
+
+	timeout := time.Duration(float64(p95) * a.Multiplier)
+	if timeout < a.Min {
+		return a.Min
+	}
+	if timeout > a.Max {
+		return a.Max
+	}
+
+	return timeout
+}
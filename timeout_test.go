@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	timeout := NewAdaptiveTimeout(100*time.Millisecond, 10*time.Second)
+	if 10*time.Second != timeout.Timeout() {
+		t.Error("max should be used until enough samples are observed")
+	}
+
+	for i := 1; i <= 100; i++ {
+		timeout.Observe(time.Duration(i) * 10 * time.Millisecond)
+	}
+	if 950*time.Millisecond != timeout.P95() {
+		t.Errorf("p95 should be 950ms, got %s", timeout.P95())
+	}
+	if 2850*time.Millisecond != timeout.Timeout() {
+		t.Errorf("timeout should be 3x p95, got %s", timeout.Timeout())
+	}
+
+	for i := 0; i < 100; i++ {
+		timeout.Observe(time.Millisecond)
+	}
+	if 100*time.Millisecond != timeout.Timeout() {
+		t.Errorf("timeout should be bounded by min, got %s", timeout.Timeout())
+	}
+
+	timeout.Adapt = false
+	if 10*time.Second != timeout.Timeout() {
+		t.Error("non adapting timeout should stay at max")
+	}
+}
+
+func TestWebHookAdaptiveTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if "slow" == r.URL.Query().Get("access_token") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("fast")
+	webHook.APIURL = server.URL
+	webHook.Timeout = NewAdaptiveTimeout(20*time.Millisecond, time.Second)
+	for i := 0; i < adaptiveMinSamples; i++ {
+		if err := webHook.SendTextMsg("ping", false); nil != err {
+			t.Fatal(err)
+		}
+	}
+
+	webHook.AccessToken = "slow"
+	if err := webHook.SendTextMsg("ping", false); nil == err {
+		t.Error("request far above the observed latency should time out")
+	}
+
+	var err error
+	for i := 0; i < 10; i++ {
+		if err = webHook.SendTextMsg("ping", false); nil == err {
+			break
+		}
+	}
+	if nil != err {
+		t.Errorf("timed out requests should raise the timeout until the slow endpoint fits, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"crypto/tls"
+
+	"github.com/lddsb/dingtalk-webhook/transport"
+)
+
+// TLSOptions `file based tls settings, e.g. for a gateway requiring client certificates, see package transport`
+type TLSOptions = transport.TLSOptions
+
+// WithTLSConfig `use config for every request, see TLSOptions`
+func WithTLSConfig(config *tls.Config) Option {
+	return func(w *WebHook) {
+		w.TLSConfig = config
+	}
+}
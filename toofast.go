@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultTooFastCooldown `cool-down matching DingTalk's per minute window`
+const DefaultTooFastCooldown = time.Minute
+
+// tooFastRetries attempts given to a payload rejected with 130101 before the error is returned
+const tooFastRetries = 2
+
+// WithTooFastRetry `wait out "send too fast" rejections and retry, see WebHook.TooFastCooldown`
+func WithTooFastRetry(cooldown time.Duration) Option {
+	return func(w *WebHook) {
+		w.TooFastCooldown = cooldown
+	}
+}
+
+// coolDown start a cool-down when err is a 130101 worth retrying
+func (w *WebHook) coolDown(err error, retried int) bool {
+	if w.TooFastCooldown <= 0 || retried >= tooFastRetries || !errors.Is(err, ErrTooFast) {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if until := time.Now().Add(w.TooFastCooldown); until.After(w.coolUntil) {
+		w.coolUntil = until
+	}
+	return true
+}
+
+// waitCoolDown hold every send of the robot until a running cool-down is over
+func (w *WebHook) waitCoolDown(ctx context.Context) error {
+	w.mu.Lock()
+	delay := time.Until(w.coolUntil)
+	w.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	//  fail fast instead of sleeping past the caller's deadline
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return ErrTooFast
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
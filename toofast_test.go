@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestTooFastRetry(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("send-too-fast")
+
+	webHook := NewWebHook("token", WithTooFastRetry(20*time.Millisecond))
+	webHook.APIURL = server.URL
+
+	start := time.Now()
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatalf("send should be retried after the cool-down, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || 2 != len(server.Requests()) {
+		t.Errorf("one retry after 20ms expected, got %d requests in %s", len(server.Requests()), elapsed)
+	}
+
+	_ = server.ReplyFixture("send-too-fast", "send-too-fast", "send-too-fast")
+	if err := webHook.SendTextMsg("hello", false); !errors.Is(err, ErrTooFast) {
+		t.Errorf("retries should be bounded, got %v", err)
+	}
+
+	_ = server.ReplyFixture("send-too-fast")
+	webHook.TooFastCooldown = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := webHook.SendTextMsgContext(ctx, "hello", false); !errors.Is(err, ErrTooFast) {
+		t.Errorf("cool-down past the deadline should fail fast, got %v", err)
+	}
+	if _, ok := webHook.DebugState()["cooldown"]; !ok {
+		t.Error("running cool-down should be in the debug state")
+	}
+
+	webHook.TooFastCooldown = 0
+	webHook.coolUntil = time.Time{}
+	_ = server.ReplyFixture("send-too-fast")
+	before := len(server.Requests())
+	if err := webHook.SendTextMsg("hello", false); !errors.Is(err, ErrTooFast) || before+1 != len(server.Requests()) {
+		t.Errorf("without cool-down the error should be returned at once, got %v", err)
+	}
+}
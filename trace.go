@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer `starts spans, adapt OpenTelemetry or any other tracing library to it`
+//
+// With OpenTelemetry, Start wraps otel.Tracer(...).Start and Span wraps trace.Span. The ctx
+// returned by Start is the one the request is made with, so an instrumented http.Client
+// propagates the trace to the gateway.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span `the part of a span the send path uses`
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// SpanName `name of the span around every send`
+const SpanName = "dingtalk.send"
+
+// WithTracer `trace every send with tracer`
+func WithTracer(tracer Tracer) Option {
+	return func(w *WebHook) {
+		w.Tracer = tracer
+	}
+}
+
+// endSpan record the outcome of a send on span and end it
+func (w *WebHook) endSpan(span Span, payload *PayLoad, result *SendResult, attempts int, err error) {
+	span.SetAttribute("dingtalk.channel", w.channel())
+	span.SetAttribute("dingtalk.msgtype", payload.MsgType)
+	if !w.Sender.IsZero() {
+		span.SetAttribute("dingtalk.sender", w.Sender.String())
+	}
+	if attempts > 0 {
+		span.SetAttribute("dingtalk.attempts", attempts)
+	}
+	if nil != result {
+		span.SetAttribute("http.status_code", result.StatusCode)
+		span.SetAttribute("dingtalk.errcode", result.ErrorCode)
+	}
+	var apiErr *APIError
+	if nil == result && errors.As(err, &apiErr) {
+		span.SetAttribute("dingtalk.errcode", apiErr.Code)
+	}
+	if nil != err {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordedSpan) RecordError(err error)                      { s.err = err }
+func (s *recordedSpan) End()                                       { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTracer(t *testing.T) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+	_ = server.ReplyFixture("ok", "token-not-exist")
+
+	tracer := &recordingTracer{}
+	propagated := 0
+	webHook := NewWebHook("token", WithTracer(tracer))
+	webHook.APIURL = server.URL
+	webHook.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if nil != req.Context().Value(spanKey{}) {
+			propagated++
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	if err := webHook.SendTextMsg("hello", false); nil != err {
+		t.Fatal(err)
+	}
+	if err := webHook.SendMarkdownMsg("title", "text", false); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token not exist error should be catch! got %v", err)
+	}
+
+	if 2 != len(tracer.spans) || 2 != propagated {
+		t.Fatalf("every send should be traced with the span context, got %d spans, %d propagated", len(tracer.spans), propagated)
+	}
+	ok, failed := tracer.spans[0], tracer.spans[1]
+	if SpanName != ok.name || !ok.ended || nil != ok.err {
+		t.Errorf("successful span is wrong: %+v", ok)
+	}
+	if "text" != ok.attrs["dingtalk.msgtype"] || 200 != ok.attrs["http.status_code"] || 0 != ok.attrs["dingtalk.errcode"] {
+		t.Errorf("successful span attributes are wrong: %v", ok.attrs)
+	}
+	if "markdown" != failed.attrs["dingtalk.msgtype"] || 300001 != failed.attrs["dingtalk.errcode"] || nil == failed.err {
+		t.Errorf("failed span should carry the errcode and error, got %v %v", failed.attrs, failed.err)
+	}
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+)
+
+// ErrDropped `returned instead of sending when a transform rule or a Rule drops the message`
+var ErrDropped = errors.New("message dropped by rule")
+
+// TransformRule `one declarative rewrite, fields carry json and yaml tags`
+//
+// Prepend and Append are expanded against the environment when loaded, e.g. "[${APP_ENV}] ".
+// Themes maps the first capture group of Match (a severity, usually) to a prefix.
+type TransformRule struct {
+	Name     string            `json:"name" yaml:"name"`
+	MsgTypes []string          `json:"msgtypes,omitempty" yaml:"msgtypes,omitempty"`
+	Match    string            `json:"match,omitempty" yaml:"match,omitempty"`
+	Drop     bool              `json:"drop,omitempty" yaml:"drop,omitempty"`
+	Prepend  string            `json:"prepend,omitempty" yaml:"prepend,omitempty"`
+	Append   string            `json:"append,omitempty" yaml:"append,omitempty"`
+	Themes   map[string]string `json:"themes,omitempty" yaml:"themes,omitempty"`
+
+	match *regexp.Regexp
+}
+
+// TransformRules `ordered rules applied to every payload before it is sent`
+type TransformRules struct {
+	Rules []TransformRule `json:"rules" yaml:"rules"`
+}
+
+// LoadTransformRules `decode rules, unmarshal defaults to json.Unmarshal, pass yaml.Unmarshal for YAML`
+func LoadTransformRules(data []byte, unmarshal func([]byte, interface{}) error) (*TransformRules, error) {
+	if nil == unmarshal {
+		unmarshal = json.Unmarshal
+	}
+
+	var rules TransformRules
+	if err := unmarshal(data, &rules); nil != err {
+		return nil, err
+	}
+
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if "" != rule.Match {
+			re, err := regexp.Compile(rule.Match)
+			if nil != err {
+				return nil, fmt.Errorf("rule %q: %v", rule.Name, err)
+			}
+			rule.match = re
+		}
+		rule.Prepend = os.ExpandEnv(rule.Prepend)
+		rule.Append = os.ExpandEnv(rule.Append)
+	}
+
+	return &rules, nil
+}
+
+// LoadTransformRulesFile `LoadTransformRules reading from path`
+func LoadTransformRulesFile(path string, unmarshal func([]byte, interface{}) error) (*TransformRules, error) {
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+
+	return LoadTransformRules(data, unmarshal)
+}
+
+// Apply `rewrite payload in place, false means it must be dropped`
+func (r *TransformRules) Apply(payload *PayLoad) bool {
+	for _, rule := range r.Rules {
+		if 0 != len(rule.MsgTypes) && !inStrings(payload.MsgType, rule.MsgTypes) {
+			continue
+		}
+
+		var groups []string
+		if nil != rule.match {
+			if groups = rule.match.FindStringSubmatch(payloadContent(payload)); nil == groups {
+				continue
+			}
+		}
+
+		if rule.Drop {
+			return false
+		}
+
+		prefix := rule.Prepend
+		if 1 < len(groups) {
+			prefix = rule.Themes[groups[1]] + prefix
+		}
+		rewriteContent(payload, func(content string) string {
+			return prefix + content + rule.Append
+		})
+	}
+
+	return true
+}
+
+// rewriteContent apply fn to the body of the active message section
+func rewriteContent(payload *PayLoad, fn func(string) string) {
+	switch {
+	case "text" == payload.MsgType && nil != payload.Text:
+		payload.Text.Content = fn(payload.Text.Content)
+	case "link" == payload.MsgType && nil != payload.Link:
+		payload.Link.Text = fn(payload.Link.Text)
+	case "markdown" == payload.MsgType && nil != payload.Markdown:
+		payload.Markdown.Text = fn(payload.Markdown.Text)
+	case "actionCard" == payload.MsgType && nil != payload.ActionCard:
+		payload.ActionCard.Text = fn(payload.ActionCard.Text)
+	}
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const transformConfig = `{
+  "rules": [
+    {"name": "drop-debug", "match": "(?i)^\\[debug\\]", "drop": true},
+    {"name": "severity", "match": "^\\[(P1|P2)\\]", "themes": {"P1": "🔴 ", "P2": "🟡 "}},
+    {"name": "env", "msgtypes": ["text", "markdown"], "prepend": "[${TRANSFORM_TEST_ENV}] "}
+  ]
+}`
+
+func TestTransformRules(t *testing.T) {
+	_ = os.Setenv("TRANSFORM_TEST_ENV", "prod")
+	defer os.Unsetenv("TRANSFORM_TEST_ENV")
+
+	rules, err := LoadTransformRules([]byte(transformConfig), nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	payload := &PayLoad{MsgType: "text", Text: &Text{Content: "[P1] db down"}}
+	if !rules.Apply(payload) {
+		t.Fatal("P1 message should be kept")
+	}
+	if "[prod] 🔴 [P1] db down" != payload.Text.Content {
+		t.Errorf("unexpected rewrite: %q", payload.Text.Content)
+	}
+
+	payload.Text.Content = "[DEBUG] cache miss"
+	if rules.Apply(payload) {
+		t.Error("debug message should be dropped")
+	}
+
+	link := &PayLoad{MsgType: "link", Link: &Link{Text: "release notes"}}
+	rules.Apply(link)
+	if "release notes" != link.Link.Text {
+		t.Errorf("env rule is limited to text and markdown, got %q", link.Link.Text)
+	}
+
+	if _, err := LoadTransformRules([]byte(`{"rules":[{"match":"("}]}`), nil); nil == err {
+		t.Error("invalid regexp error should be catch!")
+	}
+}
+
+func TestWebHookTransform(t *testing.T) {
+	var got PayLoad
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = rw.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	webHook := NewWebHook("token")
+	webHook.APIURL = server.URL
+	webHook.Transform, _ = LoadTransformRules([]byte(`{"rules":[{"match":"noise","drop":true},{"append":" #ops"}]}`), nil)
+
+	if err := webHook.SendTextMsg("some noise", false); ErrDropped != err {
+		t.Errorf("dropped message should report ErrDropped, got %v", err)
+	}
+	if err := webHook.SendTextMsg("disk full", false); nil != err {
+		t.Fatal(err)
+	}
+	if "disk full #ops" != got.Text.Content {
+		t.Errorf("rewritten content should be sent, got %q", got.Text.Content)
+	}
+}
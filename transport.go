@@ -0,0 +1,22 @@
+package webhook
+
+import "github.com/lddsb/dingtalk-webhook/transport"
+
+// TransportConfig `connection pool of the transports this package builds, see package transport`
+type TransportConfig = transport.Config
+
+// DefaultTransportConfig `pool of DefaultTransport and of robots without their own config`
+var DefaultTransportConfig = transport.DefaultConfig
+
+// DefaultTransport `transport of DefaultClient, proxied according to HTTP_PROXY, HTTPS_PROXY and NO_PROXY`
+var DefaultTransport = transport.DefaultTransport
+
+// DefaultClient `http client used when WebHook.Client is nil, see package transport`
+var DefaultClient = transport.DefaultClient
+
+// WithTransportConfig `give the robot its own connection pool, shared with robots using the same config`
+func WithTransportConfig(config TransportConfig) Option {
+	return func(w *WebHook) {
+		w.TransportConfig = &config
+	}
+}
@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+)
+
+// TLSOptions `file based tls settings, e.g. for a gateway requiring client certificates`
+type TLSOptions struct {
+	//  pem bundle trusted instead of the system roots
+	RootCAFile string
+	//  pem client certificate and key, both or neither
+	CertFile string
+	KeyFile  string
+	//  name checked against the server certificate when it differs from the url host
+	ServerName string
+	//  never outside of test labs
+	InsecureSkipVerify bool
+}
+
+// Config `build the tls config`
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{ServerName: o.ServerName, InsecureSkipVerify: o.InsecureSkipVerify}
+
+	if "" != o.RootCAFile {
+		bundle, err := ioutil.ReadFile(o.RootCAFile)
+		if nil != err {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("no certificate found in " + o.RootCAFile)
+		}
+	}
+
+	if "" != o.CertFile || "" != o.KeyFile {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if nil != err {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
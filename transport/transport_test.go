@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	if DefaultClient != Client(Options{}) {
+		t.Error("zero options should use the default client")
+	}
+	if nil != Client(Options{DisableProxy: true}).Transport.(*http.Transport).Proxy {
+		t.Error("disabled proxy should connect directly")
+	}
+
+	config := &tls.Config{ServerName: "oapi.dingtalk.com"}
+	client := Client(Options{TLS: config, Pool: &Config{MaxIdleConnsPerHost: 4}})
+	transport := client.Transport.(*http.Transport)
+	if config != transport.TLSClientConfig || 4 != transport.MaxIdleConnsPerHost {
+		t.Errorf("tls and pool settings should be applied, got %+v", transport)
+	}
+	if client != Client(Options{TLS: config, Pool: &Config{MaxIdleConnsPerHost: 4}}) {
+		t.Error("callers with the same settings should share a client")
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	proxy, err := ParseProxy("socks5://127.0.0.1:1080")
+	if nil != err {
+		t.Fatal(err)
+	}
+	if Client(Options{Proxy: proxy}) != Client(Options{Proxy: proxy}) {
+		t.Error("callers with the same proxy should share a client")
+	}
+
+	for _, raw := range []string{"ftp://proxy:21", "http://", "::"} {
+		if _, err := ParseProxy(raw); nil == err {
+			t.Errorf("invalid proxy %q error should be catch!", raw)
+		}
+	}
+}
+
+func TestClientBounded(t *testing.T) {
+	first := Client(Options{TLS: &tls.Config{}})
+	for i := 0; i < 2*maxClients; i++ {
+		Client(Options{TLS: &tls.Config{}})
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if len(clients) > maxClients || len(clientsOrder) != len(clients) {
+		t.Errorf("cache should keep at most %d clients, got %d", maxClients, len(clients))
+	}
+	for _, client := range clients {
+		if first == client {
+			t.Error("the oldest client should be dropped")
+		}
+	}
+}
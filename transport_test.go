@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/lddsb/dingtalk-webhook/webhooktest"
+)
+
+func TestTransportConfig(t *testing.T) {
+	if transport := DefaultClient.Transport.(*http.Transport); DefaultTransportConfig.MaxIdleConnsPerHost != transport.MaxIdleConnsPerHost {
+		t.Errorf("default transport should keep %d idle connections per host, got %d", DefaultTransportConfig.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+
+	config := TransportConfig{MaxIdleConns: 4, MaxIdleConnsPerHost: 4}
+	webHook := NewWebHook("token", WithTransportConfig(config))
+	transport := webHook.httpClient().Transport.(*http.Transport)
+	if 4 != transport.MaxIdleConnsPerHost || nil == transport.Proxy {
+		t.Errorf("pool config should be applied on top of the proxy environment, got %+v", transport)
+	}
+	if webHook.httpClient() != NewWebHook("other", WithTransportConfig(config)).httpClient() {
+		t.Error("robots with the same pool config should share a client")
+	}
+}
+
+// benchmarkSend send in parallel bursts through the client returned by client
+func benchmarkSend(b *testing.B, client func() *http.Client) {
+	server := webhooktest.NewServer()
+	defer server.Close()
+
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			webHook := NewWebHook("token", WithHTTPClient(client()))
+			webHook.APIURL = server.URL
+			if err := webHook.SendTextMsg("hello", false); nil != err {
+				b.Error(err)
+			}
+		}()
+		if 0 == i%16 {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+}
+
+func BenchmarkSendSharedTransport(b *testing.B) {
+	benchmarkSend(b, func() *http.Client {
+		return DefaultClient
+	})
+}
+
+func BenchmarkSendNetHTTPDefaults(b *testing.B) {
+	//  what the package used before: http.DefaultTransport keeps 2 idle connections per host
+	client := &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	benchmarkSend(b, func() *http.Client {
+		return client
+	})
+}
+
+func BenchmarkSendNewTransport(b *testing.B) {
+	//  a transport per send, every request dials
+	benchmarkSend(b, func() *http.Client {
+		return &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	})
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"net/url"
+
+	"github.com/lddsb/dingtalk-webhook/message"
+)
+
+// FieldSchema `description of one field inside a message section`
+type FieldSchema = message.FieldSchema
+
+// MessageSchema `machine readable definition of a DingTalk msgtype`
+type MessageSchema = message.MessageSchema
+
+// ValidationError `every schema violation found in a payload`
+type ValidationError = message.ValidationError
+
+// LintWarning `a markdown construct DingTalk ignores or renders badly`
+type LintWarning = message.LintWarning
+
+// MaxListDepth `deepest list nesting DingTalk renders, deeper items are flattened`
+const MaxListDepth = message.MaxListDepth
+
+// Schemas `all message kinds known to this library`
+//
+// This is the slice message.Schemas held at start up, new kinds must be appended to
+// message.Schemas for SchemaFor and ValidatePayload to see them.
+var Schemas = message.Schemas
+
+// SchemaFor `look up the schema of a msgtype`
+func SchemaFor(msgType string) (MessageSchema, bool) {
+	return message.SchemaFor(msgType)
+}
+
+// BuildPayload `build a payload of any known msgtype from its section fields`
+func BuildPayload(msgType string, fields map[string]interface{}) (*PayLoad, error) {
+	return message.BuildPayload(msgType, fields)
+}
+
+// ValidatePayload `check the active section of a payload against its schema`
+func ValidatePayload(payload *PayLoad) error {
+	return message.ValidatePayload(payload)
+}
+
+// Lint `flag markdown DingTalk does not support, line numbers start at 1`
+func Lint(markdown string) []LintWarning {
+	return message.Lint(markdown)
+}
+
+// absolute url check, DingTalk also accepts its own dingtalk:// scheme
+func isURL(str string) bool {
+	u, err := url.Parse(str)
+	return nil == err && "" != u.Scheme && ("" != u.Host || "dingtalk" == u.Scheme)
+}
+
+func inStrings(str string, list []string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,11 @@
+package webhook
+
+import "testing"
+
+func TestValidateKnownGoodPayloads(t *testing.T) {
+	for msgType, payload := range knownGoodPayloads() {
+		if err := ValidatePayload(payload); nil != err {
+			t.Errorf("%s should be valid, got %v", msgType, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import "encoding/json"
+
+// PayloadVersion `version of the wire format`
+//
+// It is bumped whenever the JSON produced for any message type changes, compare it (or the
+// output of PayloadFixtures) with your stored copy when upgrading the library.
+const PayloadVersion = "2"
+
+// PayloadFixtures `canonical JSON of every message type, keyed by msgtype`
+func PayloadFixtures() (map[string][]byte, error) {
+	fixtures := make(map[string][]byte)
+	for msgType, payload := range knownGoodPayloads() {
+		applyCompat(payload)
+		bs, err := json.MarshalIndent(payload, "", "  ")
+		if nil != err {
+			return nil, err
+		}
+		fixtures[msgType] = append(bs, '\n')
+	}
+
+	return fixtures, nil
+}
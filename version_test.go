@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var updateFixtures = flag.Bool("update", false, "rewrite testdata/payloads fixtures")
+
+func TestPayloadFixtures(t *testing.T) {
+	fixtures, err := PayloadFixtures()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	for msgType, got := range fixtures {
+		path := filepath.Join("testdata", "payloads", msgType+".json")
+		if *updateFixtures {
+			if err := ioutil.WriteFile(path, got, 0644); nil != err {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		want, err := ioutil.ReadFile(path)
+		if nil != err {
+			t.Fatalf("missing fixture for %s, run go test -update: %v", msgType, err)
+		}
+		if !bytes.Equal(want, got) {
+			t.Errorf("wire format of %s changed, bump PayloadVersion and run go test -update\nwant: %s\ngot: %s", msgType, want, got)
+		}
+	}
+}
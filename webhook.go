@@ -1,73 +1,136 @@
+// Package webhook sends messages through DingTalk custom robots.
+//
+// The building blocks live in subpackages which can be imported on their own: message,
+// transport, sign, queue, receiver and adapters. Their types and functions are re-exported
+// here under their historical names, so code written against this package keeps compiling.
 package webhook
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
+	"context"
+	"crypto/tls"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-)
-
-// LinkMsg `link message struct`
-type LinkMsg struct {
-	Title      string `json:"title"`
-	MessageURL string `json:"messageUrl"`
-	PicURL     string `json:"picUrl"`
-}
-
-// ActionCard `action card message struct`
-type ActionCard struct {
-	Text           string `json:"text"`
-	Title          string `json:"title"`
-	SingleTitle    string `json:"singleTitle"`
-	SingleURL      string `json:"singleUrl"`
-	BtnOrientation string `json:"btnOrientation"`
-	HideAvatar     string `json:"hideAvatar"` //  robot message avatar
-	Buttons        []struct {
-		Title     string `json:"title"`
-		ActionURL string `json:"actionUrl"`
-	} `json:"btns"`
-}
 
-// PayLoad payload
-type PayLoad struct {
-	MsgType string `json:"msgtype"`
-	Text    struct {
-		Content string `json:"content"`
-	} `json:"text"`
-	Link struct {
-		Title      string `json:"title"`
-		Text       string `json:"text"`
-		PicURL     string `json:"picURL"`
-		MessageURL string `json:"messageUrl"`
-	} `json:"link"`
-	Markdown struct {
-		Title string `json:"title"`
-		Text  string `json:"text"`
-	} `json:"markdown"`
-	ActionCard ActionCard `json:"actionCard"`
-	FeedCard   struct {
-		Links []LinkMsg `json:"links"`
-	} `json:"feedCard"`
-	At struct {
-		AtMobiles []string `json:"atMobiles"`
-		IsAtAll   bool     `json:"isAtAll"`
-	} `json:"at"`
-}
+	"github.com/lddsb/dingtalk-webhook/transport"
+)
 
 // WebHook `web hook base config`
 type WebHook struct {
-	accessToken string
-	apiUrl      string
+	AccessToken string
+	APIURL      string
 	Secret      string
+	//  logical channel name used in history and metrics, never the token
+	Name string
+
+	//  called for every deprecated field dropped from a payload
+	OnDeprecation func(DeprecationWarning)
+	//  paces outgoing requests, nil sends immediately
+	Limiter Limiter
+	//  retries failed requests, nil gives up after the first attempt
+	Retry *RetryPolicy
+	//  on errcode 130101 hold all sends this long and retry, 0 returns ErrTooFast at once
+	TooFastCooldown time.Duration
+	//  fail fast with ErrCircuitOpen while the endpoint is degraded
+	Breaker *CircuitBreaker
+	//  archive of sent payloads, nil keeps nothing
+	History *History
+	//  declarative rewrites applied before sending
+	Transform *TransformRules
+	//  evaluated in order on every message before the middleware, see WithRules
+	Rules []Rule
+	//  per-request timeout derived from observed latency, nil leaves requests unbounded
+	Timeout *AdaptiveTimeout
+	//  receives one observation per send, labelled with the channel name
+	Metrics Metrics
+	//  http client used for every request, nil uses DefaultClient
+	Client *http.Client
+	//  proxy used when Client is nil instead of HTTP_PROXY, HTTPS_PROXY and NO_PROXY, see ParseProxy
+	Proxy *url.URL
+	//  ignore HTTP_PROXY, HTTPS_PROXY and NO_PROXY when Client and Proxy are nil
+	DisableProxy bool
+	//  custom roots, client certificate or server name when Client is nil, see TLSOptions
+	TLSConfig *tls.Config
+	//  connection pool when Client is nil, nil uses DefaultTransportConfig
+	TransportConfig *TransportConfig
+	//  added to every request
+	Header http.Header
+	//  User-Agent of every request, empty uses DefaultUserAgent
+	UserAgent string
+	//  wraps every send, the first one is outermost, see Use
+	Middleware []Middleware
+	//  called with every payload as built, before transform rules and decorations, may modify it
+	OnBeforeSend func(payload *PayLoad)
+	//  called once per payload with the outcome, result is nil when the api was not reached
+	OnAfterSend func(payload *PayLoad, result *SendResult, err error)
+	//  wraps every send in a SpanName span
+	Tracer Tracer
+	//  remembers idempotency keys attached with WithIdempotencyKey, nil sends every message
+	Dedupe DedupeStore
+	//  how long a key suppresses re-sends, 0 uses DefaultDedupeWindow
+	DedupeWindow time.Duration
+	//  tried in order once the robot failed after its retries, e.g. another robot's AsSender
+	Fallbacks []Sender
+	//  receives retries, cool-downs, lost messages and with Debug request dumps, nil keeps it silent
+	Logger Logger
+	//  log the signed url (token redacted), json body and api response through Logger
+	Debug bool
+	//  reserved for P1 pages: skips silences, dedupe, digests, dropping rules and Limiter, only DingTalk's hard limit applies
+	Emergency bool
+	//  rewrites mentioned mobiles in the message body, e.g. MaskMobile, nil shows them as is
+	Anonymizer func(mobile string) string
+	//  called once a payload is given up on, e.g. to open a ticket
+	OnPermanentFailure func(Failure)
+	//  service and team the traffic is attributed to in hooks, metrics and history
+	Sender SenderIdentity
+	//  owner and escalation contact of the group, added to send errors and debug state
+	Profile RobotProfile
+	//  append the sender to every message body
+	SenderFooter bool
+	//  refuse to send without a Sender, for robots shared between teams
+	RequireSender bool
+	//  templates used by SendTemplate, nil uses DefaultTemplates
+	Templates *TemplateRegistry
+	//  send text and markdown longer than MaxContentLength as numbered parts instead of failing
+	AutoSplit bool
+	//  custom keywords of a keyword-secured robot, the first is added to bodies (feed card titles) carrying none
+	Keywords []string
+	//  add the keyword in front of the body instead of after it
+	KeywordPrefix bool
+	//  append an IdempotencyTag to every body, see ParseIdempotencyTag
+	IdempotencyFooter bool
+	//  guard against accidental @all, e.g. AtAllReject in production
+	AtAllPolicy AtAllPolicy
+	//  token ConfirmAtAll must present under AtAllRequireConfirm
+	AtAllConfirmToken string
+	//  most members mentioned by one message, 0 mentions everyone given
+	MaxMentions int
+	//  how mentions beyond MaxMentions are handled
+	MentionOverflow MentionOverflow
+	//  decides which mentioned mobiles are valid, nil uses IsMobile
+	MobileValidator func(mobile string) bool
+	//  what happens to mobiles MobileValidator rejects, the default mentions them anyway
+	InvalidMobilePolicy MobilePolicy
+	//  called for every mobile left out under MobileSkip
+	OnInvalidMobile func(mobile string)
+	//  send button and link urls through a click counting redirect
+	ClickTracker *ClickTracker
+	//  build, validate and sign every request without posting it, for staging and CI
+	DryRun bool
+	//  receives every request skipped by DryRun
+	OnDryRun func(DryRunRequest)
+
+	mu          sync.Mutex
+	silences    []Silence
+	nextSilence int
+	hardLimit   *hardLimiter
+	coolUntil   time.Time
 }
 
 // Response `DingTalk web hook response struct`
@@ -76,30 +139,321 @@ type Response struct {
 	ErrorMessage string `json:"errmsg"`
 }
 
+// Option `configure a WebHook in NewWebHook`
+type Option func(*WebHook)
+
+// WithHTTPClient `send through client instead of DefaultClient`
+func WithHTTPClient(client *http.Client) Option {
+	return func(w *WebHook) {
+		w.Client = client
+	}
+}
+
 // NewWebHook `new a WebHook`
-func NewWebHook(accessToken string) *WebHook {
+func NewWebHook(accessToken string, options ...Option) *WebHook {
 	baseAPI := "https://oapi.dingtalk.com/robot/send"
-	return &WebHook{accessToken: accessToken, apiUrl: baseAPI}
+	w := &WebHook{AccessToken: accessToken, APIURL: baseAPI}
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// httpClient client for the next request
+func (w *WebHook) httpClient() *http.Client {
+	if nil != w.Client {
+		return w.Client
+	}
+	if nil == w.Proxy && nil == w.TLSConfig && nil == w.TransportConfig && !w.DisableProxy {
+		return DefaultClient
+	}
+
+	return transport.Client(transport.Options{Proxy: w.Proxy, DisableProxy: w.DisableProxy, TLS: w.TLSConfig, Pool: w.TransportConfig})
+}
+
+// channel label for history and metrics, the token is never used
+func (w *WebHook) channel() string {
+	if "" == w.Name {
+		return "default"
+	}
+
+	return w.Name
 }
 
 // reset api URL
-func (w *WebHook) resetApiUrl() {
-	w.apiUrl = "https://oapi.dingtalk.com/robot/send"
+func (w *WebHook) resetAPIURL() {
+	w.APIURL = "https://oapi.dingtalk.com/robot/send"
 }
 
-//  real send request to api
+// real send request to api
 func (w *WebHook) sendPayload(payload *PayLoad) error {
+	return w.sendPayloadContext(context.Background(), payload)
+}
+
+// sendPayloadContext send request to api, bounded by ctx
+func (w *WebHook) sendPayloadContext(ctx context.Context, payload *PayLoad) error {
+	//  rules and decorations work on a copy, so the caller can send, queue or retry the same payload again
+	payload = clonePayload(payload)
+	if !w.applyRules(ctx, payload) {
+		return ErrDropped
+	}
+
+	return w.deduplicate(ctx, func() (bool, error) {
+		_, delivered, err := w.sendParts(ctx, payload)
+		return 0 != delivered, err
+	})
+}
+
+// sendParts send payload, split by mentions and size when configured, see combineResults
+//
+// delivered counts the parts the api accepted, they went out even when a later part failed.
+func (w *WebHook) sendParts(ctx context.Context, payload *PayLoad) (*SendResult, int, error) {
+	payloads := w.splitMentions(payload)
+	if !w.AutoSplit && 1 == len(payloads) {
+		result, err := w.send(ctx, payload)
+		if nil != err {
+			return result, 0, err
+		}
+		return result, 1, nil
+	}
+
+	var results []*SendResult
+	delivered := 0
+	for _, payload := range payloads {
+		parts := []*PayLoad{payload}
+		if w.AutoSplit {
+			parts = w.splitPayload(payload)
+		}
+		for _, part := range parts {
+			result, err := w.send(ctx, part)
+			if nil != result {
+				results = append(results, result)
+			}
+			if nil != err {
+				return combineResults(results), delivered, err
+			}
+			delivered++
+		}
+	}
+
+	return combineResults(results), delivered, nil
+}
+
+// sendAttempts what transmit did with a failed payload, for the report send makes
+type sendAttempts struct {
+	payload *PayLoad
+	errs    []error
+}
+
+type sendAttemptsKey struct{}
+
+// fallbackKey marks the sends of a robot serving as the fallback of another one
+type fallbackKey struct{}
+
+// send run payload through the middleware and the send pipeline, then the fallbacks if the robot failed
+//
+// The payload is reported lost once the fallbacks failed as well, a robot serving as a fallback
+// leaves the report to the robot it stands in for.
+func (w *WebHook) send(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+	attempts := &sendAttempts{}
+	ctx = context.WithValue(ctx, sendAttemptsKey{}, attempts)
+
+	original := payload
+	if 0 != len(w.Fallbacks) {
+		original = clonePayload(payload)
+	}
+	result, err := w.sender().Send(ctx, payload)
+	errs := attempts.errs
+	if 0 != len(w.Fallbacks) && shouldFailover(ctx, err) {
+		var failures *MultiError
+		if result, failures = w.failover(ctx, original, err); nil == failures {
+			return result, nil
+		}
+		for _, failure := range failures.Errors[1:] {
+			errs = append(errs, failure)
+		}
+		err = failures
+	}
+
+	if nil != err && nil != attempts.payload && nil == ctx.Value(fallbackKey{}) {
+		w.reportFailure(attempts.payload, errs)
+	}
+
+	return result, err
+}
+
+// transmit run payload through the send pipeline, result is nil when the api was not reached
+func (w *WebHook) transmit(ctx context.Context, payload *PayLoad) (result *SendResult, err error) {
+	//  deferred first so metrics and failure hooks still see the bare error
+	defer func() {
+		err = w.withProfile(err)
+	}()
+	var errs []error
+	if nil != w.Tracer {
+		var span Span
+		ctx, span = w.Tracer.Start(ctx, SpanName)
+		defer func() {
+			w.endSpan(span, payload, result, len(errs), err)
+		}()
+	}
+	if nil != w.OnAfterSend {
+		defer func() {
+			w.OnAfterSend(payload, result, err)
+		}()
+	}
+	if nil != w.Metrics {
+		start := time.Now()
+		defer func() {
+			observeSend(w.Metrics, w.Sender, w.channel(), payload.MsgType, time.Since(start), err)
+		}()
+	}
+	defer func() {
+		if nil != err {
+			if 0 == len(errs) {
+				errs = []error{err}
+			}
+			if attempts, ok := ctx.Value(sendAttemptsKey{}).(*sendAttempts); ok {
+				attempts.payload, attempts.errs = payload, errs
+			}
+		}
+	}()
+
+	if nil != w.OnBeforeSend {
+		w.OnBeforeSend(payload)
+	}
+	if nil != payload.Raw {
+		//  a raw body has no sections to prepare, see SendRaw
+		result, errs = w.deliver(ctx, func(ctx context.Context) (*SendResult, error) {
+			return w.checkResult(w.postBody(ctx, payload.Raw))
+		})
+		if 0 != len(errs) {
+			err = errs[len(errs)-1]
+		}
+		return result, err
+	}
+	if err := w.prepare(payload); nil != err {
+		return nil, err
+	}
+
+	if !w.Emergency && w.silenced(payload) {
+		return nil, ErrSilenced
+	}
+
+	result, errs = w.deliver(ctx, func(ctx context.Context) (*SendResult, error) {
+		return w.post(ctx, payload)
+	})
+	if 0 != len(errs) {
+		err = errs[len(errs)-1]
+	}
+	if nil != w.History {
+		_ = w.History.RecordAs(w.Sender.String(), w.channel(), payload, err)
+	}
+
+	return result, err
+}
+
+// prepare turn a built payload into the one posted: transform, guard and decorate it, then check its size
+func (w *WebHook) prepare(payload *PayLoad) error {
+	if nil != w.Transform && !w.Transform.Apply(payload) {
+		return ErrDropped
+	}
+	//  logs piped into messages may carry bytes DingTalk rejects with an opaque error
+	sanitizePayload(payload)
+
+	if w.RequireSender && w.Sender.IsZero() {
+		return ErrNoSenderIdentity
+	}
+	if err := w.guardAtAll(payload); nil != err {
+		return err
+	}
+	if err := w.checkMobiles(payload); nil != err {
+		return err
+	}
+	w.capMentions(payload)
+	//  tag the content as the caller built it, before any per-robot decoration
+	tag := ""
+	if w.IdempotencyFooter || nil != w.ClickTracker {
+		tag = IdempotencyTag(payload)
+	}
+	w.decorate(payload, tag)
+	w.trackClicks(payload, tag)
+	w.anonymize(payload)
+
+	return checkSize(payload)
+}
+
+// decorate add the keyword and footers of the robot to the body
+func (w *WebHook) decorate(payload *PayLoad, tag string) {
+	w.injectKeyword(payload)
+	w.senderFooter(payload)
+	if w.IdempotencyFooter {
+		idempotencyFooter(payload, tag)
+	}
+}
+
+// post post payload and turn a non-zero errcode into an error
+func (w *WebHook) post(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+	return w.checkResult(w.postPayload(ctx, payload))
+}
+
+// checkResult turn a non-zero errcode into a typed error
+func (w *WebHook) checkResult(result *SendResult, err error) (*SendResult, error) {
+	if nil != err {
+		return result, err
+	}
+
+	if 0 != result.ErrorCode {
+		apiErr := &APIError{Code: result.ErrorCode, Message: result.ErrorMessage}
+		if nil != result.Header {
+			apiErr.Header, apiErr.Body = detailHeader(result.Header), detailBody(result.Body)
+		}
+		if isSignError(apiErr) {
+			return result, w.diagnoseSign(apiErr, result)
+		}
+		if 300001 == apiErr.Code {
+			return result, &TokenError{APIError: apiErr, Reason: "the access token does not belong to any robot"}
+		}
+		return result, apiErr
+	}
+
+	return result, nil
+}
+
+// postPayload post payload and decode the api response, errcode is left to the caller
+func (w *WebHook) postPayload(ctx context.Context, payload *PayLoad) (*SendResult, error) {
+	//  drop fields DingTalk no longer accepts
+	for _, warning := range applyCompat(payload) {
+		w.infof("dingtalk %s: %s", w.channel(), warning)
+		if nil != w.OnDeprecation {
+			w.OnDeprecation(warning)
+		}
+	}
+
+	//  get config
+	bs, _ := json.Marshal(payload)
+	return w.postBody(ctx, bs)
+}
+
+// postBody sign and post a json body, errcode is left to the caller
+func (w *WebHook) postBody(ctx context.Context, bs []byte) (*SendResult, error) {
+	if "" == w.AccessToken {
+		return nil, &TokenError{Reason: "access token is empty"}
+	}
+
+	result := &SendResult{}
 	params := make(map[string]string)
 	var apiURL string
-	if strings.Contains(w.accessToken, w.apiUrl) {
-		apiURL = w.accessToken
+	if strings.Contains(w.AccessToken, w.APIURL) {
+		apiURL = w.AccessToken
 	} else {
-		params["access_token"] = w.accessToken
-		apiURL = w.apiUrl
+		params["access_token"] = w.AccessToken
+		apiURL = w.APIURL
 	}
 
 	if w.Secret != "" {
-		params["timestamp"], params["sign"] = w.getSign()
+		result.timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+		params["timestamp"], params["sign"] = strconv.FormatInt(result.timestamp, 10), Sign(w.Secret, result.timestamp)
 	}
 
 	// add params
@@ -107,164 +461,139 @@ func (w *WebHook) sendPayload(payload *PayLoad) error {
 		apiURL = addParamsToURL(params, apiURL)
 	}
 
-	//  get config
-	bs, _ := json.Marshal(payload)
+	w.debugf("dingtalk request: POST %s\n%s", redactURL(apiURL), bs)
+	if w.DryRun {
+		return w.dryRun(apiURL, bs, result)
+	}
+
+	//  bound the request by the observed latency
+	if nil != w.Timeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout.Timeout())
+		defer cancel()
+	}
+
 	//  request api
-	resp, err := http.Post(apiURL, "application/json", bytes.NewReader(bs))
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(bs))
 	if nil != err {
-		return errors.New("api request error: " + err.Error())
+		return nil, &RequestError{Err: err}
 	}
+	req.Header.Set("Content-Type", "application/json")
+	w.setHeaders(req)
+	start := time.Now()
+	resp, err := w.httpClient().Do(req.WithContext(ctx))
+	if nil != err {
+		//  a request cut off by the deadline took at least that long, ignoring it would keep the timeout from growing
+		if nil != w.Timeout && context.DeadlineExceeded == ctx.Err() {
+			w.Timeout.Observe(time.Since(start))
+		}
+		w.debugf("dingtalk request failed: %v", err)
+		return nil, &RequestError{Err: err}
+	}
+	defer resp.Body.Close()
 
 	//  read response body
 	body, _ := ioutil.ReadAll(resp.Body)
+	result.Duration = time.Since(start)
+	result.StatusCode = resp.StatusCode
+	result.Header = resp.Header
+	result.Body = body
+	w.debugf("dingtalk response: %s in %s\n%s\n\n%s", resp.Status, result.Duration, dumpHeader(resp.Header), body)
+	if nil != w.Timeout {
+		w.Timeout.Observe(result.Duration)
+	}
+
 	//  api unusual
 	if 200 != resp.StatusCode {
-		return fmt.Errorf("api response error: %d", resp.StatusCode)
+		return result, &HTTPError{StatusCode: resp.StatusCode, Header: detailHeader(resp.Header), Body: detailBody(body)}
 	}
 
-	var result Response
 	//  json decode
-	err = json.Unmarshal(body, &result)
+	err = json.Unmarshal(body, &result.Response)
 	if nil != err {
-		return errors.New("response struct error: response is not a json anymore, " + err.Error())
+		return result, &ResponseError{Header: detailHeader(resp.Header), Body: detailBody(body), Err: err}
 	}
 
-	if 0 != result.ErrorCode {
-		return fmt.Errorf("api custom error: {code: %d, msg: %s}", result.ErrorCode, result.ErrorMessage)
-	}
-
-	return nil
+	return result, nil
 }
 
 // SendTextMsg `send a text message`
 func (w *WebHook) SendTextMsg(content string, isAtAll bool, mobiles ...string) error {
-	//  send request
-	return w.sendPayload(&PayLoad{
-		MsgType: "text",
-		Text: struct {
-			Content string `json:"content"`
-		}{
-			Content: content,
-		},
-		At: struct {
-			AtMobiles []string `json:"atMobiles"`
-			IsAtAll   bool     `json:"isAtAll"`
-		}{
-			AtMobiles: mobiles,
-			IsAtAll:   isAtAll,
-		},
-	})
+	return w.SendTextMsgContext(context.Background(), content, isAtAll, mobiles...)
+}
+
+// SendTextMsgContext `send a text message, bounded by ctx`
+func (w *WebHook) SendTextMsgContext(ctx context.Context, content string, isAtAll bool, mobiles ...string) error {
+	return w.SendContext(ctx, &TextMessage{Content: content, IsAtAll: isAtAll, AtMobiles: mobiles})
 }
 
 // SendLinkMsg `send a link message`
 func (w *WebHook) SendLinkMsg(title, content, picURL, msgURL string) error {
-	return w.sendPayload(&PayLoad{
-		MsgType: "link",
-		Link: struct {
-			Title      string `json:"title"`
-			Text       string `json:"text"`
-			PicURL     string `json:"picURL"`
-			MessageURL string `json:"messageUrl"`
-		}{
-			Title:      title,
-			Text:       content,
-			PicURL:     picURL,
-			MessageURL: msgURL,
-		},
-	})
+	return w.SendLinkMsgContext(context.Background(), title, content, picURL, msgURL)
+}
+
+// SendLinkMsgContext `send a link message, bounded by ctx`
+func (w *WebHook) SendLinkMsgContext(ctx context.Context, title, content, picURL, msgURL string) error {
+	return w.SendContext(ctx, &LinkMessage{Title: title, Text: content, PicURL: picURL, MessageURL: msgURL})
 }
 
 // SendMarkdownMsg `send a markdown msg`
 func (w *WebHook) SendMarkdownMsg(title, content string, isAtAll bool, mobiles ...string) error {
-	//  send request
-	return w.sendPayload(&PayLoad{
-		MsgType: "markdown",
-		Markdown: struct {
-			Title string `json:"title"`
-			Text  string `json:"text"`
-		}{
-			Title: title,
-			Text:  content,
-		},
-		At: struct {
-			AtMobiles []string `json:"atMobiles"`
-			IsAtAll   bool     `json:"isAtAll"`
-		}{
-			AtMobiles: mobiles,
-			IsAtAll:   isAtAll,
-		},
-	})
+	return w.SendMarkdownMsgContext(context.Background(), title, content, isAtAll, mobiles...)
+}
+
+// SendMarkdownMsgContext `send a markdown msg, bounded by ctx`
+func (w *WebHook) SendMarkdownMsgContext(ctx context.Context, title, content string, isAtAll bool, mobiles ...string) error {
+	return w.SendContext(ctx, &MarkdownMessage{Title: title, Text: content, IsAtAll: isAtAll, AtMobiles: mobiles})
 }
 
 // SendActionCardMsg `send single action card message`
 func (w *WebHook) SendActionCardMsg(title, content string, linkTitles, linkUrls []string, hideAvatar, btnOrientation bool) error {
-	//  validation is empty
-	if 0 == len(linkTitles) || 0 == len(linkUrls) {
-		return errors.New("links or titles is empty！")
-	}
-	//  validation is equal
-	if len(linkUrls) != len(linkTitles) {
-		return errors.New("links length and titles length is not equal！")
-	}
-	//  hide robot avatar
-	var strHideAvatar = "0"
-	if hideAvatar {
-		strHideAvatar = "1"
-	}
-	//  button sort
-	var strBtnOrientation = "0"
-	if btnOrientation {
-		strBtnOrientation = "1"
-	}
-	//  button struct
-	var buttons []struct {
-		Title     string `json:"title"`
-		ActionURL string `json:"actionUrl"`
-	}
-	//  inject to button
-	for i := 0; i < len(linkTitles); i++ {
-		buttons = append(buttons, struct {
-			Title     string `json:"title"`
-			ActionURL string `json:"actionUrl"`
-		}{
-			Title:     linkTitles[i],
-			ActionURL: linkUrls[i],
-		})
-	}
-	//  send request
-	return w.sendPayload(&PayLoad{
-		MsgType: "actionCard",
-		ActionCard: ActionCard{
-			Title:          title,
-			Text:           content,
-			HideAvatar:     strHideAvatar,
-			BtnOrientation: strBtnOrientation,
-			Buttons:        buttons,
-		},
+	return w.SendActionCardMsgContext(context.Background(), title, content, linkTitles, linkUrls, hideAvatar, btnOrientation)
+}
+
+// SendActionCardMsgContext `send single action card message, bounded by ctx`
+func (w *WebHook) SendActionCardMsgContext(ctx context.Context, title, content string, linkTitles, linkUrls []string, hideAvatar, btnOrientation bool) error {
+	return w.SendContext(ctx, &ActionCardMessage{
+		Title:          title,
+		Text:           content,
+		LinkTitles:     linkTitles,
+		LinkURLs:       linkUrls,
+		HideAvatar:     hideAvatar,
+		BtnOrientation: btnOrientation,
 	})
 }
 
 // SendLinkCardMsg `send link card message`
 func (w *WebHook) SendLinkCardMsg(messages []LinkMsg) error {
-	return w.sendPayload(&PayLoad{
-		MsgType: "feedCard",
-		FeedCard: struct {
-			Links []LinkMsg `json:"links"`
-		}{
-			Links: messages,
-		},
-	})
+	return w.SendLinkCardMsgContext(context.Background(), messages)
 }
 
-// getSign get sign
-func (w *WebHook) getSign() (timestamp, sha string) {
-	timestamp = strconv.FormatInt(time.Now().UnixNano() / int64(time.Millisecond), 10)
-	message := timestamp + "\n" + w.Secret
+// SendLinkCardMsgContext `send link card message, bounded by ctx`
+func (w *WebHook) SendLinkCardMsgContext(ctx context.Context, messages []LinkMsg) error {
+	return w.SendContext(ctx, &FeedCardMessage{Links: messages})
+}
 
-	h := hmac.New(sha256.New, []byte(w.Secret))
-	h.Write([]byte(message))
+// payloadContent the human readable body of the active message section
+func payloadContent(payload *PayLoad) string {
+	switch {
+	case "text" == payload.MsgType && nil != payload.Text:
+		return payload.Text.Content
+	case "link" == payload.MsgType && nil != payload.Link:
+		return payload.Link.Title + "\n" + payload.Link.Text
+	case "markdown" == payload.MsgType && nil != payload.Markdown:
+		return payload.Markdown.Title + "\n" + payload.Markdown.Text
+	case "actionCard" == payload.MsgType && nil != payload.ActionCard:
+		return payload.ActionCard.Title + "\n" + payload.ActionCard.Text
+	case "feedCard" == payload.MsgType && nil != payload.FeedCard:
+		var titles []string
+		for _, link := range payload.FeedCard.Links {
+			titles = append(titles, link.Title)
+		}
+		return strings.Join(titles, "\n")
+	}
 
-	return timestamp, base64.StdEncoding.EncodeToString(h.Sum(nil))
+	return ""
 }
 
 // addPramsToUrl
@@ -42,9 +42,7 @@ func TestWebHook(t *testing.T) {
 	webHook.AccessToken = "example-access-token"
 	payLoad = &PayLoad{
 		MsgType: "text",
-		Text: struct {
-			Content string `json:"content"`
-		}{
+		Text: &Text{
 			Content: "test msg",
 		},
 	}
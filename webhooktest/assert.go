@@ -0,0 +1,143 @@
+package webhooktest
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// TB `the part of testing.TB the assertions use`
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Payload `the fields of a captured payload the assertions look at`
+type Payload struct {
+	MsgType string `json:"msgtype"`
+	Text    struct {
+		Content string `json:"content"`
+	} `json:"text"`
+	Markdown struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	} `json:"markdown"`
+	ActionCard struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	} `json:"actionCard"`
+	At struct {
+		AtMobiles []string `json:"atMobiles"`
+		AtUserIds []string `json:"atUserIds"`
+		IsAtAll   bool     `json:"isAtAll"`
+	} `json:"at"`
+}
+
+// Payload `decode the captured body`
+func (r Request) Payload() (Payload, error) {
+	var payload Payload
+	err := json.Unmarshal(r.Body, &payload)
+	return payload, err
+}
+
+// AssertAtMobiles `the request mentions exactly mobiles, in any order`
+func AssertAtMobiles(t TB, r Request, mobiles ...string) bool {
+	t.Helper()
+	payload, ok := decode(t, r)
+	if !ok {
+		return false
+	}
+	if !sameSet(payload.At.AtMobiles, mobiles) {
+		t.Errorf("atMobiles: want %q, got %q", mobiles, payload.At.AtMobiles)
+		return false
+	}
+
+	return true
+}
+
+// AssertAtUserIDs `the request mentions exactly ids, in any order`
+func AssertAtUserIDs(t TB, r Request, ids ...string) bool {
+	t.Helper()
+	payload, ok := decode(t, r)
+	if !ok {
+		return false
+	}
+	if !sameSet(payload.At.AtUserIds, ids) {
+		t.Errorf("atUserIds: want %q, got %q", ids, payload.At.AtUserIds)
+		return false
+	}
+
+	return true
+}
+
+// AssertAtAll `the request mentions everyone, or explicitly does not`
+func AssertAtAll(t TB, r Request, want bool) bool {
+	t.Helper()
+	payload, ok := decode(t, r)
+	if !ok {
+		return false
+	}
+	if want != payload.At.IsAtAll {
+		t.Errorf("isAtAll: want %v, got %v", want, payload.At.IsAtAll)
+		return false
+	}
+
+	return true
+}
+
+// AssertContainsMarkdownHeading `the markdown or action card body has a heading reading heading, at any level`
+func AssertContainsMarkdownHeading(t TB, r Request, heading string) bool {
+	t.Helper()
+	payload, ok := decode(t, r)
+	if !ok {
+		return false
+	}
+
+	body := payload.Markdown.Text
+	if "actionCard" == payload.MsgType {
+		body = payload.ActionCard.Text
+	}
+	var headings []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		text := strings.TrimLeft(line, "#")
+		if n := len(line) - len(text); 0 < n && n <= 6 && strings.HasPrefix(text, " ") {
+			text = strings.TrimSpace(text)
+			if heading == text {
+				return true
+			}
+			headings = append(headings, text)
+		}
+	}
+
+	t.Errorf("%s body has no heading %q, headings: %q", payload.MsgType, heading, headings)
+	return false
+}
+
+func decode(t TB, r Request) (Payload, bool) {
+	t.Helper()
+	payload, err := r.Payload()
+	if nil != err {
+		t.Errorf("captured body is not a json payload: %v", err)
+		return payload, false
+	}
+
+	return payload, true
+}
+
+func sameSet(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	a, b := append([]string(nil), got...), append([]string(nil), want...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,42 @@
+package webhooktest
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recorder TB collecting failures instead of failing the test
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	markdown := Request{Body: []byte(`{"msgtype":"markdown","markdown":{"title":"t","text":"## Disk full\n\n- web-1"},"at":{"atMobiles":["139","138"],"atUserIds":["u1"],"isAtAll":false}}`)}
+	card := Request{Body: []byte(`{"msgtype":"actionCard","actionCard":{"title":"t","text":"#### Release v1"}}`)}
+
+	passing := &recorder{}
+	AssertAtMobiles(passing, markdown, "138", "139")
+	AssertAtUserIDs(passing, markdown, "u1")
+	AssertAtAll(passing, markdown, false)
+	AssertContainsMarkdownHeading(passing, markdown, "Disk full")
+	AssertContainsMarkdownHeading(passing, card, "Release v1")
+	if 0 != len(passing.errors) {
+		t.Errorf("assertions should pass, got %q", passing.errors)
+	}
+
+	failing := &recorder{}
+	AssertAtMobiles(failing, markdown, "138")
+	AssertAtUserIDs(failing, card, "u1")
+	AssertAtAll(failing, markdown, true)
+	AssertContainsMarkdownHeading(failing, markdown, "web-1")
+	AssertAtAll(failing, Request{Body: []byte("not json")}, false)
+	if 5 != len(failing.errors) {
+		t.Errorf("every assertion should fail, got %q", failing.errors)
+	}
+}
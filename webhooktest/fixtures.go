@@ -0,0 +1,87 @@
+package webhooktest
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Fixtures `sanitized raw responses of the DingTalk robot api, keyed by name`
+//
+// They keep what real responses carry besides errcode and errmsg, such as extra headers,
+// unknown json fields and non-json gateway pages, so parsing is tested against the wire format
+// rather than against what the library itself would produce.
+var Fixtures = map[string]string{
+	"ok": "HTTP/1.1 200 OK\r\n" +
+		"Server: DingTalk/1.0.0\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":0,"errmsg":"ok"}`,
+	"ok-extra-fields": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":0,"errmsg":"ok","request_id":"0000000000000000","trace":{"id":"x"}}`,
+	"send-too-fast": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":130101,"errmsg":"send too fast, exceed 20 times per minute"}`,
+	"token-not-exist": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":300001,"errmsg":"token is not exist"}`,
+	"sign-not-match": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":310000,"errmsg":"sign not match, more: [https://ding-doc.dingtalk.com/doc#/serverapi2/qf2nxq]"}`,
+	"keywords-not-in-content": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":310000,"errmsg":"keywords not in content, more: [https://ding-doc.dingtalk.com/doc#/serverapi2/qf2nxq]"}`,
+	"ip-not-in-whitelist": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":310000,"errmsg":"ip X.X.X.X not in whitelist, more: [https://ding-doc.dingtalk.com/doc#/serverapi2/qf2nxq]"}`,
+	"missing-param": "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json;charset=utf-8\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		`{"errcode":40035,"errmsg":"缺少参数 json"}`,
+	"bad-gateway": "HTTP/1.1 502 Bad Gateway\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Date: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n" +
+		"<html><body><h1>502 Bad Gateway</h1></body></html>",
+}
+
+// FixtureNames `sorted names of all fixtures`
+func FixtureNames() []string {
+	var names []string
+	for name := range Fixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadFixture `parse a fixture into a Reply`
+func LoadFixture(name string) (Reply, error) {
+	raw, ok := Fixtures[name]
+	if !ok {
+		return Reply{}, fmt.Errorf("unknown fixture: %s", name)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(raw)), nil)
+	if nil != err {
+		return Reply{}, fmt.Errorf("fixture %s: %v", name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if nil != err {
+		return Reply{}, fmt.Errorf("fixture %s: %v", name, err)
+	}
+
+	return Reply{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
@@ -0,0 +1,133 @@
+package webhooktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/sign"
+)
+
+// Security `the security settings of a robot, any combination may be enabled`
+//
+// Requests breaking one of them are answered with the errcode 310000 variant DingTalk sends,
+// before any queued reply is used.
+type Security struct {
+	//  at least one must appear in the message
+	Keywords []string
+	//  requests must carry a timestamp and sign made with it
+	Secret string
+	//  addresses or CIDR ranges allowed to post, e.g. "127.0.0.1" or "10.0.0.0/8"
+	AllowedIPs []string
+}
+
+// signWindow how far a signed timestamp may be off the server clock
+const signWindow = time.Hour
+
+// Secure `enforce security on every following request`
+func (s *Server) Secure(security Security) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.security = security
+}
+
+// check the 310000 reply r deserves, nil when it passes
+func (sec Security) check(r *http.Request, body []byte) *Reply {
+	if 0 != len(sec.AllowedIPs) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		if !ipAllowed(host, sec.AllowedIPs) {
+			return securityReply(fmt.Sprintf("ip %s not in whitelist", host))
+		}
+	}
+
+	if "" != sec.Secret {
+		query := r.URL.Query()
+		timestamp, err := strconv.ParseInt(query.Get("timestamp"), 10, 64)
+		if nil != err {
+			return securityReply("sign not match")
+		}
+		skew := time.Since(time.Unix(0, timestamp*int64(time.Millisecond)))
+		if math.Abs(float64(skew)) > float64(signWindow) {
+			return securityReply("invalid timestamp")
+		}
+		if sign.Sign(sec.Secret, timestamp) != query.Get("sign") {
+			return securityReply("sign not match")
+		}
+	}
+
+	if 0 != len(sec.Keywords) && !containsKeyword(body, sec.Keywords) {
+		return securityReply("keywords not in content")
+	}
+
+	return nil
+}
+
+// securityReply 310000 with DingTalk's wording
+func securityReply(reason string) *Reply {
+	body, _ := json.Marshal(map[string]interface{}{
+		"errcode": 310000,
+		"errmsg":  reason + ", more: [https://ding-doc.dingtalk.com/doc#/serverapi2/qf2nxq]",
+	})
+
+	return &Reply{StatusCode: http.StatusOK, Header: OK.Header, Body: body}
+}
+
+func ipAllowed(host string, allowed []string) bool {
+	ip := net.ParseIP(host)
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); nil == err {
+			if nil != ip && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(entry); nil != ip && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsKeyword look for a keyword in any string of the message sections, msgtype aside
+func containsKeyword(body []byte, keywords []string) bool {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); nil != err {
+		return false
+	}
+	delete(doc, "msgtype")
+	delete(doc, "at")
+
+	var texts []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case string:
+			texts = append(texts, v)
+		case map[string]interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		}
+	}
+	collect(doc)
+
+	content := strings.Join(texts, "\n")
+	for _, keyword := range keywords {
+		if strings.Contains(content, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package webhooktest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lddsb/dingtalk-webhook/sign"
+)
+
+func post(t *testing.T, url, body string) string {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reply, _ := ioutil.ReadAll(resp.Body)
+	return string(reply)
+}
+
+func TestSecureKeywords(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.Secure(Security{Keywords: []string{"alert"}})
+
+	if reply := post(t, server.URL, `{"msgtype":"text","text":{"content":"hello"}}`); !strings.Contains(reply, "keywords not in content") {
+		t.Errorf("message without keyword should be rejected, got %s", reply)
+	}
+	if reply := post(t, server.URL, `{"msgtype":"markdown","markdown":{"title":"alert","text":"hello"}}`); !strings.Contains(reply, `"errcode":0`) {
+		t.Errorf("keyword in the title should pass, got %s", reply)
+	}
+}
+
+func TestSecureSign(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.Secure(Security{Secret: "secret"})
+	_ = server.ReplyFixture("send-too-fast")
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	signed := func(secret string, timestamp int64) string {
+		return server.URL + "?timestamp=" + strconv.FormatInt(timestamp, 10) + "&sign=" + strings.Replace(sign.Sign(secret, timestamp), "+", "%2B", -1)
+	}
+
+	cases := []struct {
+		url, want string
+	}{
+		{server.URL, "sign not match"},
+		{signed("wrong", now), "sign not match"},
+		{signed("secret", now-2*time.Hour.Nanoseconds()/int64(time.Millisecond)), "invalid timestamp"},
+		{signed("secret", now), "130101"},
+	}
+	for _, c := range cases {
+		if reply := post(t, c.url, `{"msgtype":"text"}`); !strings.Contains(reply, c.want) {
+			t.Errorf("%s: want %s, got %s", c.url, c.want, reply)
+		}
+	}
+}
+
+func TestSecureIP(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Secure(Security{AllowedIPs: []string{"10.0.0.0/8"}})
+	if reply := post(t, server.URL, `{"msgtype":"text"}`); !strings.Contains(reply, "ip 127.0.0.1 not in whitelist") {
+		t.Errorf("address outside the whitelist should be rejected, got %s", reply)
+	}
+
+	server.Secure(Security{AllowedIPs: []string{"10.0.0.0/8", "127.0.0.1"}})
+	if reply := post(t, server.URL, `{"msgtype":"text"}`); !strings.Contains(reply, `"errcode":0`) {
+		t.Errorf("whitelisted address should pass, got %s", reply)
+	}
+}
@@ -0,0 +1,103 @@
+// Package webhooktest provides a fake DingTalk robot endpoint for tests.
+package webhooktest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+// Request `a request captured by the fake server`
+type Request struct {
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Reply `response the fake server answers with`
+type Reply struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// OK `the reply DingTalk sends for an accepted message`
+var OK = Reply{
+	StatusCode: http.StatusOK,
+	Header:     http.Header{"Content-Type": {"application/json;charset=utf-8"}},
+	Body:       []byte(`{"errcode":0,"errmsg":"ok"}`),
+}
+
+// Server `fake robot endpoint recording every request`
+//
+// Queued replies are answered in order, OK is answered once the queue is empty. Requests
+// rejected by Secure settings do not take a queued reply.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	replies  []Reply
+	security Security
+}
+
+// NewServer `start a fake server, Close it when done`
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Reply `queue replies for the next requests`
+func (s *Server) Reply(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replies = append(s.replies, replies...)
+}
+
+// ReplyFixture `queue recorded responses by fixture name`
+func (s *Server) ReplyFixture(names ...string) error {
+	for _, name := range names {
+		reply, err := LoadFixture(name)
+		if nil != err {
+			return err
+		}
+		s.Reply(reply)
+	}
+
+	return nil
+}
+
+// Requests `every request received so far`
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Query: r.URL.Query(), Header: r.Header.Clone(), Body: body})
+	reply := OK
+	if rejected := s.security.check(r, body); nil != rejected {
+		reply = *rejected
+	} else if 0 != len(s.replies) {
+		reply, s.replies = s.replies[0], s.replies[1:]
+	}
+	s.mu.Unlock()
+
+	for key, values := range reply.Header {
+		for _, value := range values {
+			rw.Header().Add(key, value)
+		}
+	}
+	rw.WriteHeader(reply.StatusCode)
+	_, _ = rw.Write(reply.Body)
+}
@@ -0,0 +1,45 @@
+package webhooktest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	if err := server.ReplyFixture("send-too-fast"); nil != err {
+		t.Fatal(err)
+	}
+	if err := server.ReplyFixture("unknown"); nil == err {
+		t.Error("unknown fixture error should be catch!")
+	}
+
+	for i, want := range []string{"130101", `"errcode":0`} {
+		resp, err := http.Post(server.URL+"?access_token=x", "application/json", strings.NewReader(`{"msgtype":"text"}`))
+		if nil != err {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), want) {
+			t.Errorf("reply %d should contain %s, got %s", i, want, body)
+		}
+	}
+
+	requests := server.Requests()
+	if 2 != len(requests) || "x" != requests[0].Query.Get("access_token") || `{"msgtype":"text"}` != string(requests[1].Body) {
+		t.Errorf("requests should be captured, got %+v", requests)
+	}
+}
+
+func TestFixturesParse(t *testing.T) {
+	for _, name := range FixtureNames() {
+		if _, err := LoadFixture(name); nil != err {
+			t.Error(err)
+		}
+	}
+}